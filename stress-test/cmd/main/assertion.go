@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+)
+
+// Variáveis para os parâmetros CLI de validação das respostas
+var (
+	expectStatus  []int         // Códigos de status aceitos
+	expectBody    []string      // Textos que o corpo deve conter
+	expectRegex   []string      // Expressões regulares que o corpo deve satisfazer
+	expectJSON    []string      // Caminhos JSON que devem existir ou ter um valor (caminho[=valor])
+	expectHeaders []string      // Cabeçalhos que devem estar presentes na resposta
+	maxLatency    time.Duration // Tempo máximo de resposta aceito
+)
+
+// init configura os flags de asserções
+func init() {
+	rootCmd.Flags().IntSliceVar(&expectStatus, "expect-status", nil, "Códigos de status aceitos (ex: 200,201)")
+	rootCmd.Flags().StringArrayVar(&expectBody, "expect-body", nil, "Texto que o corpo da resposta deve conter (repetível)")
+	rootCmd.Flags().StringArrayVar(&expectRegex, "expect-regex", nil, "Expressão regular que o corpo da resposta deve satisfazer (repetível)")
+	rootCmd.Flags().StringArrayVar(&expectJSON, "expect-json", nil, "Caminho JSON que deve existir ($.temp_c) ou ter um valor ($.temp_c=28.5) (repetível)")
+	rootCmd.Flags().StringArrayVar(&expectHeaders, "expect-header", nil, "Cabeçalho que deve estar presente na resposta (repetível)")
+	rootCmd.Flags().DurationVar(&maxLatency, "max-latency", 0, "Tempo máximo de resposta aceito (ex: 500ms)")
+}
+
+// buildAssertions monta as asserções a partir dos flags informados.
+// Retorna nil quando nenhuma asserção foi pedida.
+func buildAssertions() *stresstest.Assertions {
+	if len(expectStatus) == 0 && len(expectBody) == 0 && len(expectRegex) == 0 &&
+		len(expectJSON) == 0 && len(expectHeaders) == 0 && maxLatency == 0 {
+		return nil
+	}
+
+	assertions := &stresstest.Assertions{
+		Status:       expectStatus,
+		BodyContains: expectBody,
+		BodyRegex:    expectRegex,
+		Headers:      expectHeaders,
+		MaxLatency:   maxLatency,
+	}
+
+	// Com "=" compara o valor; sem ele apenas exige que o caminho exista
+	for _, value := range expectJSON {
+		path, expected, ok := strings.Cut(value, "=")
+		if !ok {
+			assertions.JSONExists = append(assertions.JSONExists, path)
+			continue
+		}
+		if assertions.JSONEquals == nil {
+			assertions.JSONEquals = make(map[string]string)
+		}
+		assertions.JSONEquals[strings.TrimSpace(path)] = strings.TrimSpace(expected)
+	}
+
+	return assertions
+}
+
+// applyScenarioAssertions usa as asserções dos flags nas requisições do cenário
+// que não declaram as suas próprias no campo assert.
+func applyScenarioAssertions(scenario *stresstest.Scenario, assertions *stresstest.Assertions) {
+	if assertions == nil {
+		return
+	}
+	for i := range scenario.Requests {
+		if scenario.Requests[i].Assertions == nil {
+			scenario.Requests[i].Assertions = assertions
+		}
+	}
+}
+
+// applyFlowAssertions usa as asserções dos flags nos passos do fluxo que não declaram as suas
+// próprias no campo assert.
+func applyFlowAssertions(flow *stresstest.Flow, assertions *stresstest.Assertions) {
+	if assertions == nil {
+		return
+	}
+	for i := range flow.Steps {
+		if flow.Steps[i].Assertions == nil {
+			flow.Steps[i].Assertions = assertions
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+	"github.com/spf13/cobra"
+)
+
+// Variáveis para os parâmetros CLI da busca de capacidade
+var (
+	startRate    int           // Taxa do primeiro passo (requests por segundo)
+	rateStep     int           // Incremento da taxa a cada passo
+	maxRate      int           // Taxa máxima testada (0 = sem limite)
+	stepDuration time.Duration // Duração do patamar avaliado em cada passo
+	warmup       time.Duration // Aquecimento de cada passo, fora das métricas
+	slo          []string      // Critérios do SLO no formato dos thresholds (ex: p99<300ms)
+)
+
+// findCapacityCmd aumenta a taxa de chegada passo a passo até violar o SLO
+var findCapacityCmd = &cobra.Command{
+	Use:   "find-capacity",
+	Short: "Procura a maior taxa de requests que o serviço sustenta dentro do SLO",
+	Long: `Aumenta a taxa de chegada passo a passo (--start-rate, --rate-step, --max-rate) e avalia
+o SLO (--slo, no formato dos thresholds) no patamar de cada passo. A busca para no primeiro passo
+reprovado e informa a maior taxa sustentável, com as métricas de cada passo.
+
+Aceita os mesmos flags de alvo do teste de carga (URL, cenário, fluxo, gRPC, streaming, cabeçalhos
+e transporte). A concorrência (-c) precisa ser suficiente para a maior taxa: passos em que os
+workers não alcançam a taxa alvo são reprovados.`,
+	Args: cobra.NoArgs,
+	RunE: runFindCapacity,
+}
+
+// init configura o subcomando e seus flags
+func init() {
+	findCapacityCmd.Flags().IntVar(&startRate, "start-rate", 10, "Taxa do primeiro passo (requests por segundo)")
+	findCapacityCmd.Flags().IntVar(&rateStep, "rate-step", 10, "Incremento da taxa a cada passo (requests por segundo)")
+	findCapacityCmd.Flags().IntVar(&maxRate, "max-rate", 0, "Taxa máxima testada (0 = até violar o SLO)")
+	findCapacityCmd.Flags().DurationVar(&stepDuration, "step-duration", 30*time.Second, "Duração do patamar avaliado em cada passo")
+	findCapacityCmd.Flags().DurationVar(&warmup, "warmup", stresstest.DefaultCapacityWarmup, "Aquecimento de cada passo (rampa até a taxa alvo, fora das métricas)")
+	findCapacityCmd.Flags().StringArrayVar(&slo, "slo", nil, "Critério do SLO avaliado em cada passo (repetível), ex: p99<300ms, error_rate<1%")
+	findCapacityCmd.MarkFlagRequired("slo")
+
+	rootCmd.AddCommand(findCapacityCmd)
+}
+
+// runFindCapacity executa a busca de capacidade e exibe o resultado de cada passo
+func runFindCapacity(cmd *cobra.Command, args []string) error {
+	// A carga é definida pela busca: flags de quantidade, perfil, replay e thresholds não se aplicam.
+	// O resultado é exibido por passo: progresso, sinks, tags e relatórios salvos também não
+	for _, name := range []string{"requests", "stage", "profile", "replay", "threshold", "abort-on-fail",
+		"progress", "save", "html", "sink", "sink-label", "sink-header", "tag-by"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s não pode ser usado com find-capacity", name)
+		}
+	}
+
+	config, err := buildTargetConfig(cmd)
+	if err != nil {
+		return err
+	}
+	config.MaxErrors = maxErrors
+	config.GracePeriod = gracePeriod
+
+	capacity := stresstest.CapacityConfig{
+		StartRate:    startRate,
+		RateStep:     rateStep,
+		MaxRate:      maxRate,
+		StepDuration: stepDuration,
+		Warmup:       warmup,
+	}
+	for _, expression := range slo {
+		threshold, err := stresstest.ParseThreshold(expression)
+		if err != nil {
+			return err
+		}
+		capacity.SLO = append(capacity.SLO, threshold)
+	}
+	if err := validateOutput(); err != nil {
+		return err
+	}
+
+	// A partir daqui erros são da busca, não do uso da CLI: não exibe a ajuda
+	cmd.SilenceUsage = true
+
+	// Cada passo é exibido assim que termina
+	if output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		capacity.OnStep = func(step stresstest.CapacityStep) {
+			encoder.Encode(struct {
+				Type string `json:"type"`
+				stresstest.CapacityStep
+			}{Type: "capacity_step", CapacityStep: step})
+		}
+	} else {
+		fmt.Printf("Iniciando busca de capacidade: %d req/s, +%d req/s a cada %v\n", startRate, rateStep, stepDuration+warmup)
+		capacity.OnStep = func(step stresstest.CapacityStep) {
+			status := "✅"
+			if !step.Passed {
+				status = "❌"
+			}
+			fmt.Printf("%s %d req/s: %.1f req/s alcançados, p99 %v\n", status, step.Rate, step.Achieved,
+				step.Latency.Percentile(99).Round(time.Microsecond))
+		}
+	}
+
+	// Ctrl-C interrompe a busca, mas os passos concluídos ainda são exibidos
+	ctx, stop := interruptContext()
+	defer stop()
+
+	report, err := stresstest.FindCapacity(ctx, config, capacity)
+	if err != nil {
+		return fmt.Errorf("configuração inválida: %w", err)
+	}
+
+	if output == outputJSON {
+		if err := stresstest.WriteJSONCapacity(os.Stdout, report); err != nil {
+			return err
+		}
+	} else {
+		stresstest.PrintCapacity(report)
+	}
+
+	// Sem taxa sustentável ou com a busca interrompida o código de saída é não zero
+	if report.Aborted != "" {
+		return fmt.Errorf("busca interrompida: %s", report.Aborted)
+	}
+	if report.Sustainable == 0 {
+		return fmt.Errorf("nenhuma taxa testada atendeu o SLO")
+	}
+	return nil
+}
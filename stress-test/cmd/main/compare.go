@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+	"github.com/spf13/cobra"
+)
+
+// Variáveis para os parâmetros CLI da comparação de relatórios (em porcentagem)
+var (
+	latencyTolerance    float64 // Aumento aceito na latência
+	throughputTolerance float64 // Queda aceita em requests por segundo
+	errorRateTolerance  float64 // Aumento aceito nas taxas de erro, em pontos percentuais
+)
+
+// compareCmd compara dois relatórios salvos e falha quando há regressão
+var compareCmd = &cobra.Command{
+	Use:   "compare baseline.json atual.json",
+	Short: "Compara dois relatórios salvos e detecta regressões",
+	Long: `Compara um relatório de baseline com o de uma nova execução (salvos com --save ou com
+--output json), exibe as métricas lado a lado e termina com código de saída 1 quando alguma
+métrica piorou além da tolerância.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runCompare,
+}
+
+// init configura o subcomando e os flags de tolerância
+func init() {
+	compareCmd.Flags().Float64Var(&latencyTolerance, "latency-tolerance", stresstest.DefaultTolerance.Latency*100, "Aumento aceito na latência (%)")
+	compareCmd.Flags().Float64Var(&throughputTolerance, "throughput-tolerance", stresstest.DefaultTolerance.Throughput*100, "Queda aceita em requests por segundo (%)")
+	compareCmd.Flags().Float64Var(&errorRateTolerance, "error-tolerance", stresstest.DefaultTolerance.ErrorRate*100, "Aumento aceito nas taxas de erro (pontos percentuais)")
+
+	rootCmd.AddCommand(compareCmd)
+}
+
+// runCompare carrega os dois relatórios, exibe a comparação e retorna erro em caso de regressão
+func runCompare(cmd *cobra.Command, args []string) error {
+	if latencyTolerance < 0 || throughputTolerance < 0 || errorRateTolerance < 0 {
+		return fmt.Errorf("tolerâncias não podem ser negativas")
+	}
+
+	// A partir daqui os erros não são de uso da CLI: não exibe a ajuda
+	cmd.SilenceUsage = true
+
+	baseline, err := stresstest.LoadReport(args[0])
+	if err != nil {
+		return err
+	}
+	current, err := stresstest.LoadReport(args[1])
+	if err != nil {
+		return err
+	}
+
+	comparisons := stresstest.CompareReports(baseline, current, stresstest.Tolerance{
+		Latency:    latencyTolerance / 100,
+		Throughput: throughputTolerance / 100,
+		ErrorRate:  errorRateTolerance / 100,
+	})
+	stresstest.PrintComparison(comparisons)
+
+	if regressions := stresstest.Regressions(comparisons); regressions > 0 {
+		return fmt.Errorf("%d métricas regrediram em relação ao baseline", regressions)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+	"github.com/spf13/cobra"
+)
+
+// Variáveis para os parâmetros CLI do modo distribuído
+var (
+	agents      []string // Endereços dos agentes usados pelo coordenador
+	agentListen string   // Endereço em que o agente aguarda trabalhos
+	agentToken  string   // Token compartilhado entre o coordenador e os agentes
+)
+
+// agentTokenEnv é a variável de ambiente com o token padrão do agente e do coordenador
+const agentTokenEnv = "STRESS_TEST_AGENT_TOKEN"
+
+// agentCmd inicia um agente que executa as partes do teste enviadas pelo coordenador
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Inicia um agente de teste distribuído",
+	Long: `Inicia um agente que aguarda trabalhos do coordenador via HTTP, executa a sua parte
+do teste no instante combinado e devolve o relatório parcial.`,
+	Args: cobra.NoArgs,
+	RunE: runAgent,
+}
+
+// coordinatorCmd divide o teste entre os agentes e combina os relatórios parciais
+var coordinatorCmd = &cobra.Command{
+	Use:   "coordinator",
+	Short: "Distribui o teste de carga entre vários agentes",
+	Long: `Divide o teste de carga entre os agentes informados, inicia todos no mesmo instante e
+combina os relatórios parciais em um único relatório. Aceita os mesmos flags do teste local.`,
+	Args: cobra.NoArgs,
+	RunE: runStressTest,
+}
+
+// init configura os subcomandos e flags do modo distribuído
+func init() {
+	agentCmd.Flags().StringVar(&agentListen, "listen", "127.0.0.1:7070", "Endereço em que o agente aguarda o coordenador (ex: :7070 para aceitar outras máquinas)")
+	agentCmd.Flags().StringVar(&agentToken, "token", os.Getenv(agentTokenEnv), "Token exigido do coordenador (padrão: variável "+agentTokenEnv+")")
+	coordinatorCmd.Flags().StringArrayVar(&agents, "agent", nil, "Endereço do agente (host:porta, repetível)")
+	coordinatorCmd.Flags().StringVar(&agentToken, "token", os.Getenv(agentTokenEnv), "Token enviado aos agentes (padrão: variável "+agentTokenEnv+")")
+	coordinatorCmd.MarkFlagRequired("agent")
+
+	rootCmd.AddCommand(agentCmd, coordinatorCmd)
+}
+
+// runAgent mantém o agente escutando até o processo ser encerrado
+func runAgent(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	fmt.Printf("Agente aguardando o coordenador em %s\n", agentListen)
+	if agentToken == "" {
+		fmt.Printf("⚠️  Agente sem token: qualquer um que alcance %s pode disparar testes (use --token)\n", agentListen)
+	}
+	return http.ListenAndServe(agentListen, stresstest.NewAgent(agentToken))
+}
+
+// execute roda o teste localmente ou, no coordenador, distribuído entre os agentes
+func execute(ctx context.Context, config stresstest.Config) (stresstest.Report, error) {
+	if len(agents) > 0 {
+		return stresstest.RunDistributed(ctx, config, agents, agentToken)
+	}
+	return stresstest.Run(ctx, config), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+)
+
+// Variáveis para os parâmetros CLI do modo gRPC
+var (
+	grpcTarget   string   // Endereço do servidor gRPC
+	grpcMethod   string   // Método totalmente qualificado
+	grpcData     string   // Mensagem de requisição em JSON
+	protoFiles   []string // Arquivos .proto com a definição do serviço
+	importPaths  []string // Diretórios para resolver imports dos arquivos .proto
+	grpcMetadata []string // Metadados no formato "chave: valor"
+	grpcTLS      bool     // Conecta usando TLS
+)
+
+// init configura os flags do modo gRPC
+func init() {
+	rootCmd.Flags().StringVar(&grpcTarget, "grpc", "", "Endereço do servidor gRPC (host:porta); ativa o modo gRPC")
+	rootCmd.Flags().StringVar(&grpcMethod, "grpc-method", "", "Método gRPC totalmente qualificado (ex: pedidos.OrderService/CreateOrder)")
+	rootCmd.Flags().StringVar(&grpcData, "grpc-data", "", "Mensagem de requisição em JSON")
+	rootCmd.Flags().StringArrayVar(&protoFiles, "proto", nil, "Arquivo .proto com o serviço (repetível; sem ele usa a reflexão do servidor)")
+	rootCmd.Flags().StringArrayVar(&importPaths, "import-path", nil, "Diretório para resolver imports dos arquivos .proto (repetível)")
+	rootCmd.Flags().StringArrayVar(&grpcMetadata, "grpc-metadata", nil, "Metadado no formato \"chave: valor\" (repetível)")
+	rootCmd.Flags().BoolVar(&grpcTLS, "grpc-tls", false, "Conecta ao servidor gRPC usando TLS")
+}
+
+// buildGRPCConfig monta a chamada gRPC a partir dos flags informados.
+// Retorna nil quando o modo gRPC não foi ativado.
+func buildGRPCConfig() (*stresstest.GRPCConfig, error) {
+	if grpcTarget == "" {
+		return nil, nil
+	}
+
+	config := &stresstest.GRPCConfig{
+		Target:      grpcTarget,
+		Method:      grpcMethod,
+		Message:     grpcData,
+		ProtoFiles:  protoFiles,
+		ImportPaths: importPaths,
+		TLS:         grpcTLS,
+	}
+
+	// Metadados usam o mesmo formato dos cabeçalhos HTTP
+	if len(grpcMetadata) > 0 {
+		config.Metadata = make(map[string]string, len(grpcMetadata))
+		for _, value := range grpcMetadata {
+			key, content, err := stresstest.ParseHeader(value)
+			if err != nil {
+				return nil, err
+			}
+			config.Metadata[key] = content
+		}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+	"github.com/spf13/cobra"
+)
+
+// Variáveis para os parâmetros CLI da importação de requisições
+var (
+	importOutput  string // Arquivo onde o cenário gerado é gravado (saída padrão quando vazio)
+	importInclude string // Expressão regular das URLs importadas do HAR
+)
+
+// importCmd converte uma exportação HAR ou um comando curl em um arquivo de cenário
+var importCmd = &cobra.Command{
+	Use:   "import [arquivo.har | arquivo com comando curl | -]",
+	Short: "Converte um HAR do navegador ou um comando curl em um cenário",
+	Long: `Converte uma exportação HAR do navegador (DevTools > Network > Save all as HAR) ou um
+comando curl ("Copy as cURL") em um arquivo de cenário para usar com --scenario.
+
+Sem argumento ou com "-" o conteúdo é lido da entrada padrão, o que permite colar o comando
+curl diretamente. No HAR, requisições repetidas são agrupadas e a quantidade de ocorrências
+vira o peso de cada requisição.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runImport,
+}
+
+// init configura o subcomando e seus flags
+func init() {
+	importCmd.Flags().StringVarP(&importOutput, "output", "o", "", "Arquivo do cenário gerado (padrão: saída padrão)")
+	importCmd.Flags().StringVar(&importInclude, "include", "", "Importa do HAR apenas as URLs que satisfazem a expressão regular")
+
+	rootCmd.AddCommand(importCmd)
+}
+
+// runImport lê o HAR ou o comando curl e grava o cenário equivalente
+func runImport(cmd *cobra.Command, args []string) error {
+	var include *regexp.Regexp
+	if importInclude != "" {
+		var err error
+		if include, err = regexp.Compile(importInclude); err != nil {
+			return fmt.Errorf("--include inválido: %w", err)
+		}
+	}
+
+	// A partir daqui os erros não são de uso da CLI: não exibe a ajuda
+	cmd.SilenceUsage = true
+
+	input := os.Stdin
+	if len(args) == 1 && args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("erro ao abrir arquivo: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return fmt.Errorf("erro ao ler entrada: %w", err)
+	}
+
+	// O formato é detectado pelo conteúdo: HAR é um documento JSON, curl é um comando
+	content := strings.TrimSpace(string(data))
+	var scenario *stresstest.Scenario
+	switch {
+	case strings.HasPrefix(content, "{"):
+		scenario, err = stresstest.ImportHAR(strings.NewReader(content), include)
+	case strings.HasPrefix(content, "curl"):
+		scenario, err = stresstest.ImportCurl(content)
+	default:
+		return fmt.Errorf("formato não reconhecido: informe um arquivo HAR ou um comando curl")
+	}
+	if err != nil {
+		return err
+	}
+
+	if importOutput == "" {
+		return stresstest.WriteScenario(os.Stdout, scenario)
+	}
+	file, err := os.Create(importOutput)
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo do cenário: %w", err)
+	}
+	if err := stresstest.WriteScenario(file, scenario); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("erro ao gravar cenário: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Cenário com %d requisições salvo em %s\n", len(scenario.Requests), importOutput)
+	return nil
+}
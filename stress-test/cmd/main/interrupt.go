@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Variáveis para os parâmetros CLI de interrupção do teste
+var (
+	maxErrors   int           // Número de erros de transporte que interrompe o teste
+	gracePeriod time.Duration // Tempo para os requests em andamento terminarem após a interrupção
+)
+
+// init configura os flags de interrupção
+func init() {
+	rootCmd.Flags().IntVar(&maxErrors, "max-errors", 0, "Interrompe o teste ao atingir este número de erros de transporte (0 = sem limite)")
+	rootCmd.Flags().DurationVar(&gracePeriod, "grace-period", 5*time.Second, "Tempo para os requests em andamento terminarem após a interrupção")
+}
+
+// interruptContext retorna um contexto cancelado no primeiro SIGINT/SIGTERM. Depois do primeiro
+// sinal o comportamento padrão é restaurado: um segundo Ctrl-C encerra o processo imediatamente.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			fmt.Fprintf(os.Stderr, "\n⏹️  Interrompendo: aguardando até %v pelos requests em andamento (Ctrl-C novamente para sair)\n", gracePeriod)
+			cancel(fmt.Errorf("sinal %v recebido", sig))
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
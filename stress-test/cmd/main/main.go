@@ -5,16 +5,22 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
-	"github.com/spf13/cobra"
 	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+	"github.com/spf13/cobra"
 )
 
 // Variáveis globais para armazenar os valores dos parâmetros CLI
 var (
-	url         string // URL do serviço a ser testado
-	requests    int    // Número total de requests a serem enviados
-	concurrency int    // Número de requests simultâneos
+	url         string   // URL do serviço a ser testado
+	requests    int      // Número total de requests a serem enviados
+	concurrency int      // Número de requests simultâneos
+	stages      []string // Estágios do perfil de carga no formato duração:alvo[:nome]
+	profile     string   // Caminho do arquivo de perfil de carga (YAML ou JSON)
+	scenario    string   // Caminho do arquivo de cenário com várias requisições (YAML ou JSON)
+	flowFile    string   // Caminho do arquivo de fluxo de usuário virtual com vários passos (YAML ou JSON)
+	tagBy       string   // Agrupamento automático das requisições em tags (name ou url)
 )
 
 // rootCmd define o comando raiz da aplicação CLI usando Cobra
@@ -22,57 +28,247 @@ var rootCmd = &cobra.Command{
 	Use:   "stress-test",
 	Short: "Uma ferramenta CLI para testes de carga em serviços web",
 	Long: `Stress Test é uma ferramenta CLI desenvolvida em Go para realizar testes de carga
-em serviços web. Permite especificar URL, número de requests e nível de concorrência.`,
+em serviços web. Permite especificar URL, número de requests e nível de concorrência,
+ou um perfil de carga por estágios (ramp-up, patamares, picos e soak). Suporta HTTP e gRPC.`,
 	RunE: runStressTest,
 }
 
 // init configura os flags/parâmetros da linha de comando
 func init() {
 	// Configura os flags com versões curtas e longas
-	rootCmd.Flags().StringVarP(&url, "url", "u", "", "URL do serviço a ser testado (obrigatório)")
-	rootCmd.Flags().IntVarP(&requests, "requests", "r", 0, "Número total de requests (obrigatório)")
+	rootCmd.Flags().StringVarP(&url, "url", "u", "", "URL do serviço a ser testado (obrigatório sem cenário, gRPC ou streaming)")
+	rootCmd.Flags().IntVarP(&requests, "requests", "r", 0, "Número total de requests (obrigatório sem perfil de carga)")
 	rootCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 1, "Número de chamadas simultâneas")
-	
-	// Marca flags como obrigatórios
-	rootCmd.MarkFlagRequired("url")
-	rootCmd.MarkFlagRequired("requests")
+	rootCmd.Flags().StringArrayVar(&stages, "stage", nil, "Estágio do perfil de carga no formato duração:alvo[:nome] (repetível, ex: 60s:200:ramp-up)")
+	rootCmd.Flags().StringVar(&profile, "profile", "", "Arquivo YAML/JSON com os estágios do perfil de carga")
+	rootCmd.Flags().StringVar(&scenario, "scenario", "", "Arquivo YAML/JSON com várias requisições ponderadas e templates")
+	rootCmd.Flags().StringVar(&flowFile, "flow", "", "Arquivo YAML/JSON com o fluxo de usuário virtual (passos em ordem com variáveis extraídas)")
+	rootCmd.Flags().StringVar(&tagBy, "tag-by", "", "Métricas por tag agrupando as requisições sem tag: name (requisição do cenário ou passo do fluxo) ou url")
+
+	// Marca flags mutuamente exclusivos (a obrigatoriedade da URL é verificada na validação)
+	rootCmd.MarkFlagsMutuallyExclusive("stage", "profile")
+	rootCmd.MarkFlagsMutuallyExclusive("url", "scenario")
+	rootCmd.MarkFlagsMutuallyExclusive("url", "flow")
+	rootCmd.MarkFlagsMutuallyExclusive("scenario", "flow")
 }
 
 // runStressTest executa o teste de carga com os parâmetros fornecidos
 func runStressTest(cmd *cobra.Command, args []string) error {
+	// Monta o alvo do teste (requisição, cenário, fluxo, gRPC ou streaming) e o cliente
+	config, err := buildTargetConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Carrega o perfil de carga, se informado
+	loadedStages, err := loadStages()
+	if err != nil {
+		return err
+	}
+	config.Stages = loadedStages
+
+	// No streaming, sem -r, cada worker abre uma única sessão (uma conexão por unidade de concorrência)
+	if config.Stream != nil && len(config.Stages) == 0 && config.Requests == 0 {
+		config.Requests = config.Concurrency
+	}
+
+	// Critérios de aprovação
+	config.Thresholds, err = parseThresholds()
+	if err != nil {
+		return err
+	}
+	config.AbortOnFail = abortOnFail
+
+	// Destinos das métricas enviadas durante o teste
+	config.Sinks, err = buildSinks()
+	if err != nil {
+		return err
+	}
+
+	// Interrupção antecipada
+	config.MaxErrors = maxErrors
+	config.GracePeriod = gracePeriod
+
+	// Valida a configuração antes de prosseguir
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("configuração inválida: %w", err)
+	}
+	if err := validateOutput(); err != nil {
+		return err
+	}
+
+	// A partir daqui erros são do teste, não do uso da CLI: não exibe a ajuda
+	cmd.SilenceUsage = true
+
+	// Configura o acompanhamento em tempo real conforme o formato de saída
+	configureProgress(&config)
+
+	// Exibe informações do teste que será executado (apenas na saída em texto)
+	if output == outputText {
+		printHeader(config)
+	}
+
+	// Ctrl-C interrompe o teste, mas o relatório parcial ainda é exibido
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Executa o teste de carga (local ou distribuído) e obtém o relatório
+	report, err := execute(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	// Exibe o relatório final
+	if err := printReport(report); err != nil {
+		return err
+	}
+
+	// Avalia os thresholds (erro resulta em código de saída não zero)
+	if err := checkThresholds(config, report); err != nil {
+		return err
+	}
+
+	// Um teste interrompido também termina com código de saída não zero
+	if report.Aborted != "" {
+		return fmt.Errorf("relatório parcial: %s", report.Aborted)
+	}
+	return nil
+}
+
+// buildTargetConfig cria a configuração com o alvo do teste (requisição HTTP, cenário, fluxo,
+// chamada gRPC ou sessões de streaming), a concorrência e os ajustes do cliente, compartilhados
+// pelo teste de carga e pela busca de capacidade
+func buildTargetConfig(cmd *cobra.Command) (stresstest.Config, error) {
 	// Cria a configuração com os valores dos flags
 	config := stresstest.Config{
-		URL:         url,
 		Requests:    requests,
 		Concurrency: concurrency,
+		TagBy:       tagBy,
 	}
-	
-	// Valida a configuração antes de prosseguir
-	if err := config.Validate(); err != nil {
-		return fmt.Errorf("configuração inválida: %w", err)
+
+	// Ajustes do cliente: timeouts, conexões, TLS, proxy e substituições de DNS
+	transport, err := buildTransportConfig()
+	if err != nil {
+		return config, err
+	}
+	config.Transport = transport
+
+	// Comportamento diante de respostas 429
+	config.RespectRetryAfter = respectRetryAfter
+	config.MaxRetryAfter = maxRetryAfter
+
+	// Monta a requisição HTTP (método, cabeçalhos, corpo e autenticação)
+	spec, err := buildRequestSpec(cmd)
+	if err != nil {
+		return config, err
+	}
+	config.RequestSpec = spec
+
+	// Carrega o cenário com várias requisições, se informado
+	if scenario != "" {
+		loaded, err := stresstest.LoadScenario(scenario)
+		if err != nil {
+			return config, err
+		}
+		applyScenarioAssertions(loaded, spec.Assertions)
+		config.Scenario = loaded
+	}
+
+	// Carrega o fluxo de usuário virtual, se informado
+	if flowFile != "" {
+		loaded, err := stresstest.LoadFlow(flowFile)
+		if err != nil {
+			return config, err
+		}
+		applyFlowAssertions(loaded, spec.Assertions)
+		config.Flow = loaded
+	}
+
+	// Chamada gRPC, se o modo gRPC foi ativado
+	config.GRPC, err = buildGRPCConfig()
+	if err != nil {
+		return config, err
+	}
+
+	// Sessões WebSocket/SSE, se o modo streaming foi ativado
+	config.Stream, err = buildStreamConfig(spec.Headers)
+	if err != nil {
+		return config, err
 	}
 
-	// Exibe informações do teste que será executado
+	// Log de acesso reproduzido contra a URL base, se informado
+	config.Replay, err = buildReplay()
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+// printHeader exibe as informações do teste que será executado
+func printHeader(config stresstest.Config) {
 	fmt.Printf("Iniciando teste de carga...\n")
-	fmt.Printf("URL: %s\n", config.URL)
-	fmt.Printf("Total de requests: %d\n", config.Requests)
+	if config.Stream != nil {
+		fmt.Printf("Streaming: %s %s\n", config.Stream.Protocol, config.Stream.URL)
+	} else if config.GRPC != nil {
+		fmt.Printf("gRPC: %s %s\n", config.GRPC.Target, config.GRPC.Method)
+	} else if config.Flow != nil {
+		fmt.Printf("Fluxo: %s (%d passos)\n", flowFile, len(config.Flow.Steps))
+	} else if config.Replay != nil {
+		fmt.Printf("Replay: %s (%d requisições, %gx) contra %s\n", replayFile, len(config.Replay.Entries), replaySpeed, config.URL)
+	} else if config.Scenario != nil {
+		fmt.Printf("Cenário: %s (%d requisições)\n", scenario, len(config.Scenario.Requests))
+	} else {
+		fmt.Printf("URL: %s %s\n", config.RequestSpec.Method, config.URL)
+	}
+	if config.Replay != nil {
+		// A quantidade de requests e o ritmo vêm do log
+		last := config.Replay.Entries[len(config.Replay.Entries)-1]
+		fmt.Printf("Duração prevista: %v\n", time.Duration(float64(last.Offset)/replaySpeed).Round(time.Second))
+	} else if len(config.Stages) > 0 {
+		fmt.Printf("Perfil de carga: %d estágios\n", len(config.Stages))
+	} else if config.Flow != nil {
+		fmt.Printf("Total de iterações do fluxo: %d\n", config.Requests)
+	} else {
+		fmt.Printf("Total de requests: %d\n", config.Requests)
+	}
 	fmt.Printf("Concorrência: %d\n", config.Concurrency)
+	if config.RespectRetryAfter {
+		fmt.Printf("Respostas 429: aguarda o Retry-After (até %v)\n", config.MaxRetryAfter)
+	}
+	if len(agents) > 0 {
+		fmt.Printf("Agentes: %d\n", len(agents))
+	}
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+}
 
-	// Executa o teste de carga e obtém o relatório
-	report := stresstest.Run(config)
-	
-	// Exibe o relatório final
-	stresstest.PrintReport(report)
-	
-	return nil
+// loadStages monta os estágios do perfil de carga a partir do arquivo ou dos flags --stage
+func loadStages() ([]stresstest.Stage, error) {
+	// Arquivo de perfil tem prioridade (os flags são mutuamente exclusivos)
+	if profile != "" {
+		return stresstest.LoadProfile(profile)
+	}
+
+	var result []stresstest.Stage
+	for _, value := range stages {
+		stage, err := stresstest.ParseStage(value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stage)
+	}
+	return result, nil
 }
 
 // main é o ponto de entrada da aplicação
 func main() {
+	// O coordenador e a busca de capacidade aceitam os mesmos flags do teste local
+	coordinatorCmd.Flags().AddFlagSet(rootCmd.Flags())
+	findCapacityCmd.Flags().AddFlagSet(rootCmd.Flags())
+
 	// Executa o comando raiz e trata erros
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+)
+
+// Formatos de saída suportados
+const (
+	outputText = "text" // Relatório formatado para leitura no terminal
+	outputJSON = "json" // Snapshots e relatório como linhas JSON (NDJSON)
+)
+
+// Variáveis para os parâmetros CLI de saída
+var (
+	output   string // Formato de saída (text ou json)
+	progress bool   // Exibe o progresso em tempo real
+	save     string // Arquivo em que o relatório final é salvo em JSON
+	htmlFile string // Arquivo em que o relatório final é salvo em HTML
+)
+
+// init configura os flags de saída
+func init() {
+	rootCmd.Flags().StringVarP(&output, "output", "o", outputText, "Formato de saída: text ou json (NDJSON com snapshots a cada segundo e o relatório final)")
+	rootCmd.Flags().BoolVar(&progress, "progress", true, "Exibe o progresso em tempo real (desativado automaticamente quando a saída não é um terminal)")
+	rootCmd.Flags().StringVar(&save, "save", "", "Salva o relatório final em JSON (para comparar execuções com o subcomando compare)")
+	rootCmd.Flags().StringVar(&htmlFile, "html", "", "Salva o relatório final em uma página HTML autocontida, com gráficos")
+}
+
+// validateOutput verifica se o formato de saída informado é suportado
+func validateOutput() error {
+	if output != outputText && output != outputJSON {
+		return fmt.Errorf("formato de saída inválido %q: use text ou json", output)
+	}
+	return nil
+}
+
+// configureProgress define como o progresso será acompanhado conforme o formato de saída
+func configureProgress(config *stresstest.Config) {
+	switch {
+	case output == outputJSON:
+		// Na saída JSON os snapshots são sempre emitidos, um por linha
+		config.Progress = stresstest.NewJSONProgress(os.Stdout)
+	case progress && isTerminal(os.Stdout):
+		// Visualização em tempo real apenas quando a saída é um terminal interativo
+		config.Progress = stresstest.NewLiveView(os.Stdout).Update
+	}
+}
+
+// printReport exibe o relatório final no formato escolhido e o salva, se solicitado
+func printReport(report stresstest.Report) error {
+	if save != "" {
+		if err := saveReport(report); err != nil {
+			return err
+		}
+	}
+	if htmlFile != "" {
+		if err := saveHTMLReport(report); err != nil {
+			return err
+		}
+	}
+
+	if output == outputJSON {
+		return stresstest.WriteJSONReport(os.Stdout, report)
+	}
+	stresstest.PrintReport(report)
+	return nil
+}
+
+// saveReport grava o relatório final no arquivo informado em --save
+func saveReport(report stresstest.Report) error {
+	file, err := os.Create(save)
+	if err != nil {
+		return fmt.Errorf("erro ao salvar relatório: %w", err)
+	}
+	if err := stresstest.WriteJSONReport(file, report); err != nil {
+		file.Close()
+		return fmt.Errorf("erro ao salvar relatório: %w", err)
+	}
+	return file.Close()
+}
+
+// saveHTMLReport grava o relatório final em HTML no arquivo informado em --html
+func saveHTMLReport(report stresstest.Report) error {
+	file, err := os.Create(htmlFile)
+	if err != nil {
+		return fmt.Errorf("erro ao salvar relatório HTML: %w", err)
+	}
+	if err := stresstest.WriteHTMLReport(file, report); err != nil {
+		file.Close()
+		return fmt.Errorf("erro ao salvar relatório HTML: %w", err)
+	}
+	return file.Close()
+}
+
+// isTerminal indica se o arquivo é um terminal (e não um pipe ou arquivo redirecionado)
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"time"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+)
+
+// Variáveis para os parâmetros CLI de limite de taxa
+var (
+	respectRetryAfter bool          // Aguarda o Retry-After após respostas 429
+	maxRetryAfter     time.Duration // Maior espera após uma resposta 429
+)
+
+// init configura os flags de limite de taxa
+func init() {
+	rootCmd.Flags().BoolVar(&respectRetryAfter, "respect-retry-after", false, "Após uma resposta 429 o usuário virtual aguarda o Retry-After (ou a renovação da cota) antes do próximo request")
+	rootCmd.Flags().DurationVar(&maxRetryAfter, "max-retry-after", stresstest.DefaultMaxRetryAfter, "Maior espera após uma resposta 429 com --respect-retry-after")
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+)
+
+// Variáveis para os parâmetros CLI do replay de logs de acesso
+var (
+	replayFile  string  // Log de acesso reproduzido (Common/Combined Log Format ou JSON)
+	replaySpeed float64 // Fator de aceleração do replay
+)
+
+// init configura os flags do replay
+func init() {
+	rootCmd.Flags().StringVar(&replayFile, "replay", "", "Log de acesso (Common/Combined Log Format ou JSON) reproduzido contra a URL base (-u)")
+	rootCmd.Flags().Float64Var(&replaySpeed, "replay-speed", 1, "Fator de aceleração do replay (2 = duas vezes mais rápido que o log)")
+
+	rootCmd.MarkFlagsMutuallyExclusive("replay", "scenario")
+	rootCmd.MarkFlagsMutuallyExclusive("replay", "flow")
+}
+
+// buildReplay carrega o log de acesso informado.
+// Retorna nil quando o replay não foi ativado.
+func buildReplay() (*stresstest.Replay, error) {
+	if replayFile == "" {
+		return nil, nil
+	}
+	if replaySpeed <= 0 {
+		return nil, fmt.Errorf("--replay-speed deve ser maior que 0")
+	}
+
+	replay, err := stresstest.LoadAccessLog(replayFile)
+	if err != nil {
+		return nil, err
+	}
+	replay.Speed = replaySpeed
+
+	// Linhas não reconhecidas não impedem o teste, mas o usuário é avisado
+	if replay.Skipped > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  Linhas não reconhecidas no log de acesso (ignoradas): %d\n", replay.Skipped)
+	}
+	return replay, nil
+}
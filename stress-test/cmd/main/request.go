@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+	"github.com/spf13/cobra"
+)
+
+// Variáveis para os parâmetros CLI que personalizam a requisição HTTP
+var (
+	method      string   // Método HTTP da requisição
+	headers     []string // Cabeçalhos no formato "Nome: valor"
+	body        string   // Corpo da requisição
+	bodyFile    string   // Arquivo cujo conteúdo será usado como corpo da requisição
+	basicAuth   string   // Credenciais de autenticação básica no formato usuário:senha
+	bearerToken string   // Token para autenticação bearer
+	contentType string   // Valor do cabeçalho Content-Type
+	queryParams []string // Parâmetros de query no formato chave=valor
+	curlCommand string   // Comando curl de onde a requisição é importada
+)
+
+// init configura os flags de personalização da requisição
+func init() {
+	rootCmd.Flags().StringVarP(&method, "method", "X", http.MethodGet, "Método HTTP da requisição")
+	rootCmd.Flags().StringArrayVarP(&headers, "header", "H", nil, "Cabeçalho no formato \"Nome: valor\" (repetível)")
+	rootCmd.Flags().StringVarP(&body, "body", "d", "", "Corpo da requisição")
+	rootCmd.Flags().StringVar(&bodyFile, "body-file", "", "Arquivo com o corpo da requisição")
+	rootCmd.Flags().StringVar(&basicAuth, "basic-auth", "", "Autenticação básica no formato usuário:senha")
+	rootCmd.Flags().StringVar(&bearerToken, "bearer", "", "Token para autenticação Authorization: Bearer")
+	rootCmd.Flags().StringVar(&contentType, "content-type", "", "Valor do cabeçalho Content-Type")
+	rootCmd.Flags().StringArrayVarP(&queryParams, "query", "q", nil, "Parâmetro de query no formato chave=valor (repetível)")
+	rootCmd.Flags().StringVar(&curlCommand, "curl", "", "Comando curl com a requisição a ser testada (ex: copiado do navegador)")
+
+	rootCmd.MarkFlagsMutuallyExclusive("body", "body-file")
+	rootCmd.MarkFlagsMutuallyExclusive("curl", "url")
+	rootCmd.MarkFlagsMutuallyExclusive("curl", "scenario")
+	rootCmd.MarkFlagsMutuallyExclusive("curl", "flow")
+	rootCmd.MarkFlagsMutuallyExclusive("curl", "body")
+	rootCmd.MarkFlagsMutuallyExclusive("curl", "body-file")
+	rootCmd.MarkFlagsMutuallyExclusive("basic-auth", "bearer")
+}
+
+// buildRequestSpec monta a especificação da requisição a partir dos flags informados
+func buildRequestSpec(cmd *cobra.Command) (stresstest.RequestSpec, error) {
+	spec := stresstest.RequestSpec{
+		Method:      strings.ToUpper(method),
+		URL:         url,
+		Body:        body,
+		ContentType: contentType,
+		BearerToken: bearerToken,
+		Assertions:  buildAssertions(),
+	}
+
+	// Requisição importada de um comando curl: os demais flags complementam ou substituem os seus valores
+	if curlCommand != "" {
+		parsed, err := stresstest.ParseCurl(curlCommand)
+		if err != nil {
+			return spec, fmt.Errorf("--curl: %w", err)
+		}
+		spec.URL = parsed.URL
+		spec.Headers = parsed.Headers
+		spec.Body = parsed.Body
+		spec.BasicAuth = parsed.BasicAuth
+		if !cmd.Flags().Changed("method") {
+			spec.Method = parsed.Method
+		}
+		if spec.ContentType == "" {
+			spec.ContentType = parsed.ContentType
+		}
+		if spec.BearerToken == "" {
+			spec.BearerToken = parsed.BearerToken
+		}
+	}
+
+	// Corpo lido de arquivo
+	if bodyFile != "" {
+		data, err := os.ReadFile(bodyFile)
+		if err != nil {
+			return spec, fmt.Errorf("erro ao ler corpo da requisição: %w", err)
+		}
+		spec.Body = string(data)
+	}
+
+	// Cabeçalhos personalizados
+	if len(headers) > 0 {
+		if spec.Headers == nil {
+			spec.Headers = make(map[string]string, len(headers))
+		}
+		for _, value := range headers {
+			name, content, err := stresstest.ParseHeader(value)
+			if err != nil {
+				return spec, err
+			}
+			// Substitui o cabeçalho de mesmo nome vindo do --curl, mesmo com outra capitalização
+			for existing := range spec.Headers {
+				if strings.EqualFold(existing, name) {
+					delete(spec.Headers, existing)
+				}
+			}
+			spec.Headers[name] = content
+		}
+	}
+
+	// Parâmetros de query
+	if len(queryParams) > 0 {
+		spec.Query = make(map[string]string, len(queryParams))
+		for _, value := range queryParams {
+			key, content, err := stresstest.ParseQueryParam(value)
+			if err != nil {
+				return spec, err
+			}
+			spec.Query[key] = content
+		}
+	}
+
+	// Autenticação básica
+	if basicAuth != "" {
+		credentials, err := stresstest.ParseBasicAuth(basicAuth)
+		if err != nil {
+			return spec, err
+		}
+		spec.BasicAuth = credentials
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+)
+
+// Variáveis para os parâmetros CLI de envio de métricas
+var (
+	sinks       []string // Destinos no formato formato=url
+	sinkLabels  []string // Rótulos no formato chave=valor adicionados a todas as métricas
+	sinkHeaders []string // Cabeçalhos enviados aos destinos no formato "Nome: valor"
+)
+
+// init configura os flags de envio de métricas
+func init() {
+	rootCmd.Flags().StringArrayVar(&sinks, "sink", nil, "Envia as métricas durante o teste: prometheus|remote-write|otlp|influxdb=URL (repetível)")
+	rootCmd.Flags().StringArrayVar(&sinkLabels, "sink-label", nil, "Rótulo chave=valor adicionado a todas as métricas enviadas (repetível)")
+	rootCmd.Flags().StringArrayVar(&sinkHeaders, "sink-header", nil, "Cabeçalho \"Nome: valor\" enviado aos destinos das métricas (repetível)")
+}
+
+// buildSinks monta os destinos das métricas a partir dos flags informados
+func buildSinks() ([]stresstest.Sink, error) {
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	if len(agents) > 0 {
+		return nil, fmt.Errorf("--sink não é suportado no modo distribuído")
+	}
+
+	labels := make(map[string]string, len(sinkLabels))
+	for _, value := range sinkLabels {
+		name, content, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("rótulo inválido %q: use o formato chave=valor", value)
+		}
+		labels[strings.TrimSpace(name)] = strings.TrimSpace(content)
+	}
+
+	headers := make(map[string]string, len(sinkHeaders))
+	for _, value := range sinkHeaders {
+		name, content, err := stresstest.ParseHeader(value)
+		if err != nil {
+			return nil, err
+		}
+		headers[name] = content
+	}
+
+	var result []stresstest.Sink
+	for _, value := range sinks {
+		sink, err := stresstest.ParseSink(value)
+		if err != nil {
+			return nil, err
+		}
+		sink.Labels = labels
+		sink.Headers = headers
+		result = append(result, sink)
+	}
+	return result, nil
+}
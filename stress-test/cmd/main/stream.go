@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+)
+
+// Variáveis para os parâmetros CLI do modo streaming
+var (
+	wsURL           string        // URL do endpoint WebSocket
+	sseURL          string        // URL do endpoint SSE
+	wsMessages      []string      // Mensagens enviadas após conectar no WebSocket
+	messageInterval time.Duration // Intervalo entre as mensagens enviadas
+	hold            time.Duration // Tempo que cada conexão permanece aberta
+)
+
+// init configura os flags do modo streaming
+func init() {
+	rootCmd.Flags().StringVar(&wsURL, "ws", "", "URL WebSocket (ws:// ou wss://); ativa o modo streaming")
+	rootCmd.Flags().StringVar(&sseURL, "sse", "", "URL de Server-Sent Events (http:// ou https://); ativa o modo streaming")
+	rootCmd.Flags().StringArrayVar(&wsMessages, "ws-message", nil, "Mensagem enviada após conectar no WebSocket (repetível, enviadas em ordem)")
+	rootCmd.Flags().DurationVar(&messageInterval, "message-interval", 0, "Intervalo entre as mensagens enviadas no WebSocket (padrão 1s)")
+	rootCmd.Flags().DurationVar(&hold, "hold", 0, "Tempo que cada conexão permanece aberta (padrão 10s)")
+}
+
+// buildStreamConfig monta as sessões WebSocket/SSE a partir dos flags informados, reaproveitando
+// os cabeçalhos de -H. Retorna nil quando o modo streaming não foi ativado.
+func buildStreamConfig(headers map[string]string) (*stresstest.StreamConfig, error) {
+	if wsURL == "" && sseURL == "" {
+		return nil, nil
+	}
+	if wsURL != "" && sseURL != "" {
+		return nil, fmt.Errorf("informe --ws ou --sse, não ambos")
+	}
+
+	config := &stresstest.StreamConfig{
+		Protocol:        stresstest.ProtocolWebSocket,
+		URL:             wsURL,
+		Headers:         headers,
+		Messages:        wsMessages,
+		MessageInterval: messageInterval,
+		Duration:        hold,
+	}
+	if sseURL != "" {
+		config.Protocol = stresstest.ProtocolSSE
+		config.URL = sseURL
+	}
+	return config, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+)
+
+// Variáveis para os parâmetros CLI de critérios de aprovação
+var (
+	thresholds  []string // Expressões de threshold (ex: p95<300ms)
+	abortOnFail bool     // Interrompe o teste quando um threshold é irremediavelmente violado
+)
+
+// init configura os flags de thresholds
+func init() {
+	rootCmd.Flags().StringArrayVar(&thresholds, "threshold", nil, "Critério de aprovação (repetível), ex: p95<300ms, error_rate<1%, rps>500, status_2xx>99%")
+	rootCmd.Flags().BoolVar(&abortOnFail, "abort-on-fail", false, "Interrompe o teste assim que um threshold não puder mais ser atendido")
+}
+
+// parseThresholds interpreta as expressões informadas nos flags --threshold
+func parseThresholds() ([]stresstest.Threshold, error) {
+	var result []stresstest.Threshold
+	for _, expression := range thresholds {
+		threshold, err := stresstest.ParseThreshold(expression)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, threshold)
+	}
+	return result, nil
+}
+
+// checkThresholds avalia os thresholds, exibe a tabela de resultados e retorna erro
+// quando algum deles falhou, fazendo o processo terminar com código de saída não zero
+func checkThresholds(config stresstest.Config, report stresstest.Report) error {
+	if len(config.Thresholds) == 0 {
+		return nil
+	}
+
+	results := stresstest.EvaluateThresholds(config.Thresholds, report)
+	if output == outputJSON {
+		if err := stresstest.WriteJSONChecks(os.Stdout, results); err != nil {
+			return err
+		}
+	} else {
+		stresstest.PrintChecks(results)
+	}
+
+	if failed := stresstest.FailedChecks(results); failed > 0 {
+		return fmt.Errorf("%d de %d thresholds falharam", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"time"
+
+	"github.com/rafabene/go-projects/stress-test/pkg/stresstest"
+)
+
+// Variáveis para os parâmetros CLI de ajuste do cliente HTTP
+var (
+	timeout             time.Duration // Tempo máximo de cada request
+	dialTimeout         time.Duration // Tempo máximo para estabelecer a conexão TCP
+	tlsHandshakeTimeout time.Duration // Tempo máximo do handshake TLS
+	responseTimeout     time.Duration // Tempo máximo aguardando os cabeçalhos da resposta
+	maxIdleConns        int           // Conexões ociosas mantidas por host
+	keepAlive           bool          // Reutiliza conexões entre requests
+	http2               bool          // Permite negociar HTTP/2 em conexões TLS
+	insecure            bool          // Não valida o certificado TLS do servidor
+	clientCert          string        // Certificado do cliente para mTLS (PEM)
+	clientKey           string        // Chave privada do certificado do cliente (PEM)
+	caCert              string        // CAs aceitas no certificado do servidor (PEM)
+	serverName          string        // Nome enviado no SNI e validado no certificado
+	proxy               string        // URL do proxy HTTP, HTTPS ou SOCKS5
+	resolve             []string      // Substituições de DNS no formato host:ip
+)
+
+// init configura os flags de ajuste do cliente HTTP
+func init() {
+	rootCmd.Flags().DurationVar(&timeout, "timeout", stresstest.DefaultTimeout, "Tempo máximo de cada request")
+	rootCmd.Flags().DurationVar(&dialTimeout, "dial-timeout", 10*time.Second, "Tempo máximo para estabelecer a conexão TCP")
+	rootCmd.Flags().DurationVar(&tlsHandshakeTimeout, "tls-handshake-timeout", 10*time.Second, "Tempo máximo do handshake TLS")
+	rootCmd.Flags().DurationVar(&responseTimeout, "response-header-timeout", 0, "Tempo máximo aguardando os cabeçalhos da resposta (0 = sem limite)")
+	rootCmd.Flags().IntVar(&maxIdleConns, "max-idle-conns", 0, "Conexões ociosas mantidas por host (0 = igual à concorrência)")
+	rootCmd.Flags().BoolVar(&keepAlive, "keep-alive", true, "Reutiliza conexões entre requests (--keep-alive=false abre uma conexão por request)")
+	rootCmd.Flags().BoolVar(&http2, "http2", true, "Permite negociar HTTP/2 em conexões TLS")
+	rootCmd.Flags().BoolVarP(&insecure, "insecure", "k", false, "Não valida o certificado TLS do servidor")
+	rootCmd.Flags().StringVar(&clientCert, "cert", "", "Arquivo PEM com o certificado do cliente (mTLS, requer --key)")
+	rootCmd.Flags().StringVar(&clientKey, "key", "", "Arquivo PEM com a chave privada do certificado do cliente")
+	rootCmd.Flags().StringVar(&caCert, "cacert", "", "Arquivo PEM com as CAs aceitas no certificado do servidor (substitui as do sistema)")
+	rootCmd.Flags().StringVar(&serverName, "sni", "", "Nome enviado no SNI e validado no certificado (padrão: host da URL)")
+	rootCmd.Flags().StringVar(&proxy, "proxy", "", "URL do proxy (http://, https:// ou socks5://); padrão: HTTP_PROXY/HTTPS_PROXY")
+	rootCmd.Flags().StringArrayVar(&resolve, "resolve", nil, "Usa o IP informado para o host no lugar do DNS, formato host:ip (repetível)")
+	rootCmd.MarkFlagsRequiredTogether("cert", "key")
+}
+
+// buildTransportConfig monta os ajustes do cliente HTTP a partir dos flags informados
+func buildTransportConfig() (stresstest.TransportConfig, error) {
+	config := stresstest.TransportConfig{
+		Timeout:               timeout,
+		DialTimeout:           dialTimeout,
+		TLSHandshakeTimeout:   tlsHandshakeTimeout,
+		ResponseHeaderTimeout: responseTimeout,
+		MaxIdleConnsPerHost:   maxIdleConns,
+		DisableKeepAlives:     !keepAlive,
+		DisableHTTP2:          !http2,
+		InsecureSkipVerify:    insecure,
+		ClientCert:            clientCert,
+		ClientKey:             clientKey,
+		CACert:                caCert,
+		ServerName:            serverName,
+		Proxy:                 proxy,
+	}
+
+	for _, value := range resolve {
+		host, ip, err := stresstest.ParseResolve(value)
+		if err != nil {
+			return config, err
+		}
+		if config.Resolve == nil {
+			config.Resolve = make(map[string]string)
+		}
+		config.Resolve[host] = ip
+	}
+	return config, nil
+}
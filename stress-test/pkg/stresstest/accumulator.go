@@ -0,0 +1,160 @@
+package stresstest
+
+import (
+	"sync"
+	"time"
+)
+
+// accumulator agrega os resultados produzidos por um único worker.
+// Cada worker possui o seu, de modo que o registro de um resultado não disputa trava com os
+// demais workers e a memória usada independe do número total de requests: apenas contadores
+// e histogramas são mantidos, nunca os resultados individuais.
+type accumulator struct {
+	mu     sync.Mutex        // Protege as métricas para leituras concorrentes durante o teste
+	stats  Stats             // Métricas de todas as requisições do worker
+	stages []Stats           // Métricas por estágio do perfil de carga
+	steps  []Stats           // Métricas por passo do fluxo
+	tags   map[string]*Stats // Métricas por tag (criadas à medida que aparecem)
+	tagSet *tagSet           // Conjunto de tags do teste, compartilhado com os demais workers
+
+	iterations int // Iterações do fluxo executadas
+	completed  int // Iterações do fluxo concluídas com sucesso
+
+	start   time.Time      // Início do teste, referência do instante da primeira recusa
+	pending TimelineBucket // Métricas desde a última coleta da linha do tempo (ver startTimeline)
+
+	// Métricas da janela atual, zeradas a cada coleta de progresso
+	window         Histogram // Latências registradas desde a última coleta
+	windowRequests int       // Requests concluídos desde a última coleta
+}
+
+// newAccumulator cria um acumulador com uma entrada para cada estágio do perfil e para cada
+// passo do fluxo. As métricas por tag são criadas à medida que as tags aparecem, conforme o
+// conjunto de tags compartilhado entre os workers.
+func newAccumulator(config Config, start time.Time, tags *tagSet) *accumulator {
+	acc := &accumulator{stats: newStats(), start: start, tags: make(map[string]*Stats), tagSet: tags}
+	for range config.Stages {
+		acc.stages = append(acc.stages, newStats())
+	}
+	if config.Flow != nil {
+		for range config.Flow.Steps {
+			acc.steps = append(acc.steps, newStats())
+		}
+	}
+	return acc
+}
+
+// add contabiliza um resultado nas métricas do worker.
+func (a *accumulator) add(result Result) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.addLocked(result)
+}
+
+// addIteration contabiliza os passos executados em uma iteração do fluxo, na ordem do fluxo.
+func (a *accumulator) addIteration(steps []Result, completed bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.iterations++
+	if completed {
+		a.completed++
+	}
+	for i, step := range steps {
+		a.addLocked(step)
+		a.steps[i].add(step)
+	}
+}
+
+// addLocked contabiliza um resultado; a trava já deve estar adquirida.
+func (a *accumulator) addLocked(result Result) {
+	elapsed := time.Since(a.start)
+	a.stats.add(result)
+	a.pending.record(result)
+
+	// Instante da primeira recusa por limite de taxa (apenas nas métricas gerais)
+	if result.throttled() && a.stats.Throttle.FirstThrottle == 0 {
+		a.stats.Throttle.FirstThrottle = elapsed
+	}
+
+	// Janela usada no acompanhamento em tempo real
+	a.windowRequests++
+	if result.Error == nil {
+		a.window.Record(result.Duration)
+	}
+
+	// Contabiliza também no estágio em que o request foi disparado
+	if result.Stage >= 0 {
+		a.stages[result.Stage].add(result)
+	}
+
+	// E na tag da requisição, quando houver (acima do limite do teste, em otherTags)
+	if result.Tag != "" {
+		tag := a.tagSet.resolve(result.Tag)
+		stats, ok := a.tags[tag]
+		if !ok {
+			stats = &Stats{}
+			*stats = newStats()
+			a.tags[tag] = stats
+		}
+		stats.add(result)
+	}
+}
+
+// addBackoff contabiliza uma espera feita pelo worker após uma resposta 429.
+func (a *accumulator) addBackoff(wait time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stats.Throttle == nil {
+		a.stats.Throttle = newThrottleStats()
+	}
+	a.stats.Throttle.Backoffs++
+	a.stats.Throttle.BackoffTime += wait
+}
+
+// mergeInto soma as métricas do worker no relatório informado; as métricas por tag são somadas
+// em tags e incluídas no relatório ao final.
+func (a *accumulator) mergeInto(report *Report, tags *tagMerger) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	report.Stats.merge(a.stats)
+	for i := range a.stages {
+		report.Stages[i].Stats.merge(a.stages[i])
+	}
+	for i := range a.steps {
+		report.Steps[i].Stats.merge(a.steps[i])
+	}
+	for tag, stats := range a.tags {
+		tags.merge(tag, *stats)
+	}
+	report.Iterations += a.iterations
+	report.CompletedIterations += a.completed
+}
+
+// collectTimeline soma no intervalo informado as métricas registradas desde a última coleta da
+// linha do tempo, zerando as do worker.
+func (a *accumulator) collectTimeline(bucket *TimelineBucket) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	bucket.merge(a.pending)
+	a.pending = TimelineBucket{}
+}
+
+// collect soma as métricas acumuladas e as da janela atual nos destinos informados,
+// zerando a janela do worker. Usado para gerar os snapshots de progresso.
+func (a *accumulator) collect(total *Stats, window *Histogram) (windowRequests int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	total.merge(a.stats)
+	window.Merge(a.window)
+	windowRequests = a.windowRequests
+
+	a.window = Histogram{}
+	a.windowRequests = 0
+	return windowRequests
+}
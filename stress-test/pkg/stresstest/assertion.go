@@ -0,0 +1,184 @@
+package stresstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxAssertionBody limita quantos bytes do corpo são mantidos em memória para as asserções.
+const maxAssertionBody = 10 << 20
+
+// Assertions descreve as validações aplicadas a cada resposta.
+// Uma resposta que não atende alguma asserção deixa de contar como sucesso no relatório,
+// mesmo que o status seja 200.
+type Assertions struct {
+	Status       []int             `yaml:"status,omitempty"`        // Códigos de status aceitos
+	BodyContains []string          `yaml:"body_contains,omitempty"` // Textos que o corpo deve conter
+	BodyRegex    []string          `yaml:"body_regex,omitempty"`    // Expressões regulares que o corpo deve satisfazer
+	JSONEquals   map[string]string `yaml:"json_equals,omitempty"`   // Caminho JSON -> valor esperado (ex: $.temp_c -> 28.5)
+	JSONExists   []string          `yaml:"json_exists,omitempty"`   // Caminhos JSON que devem existir (ex: $.temp_f)
+	Headers      []string          `yaml:"headers,omitempty"`       // Cabeçalhos que devem estar presentes
+	MaxLatency   time.Duration     `yaml:"max_latency,omitempty"`   // Tempo máximo de resposta aceito
+
+	once    sync.Once        // Garante que as expressões sejam compiladas uma única vez
+	err     error            // Erro de compilação das expressões
+	regexes []*regexp.Regexp // Expressões regulares compiladas
+}
+
+// validate compila as expressões regulares e verifica os caminhos JSON.
+func (a *Assertions) validate() error {
+	a.once.Do(func() {
+		for _, expression := range a.BodyRegex {
+			re, err := regexp.Compile(expression)
+			if err != nil {
+				a.err = fmt.Errorf("asserção body_regex inválida %q: %w", expression, err)
+				return
+			}
+			a.regexes = append(a.regexes, re)
+		}
+		for _, path := range append(slices.Collect(maps.Keys(a.JSONEquals)), a.JSONExists...) {
+			if _, err := parseJSONPath(path); err != nil {
+				a.err = err
+				return
+			}
+		}
+		if a.MaxLatency < 0 {
+			a.err = fmt.Errorf("asserção max_latency não pode ser negativa")
+		}
+	})
+	return a.err
+}
+
+// needsBody indica se alguma asserção depende do conteúdo do corpo da resposta.
+func (a *Assertions) needsBody() bool {
+	return len(a.BodyContains) > 0 || len(a.BodyRegex) > 0 || len(a.JSONEquals) > 0 || len(a.JSONExists) > 0
+}
+
+// check aplica as asserções à resposta e retorna o nome das que falharam.
+func (a *Assertions) check(resp *http.Response, body []byte, duration time.Duration) []string {
+	var failed []string
+
+	if len(a.Status) > 0 && !slices.Contains(a.Status, resp.StatusCode) {
+		failed = append(failed, "status")
+	}
+
+	for _, text := range a.BodyContains {
+		if !bytes.Contains(body, []byte(text)) {
+			failed = append(failed, "body_contains: "+text)
+		}
+	}
+
+	for _, re := range a.regexes {
+		if !re.Match(body) {
+			failed = append(failed, "body_regex: "+re.String())
+		}
+	}
+
+	// O corpo só é decodificado quando há asserções de JSON
+	if len(a.JSONEquals) > 0 || len(a.JSONExists) > 0 {
+		var document any
+		valid := json.Unmarshal(body, &document) == nil
+
+		// Caminhos em ordem alfabética para que os nomes das falhas sejam estáveis
+		for _, path := range slices.Sorted(maps.Keys(a.JSONEquals)) {
+			value, ok := lookupJSONPath(document, path)
+			if !valid || !ok || formatJSONValue(value) != a.JSONEquals[path] {
+				failed = append(failed, "json_equals: "+path)
+			}
+		}
+		for _, path := range a.JSONExists {
+			if _, ok := lookupJSONPath(document, path); !valid || !ok {
+				failed = append(failed, "json_exists: "+path)
+			}
+		}
+	}
+
+	for _, header := range a.Headers {
+		if resp.Header.Get(header) == "" {
+			failed = append(failed, "header: "+header)
+		}
+	}
+
+	if a.MaxLatency > 0 && duration > a.MaxLatency {
+		failed = append(failed, "max_latency")
+	}
+
+	return failed
+}
+
+// parseJSONPath interpreta um caminho simples como "$.current.temp_c" ou "$.items[0].id".
+// Retorna os segmentos do caminho: nomes de campos (string) e índices de arrays (int).
+func parseJSONPath(path string) ([]any, error) {
+	rest := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if rest == "" {
+		return nil, fmt.Errorf("caminho JSON vazio %q", path)
+	}
+
+	var segments []any
+	for _, part := range strings.Split(rest, ".") {
+		// Separa o nome do campo dos índices de array (ex: items[0][1])
+		name, indexes, _ := strings.Cut(part, "[")
+		if name != "" {
+			segments = append(segments, name)
+		}
+		if indexes == "" {
+			continue
+		}
+		for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("índice inválido no caminho JSON %q", path)
+			}
+			segments = append(segments, i)
+		}
+	}
+	return segments, nil
+}
+
+// lookupJSONPath percorre o documento JSON decodificado seguindo o caminho informado.
+func lookupJSONPath(document any, path string) (any, bool) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false
+	}
+
+	current := document
+	for _, segment := range segments {
+		switch key := segment.(type) {
+		case string:
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[key]; !ok {
+				return nil, false
+			}
+		case int:
+			array, ok := current.([]any)
+			if !ok || key >= len(array) {
+				return nil, false
+			}
+			current = array[key]
+		}
+	}
+	return current, true
+}
+
+// formatJSONValue converte um valor JSON em texto para comparação com o valor esperado.
+// Strings são comparadas sem aspas; os demais tipos usam a sua representação JSON.
+func formatJSONValue(value any) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
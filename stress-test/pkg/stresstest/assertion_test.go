@@ -0,0 +1,108 @@
+package stresstest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAssertions_Check(t *testing.T) {
+	corpo := []byte(`{"temp_c": 28.5, "temp_f": 83.3, "cidade": "Recife", "leituras": [{"id": 7}]}`)
+	resposta := &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"application/json"}}}
+
+	testes := []struct {
+		nome       string
+		assertions *Assertions
+		latencia   time.Duration
+		falhas     []string
+	}{
+		{"tudo ok", &Assertions{
+			Status:       []int{200, 201},
+			BodyContains: []string{"Recife"},
+			BodyRegex:    []string{`"temp_c":\s*\d+`},
+			JSONEquals:   map[string]string{"$.temp_c": "28.5", "$.cidade": "Recife", "$.leituras[0].id": "7"},
+			JSONExists:   []string{"$.temp_f"},
+			Headers:      []string{"content-type"},
+			MaxLatency:   time.Second,
+		}, 10 * time.Millisecond, nil},
+		{"status inesperado", &Assertions{Status: []int{201}}, 0, []string{"status"}},
+		{"texto ausente", &Assertions{BodyContains: []string{"Natal"}}, 0, []string{"body_contains: Natal"}},
+		{"regex", &Assertions{BodyRegex: []string{`temp_k`}}, 0, []string{"body_regex: temp_k"}},
+		{"valor JSON diferente", &Assertions{JSONEquals: map[string]string{"$.temp_c": "30"}}, 0, []string{"json_equals: $.temp_c"}},
+		{"caminho JSON ausente", &Assertions{JSONExists: []string{"$.temp_k", "$.leituras[3]"}}, 0,
+			[]string{"json_exists: $.temp_k", "json_exists: $.leituras[3]"}},
+		{"cabeçalho ausente", &Assertions{Headers: []string{"X-Request-Id"}}, 0, []string{"header: X-Request-Id"}},
+		{"latência", &Assertions{MaxLatency: time.Millisecond}, 5 * time.Millisecond, []string{"max_latency"}},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			if err := tt.assertions.validate(); err != nil {
+				t.Fatalf("Erro inesperado na validação: %v", err)
+			}
+			falhas := tt.assertions.check(resposta, corpo, tt.latencia)
+			if !slices.Equal(falhas, tt.falhas) {
+				t.Errorf("Esperado falhas %v, obtido %v", tt.falhas, falhas)
+			}
+		})
+	}
+}
+
+func TestAssertions_Validacao(t *testing.T) {
+	invalidas := []*Assertions{
+		{BodyRegex: []string{"("}},
+		{JSONExists: []string{"$"}},
+		{JSONEquals: map[string]string{"$.itens[x]": "1"}},
+		{MaxLatency: -time.Second},
+	}
+	for _, assertions := range invalidas {
+		spec := RequestSpec{URL: "http://localhost", Assertions: assertions}
+		if err := spec.validate(); err == nil {
+			t.Errorf("Esperado erro de validação para %+v", assertions)
+		}
+	}
+}
+
+func TestRun_AssercoesNaoContamComoSucesso(t *testing.T) {
+	// Metade das respostas vem sem o campo temp_k, mas sempre com status 200
+	contador := 0
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contador++
+		if contador%2 == 0 {
+			w.Write([]byte(`{"temp_c": 25, "temp_f": 77}`))
+			return
+		}
+		w.Write([]byte(`{"temp_c": 25, "temp_f": 77, "temp_k": 298.15}`))
+	}))
+	defer servidor.Close()
+
+	config := Config{
+		RequestSpec: RequestSpec{
+			URL:        servidor.URL,
+			Assertions: &Assertions{JSONExists: []string{"$.temp_c", "$.temp_f", "$.temp_k"}},
+		},
+		Requests:    10,
+		Concurrency: 1,
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Erro inesperado na validação: %v", err)
+	}
+	report := Run(context.Background(), config)
+
+	if report.StatusCodes[200] != 10 {
+		t.Errorf("Esperado 10 respostas 200, obtido %d", report.StatusCodes[200])
+	}
+	if report.SuccessCount != 5 || report.AssertionFailedCount != 5 {
+		t.Errorf("Esperado 5 sucessos e 5 reprovações, obtido %d e %d", report.SuccessCount, report.AssertionFailedCount)
+	}
+	if report.AssertionFailures["json_exists: $.temp_k"] != 5 {
+		t.Errorf("Esperado 5 falhas em temp_k, obtido %v", report.AssertionFailures)
+	}
+	// O corpo lido para as asserções continua contabilizado nos bytes recebidos
+	if report.BytesReceived == 0 {
+		t.Error("Esperado bytes recebidos contabilizados")
+	}
+}
@@ -0,0 +1,224 @@
+package stresstest
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultCapacityWarmup é o tempo padrão de aquecimento de cada passo da busca de capacidade,
+// durante o qual a taxa sobe de 0 até o alvo do passo sem entrar nas métricas.
+const DefaultCapacityWarmup = 5 * time.Second
+
+// capacityRateTolerance é a fração mínima da taxa alvo que o passo precisa alcançar. Abaixo
+// dela os workers não deram conta da taxa e as latências medidas não representam a carga pedida.
+const capacityRateTolerance = 0.9
+
+// CapacityConfig define a busca da maior taxa sustentável: a taxa de chegada começa em
+// StartRate e aumenta RateStep a cada passo até que o SLO seja violado ou MaxRate seja atingido.
+// Cada passo é um teste independente com aquecimento (Warmup) seguido de um patamar na taxa
+// alvo (StepDuration); apenas o patamar é avaliado.
+type CapacityConfig struct {
+	StartRate    int           `json:"start_rate"`    // Taxa do primeiro passo (requests por segundo)
+	RateStep     int           `json:"rate_step"`     // Incremento da taxa a cada passo
+	MaxRate      int           `json:"max_rate"`      // Taxa máxima testada (0 = sem limite)
+	StepDuration time.Duration `json:"step_duration"` // Duração do patamar de cada passo
+	Warmup       time.Duration `json:"warmup"`        // Aquecimento de cada passo (padrão DefaultCapacityWarmup)
+	SLO          []Threshold   `json:"slo"`           // Critérios que cada passo precisa atender (ex: p99<300ms, error_rate<1%)
+
+	OnStep func(CapacityStep) `json:"-"` // Chamada ao fim de cada passo (opcional)
+}
+
+// CapacityStep contém o resultado de um passo da busca de capacidade.
+type CapacityStep struct {
+	Rate     int           `json:"rate"`             // Taxa alvo do passo (requests por segundo)
+	Achieved float64       `json:"achieved_rps"`     // Taxa efetivamente disparada durante o patamar
+	Checks   []CheckResult `json:"checks"`           // Avaliação de cada critério do SLO
+	Passed   bool          `json:"passed"`           // Indica se o passo é sustentável
+	Reason   string        `json:"reason,omitempty"` // Motivo da reprovação (vazio quando o passo foi aprovado)
+	Stats                  // Métricas das requisições disparadas durante o patamar
+}
+
+// CapacityReport contém o resultado da busca de capacidade.
+type CapacityReport struct {
+	Sustainable  int            `json:"sustainable_rate"`  // Maior taxa que atendeu o SLO (0 quando nenhuma atendeu)
+	LimitReached bool           `json:"limit_reached"`     // MaxRate atingido sem violar o SLO: a capacidade pode ser maior
+	Steps        []CapacityStep `json:"steps"`             // Resultado de cada passo executado
+	Aborted      string         `json:"aborted,omitempty"` // Motivo da interrupção da busca (vazio se concluída)
+}
+
+// validate verifica se os parâmetros da busca são consistentes.
+func (c *CapacityConfig) validate() error {
+	if c.StartRate <= 0 {
+		return fmt.Errorf("taxa inicial deve ser maior que 0")
+	}
+	if c.RateStep <= 0 {
+		return fmt.Errorf("incremento da taxa deve ser maior que 0")
+	}
+	if c.MaxRate != 0 && c.MaxRate < c.StartRate {
+		return fmt.Errorf("taxa máxima não pode ser menor que a taxa inicial")
+	}
+	if c.StepDuration <= 0 {
+		return fmt.Errorf("duração do passo deve ser maior que 0")
+	}
+	if c.Warmup < 0 {
+		return fmt.Errorf("aquecimento não pode ser negativo")
+	}
+	if len(c.SLO) == 0 {
+		return fmt.Errorf("informe ao menos um critério de SLO (ex: p99<300ms)")
+	}
+	return nil
+}
+
+// stages monta o perfil de carga do passo: aquecimento até a taxa alvo e o patamar avaliado.
+func (c *CapacityConfig) stages(rate int) []Stage {
+	return []Stage{
+		{Name: "aquecimento", Duration: orDefault(c.Warmup, DefaultCapacityWarmup), Target: rate},
+		{Name: "patamar", Duration: c.StepDuration, Target: rate},
+	}
+}
+
+// FindCapacity procura a maior taxa de chegada que o alvo da configuração sustenta dentro do SLO.
+// A configuração define o alvo (requisição, cenário, fluxo, gRPC ou streaming), a concorrência
+// e o cliente; a quantidade de requests, os estágios e os thresholds são definidos pela busca.
+// A concorrência precisa ser suficiente para a maior taxa: passos em que os workers não alcançam
+// a taxa alvo são reprovados.
+//
+// A busca para no primeiro passo reprovado. Quando ctx é cancelado, o passo em andamento é
+// descartado e o relatório é retornado com os passos concluídos (CapacityReport.Aborted).
+func FindCapacity(ctx context.Context, config Config, capacity CapacityConfig) (CapacityReport, error) {
+	if err := capacity.validate(); err != nil {
+		return CapacityReport{}, err
+	}
+	if config.Requests > 0 || len(config.Stages) > 0 || len(config.Thresholds) > 0 {
+		return CapacityReport{}, fmt.Errorf("a busca de capacidade define a carga e o SLO: não informe requests, estágios ou thresholds")
+	}
+
+	// Valida o alvo com o perfil do primeiro passo
+	config.Stages = capacity.stages(capacity.StartRate)
+	if err := config.Validate(); err != nil {
+		return CapacityReport{}, err
+	}
+
+	var result CapacityReport
+	for rate := capacity.StartRate; capacity.MaxRate == 0 || rate <= capacity.MaxRate; rate += capacity.RateStep {
+		config.Stages = capacity.stages(rate)
+		report := Run(ctx, config)
+
+		// Busca cancelada: o passo incompleto não é avaliado. O cancelamento pode ocorrer logo após o
+		// fim do passo, quando o relatório ainda não registra a interrupção
+		if ctx.Err() != nil {
+			result.Aborted = cmp.Or(report.Aborted, "teste cancelado: "+context.Cause(ctx).Error())
+			return result, nil
+		}
+
+		step := evaluateCapacityStep(rate, report, capacity.SLO)
+		result.Steps = append(result.Steps, step)
+		if capacity.OnStep != nil {
+			capacity.OnStep(step)
+		}
+		if !step.Passed {
+			return result, nil
+		}
+		result.Sustainable = rate
+	}
+
+	// Todos os passos até a taxa máxima foram aprovados
+	result.LimitReached = true
+	return result, nil
+}
+
+// evaluateCapacityStep avalia o SLO contra as métricas do patamar do passo.
+func evaluateCapacityStep(rate int, report Report, slo []Threshold) CapacityStep {
+	// Um passo que não chegou a executar (ex: configuração inválida) não tem patamar a avaliar
+	if len(report.Stages) == 0 {
+		reason := "passo não executado"
+		if report.Aborted != "" {
+			reason = "passo interrompido: " + report.Aborted
+		}
+		return CapacityStep{Rate: rate, Stats: report.Stats, Reason: reason}
+	}
+	hold := report.Stages[len(report.Stages)-1]
+	step := CapacityStep{Rate: rate, Stats: hold.Stats}
+
+	// Quando os workers não dão conta da taxa, os requests atrasados são disparados depois do fim
+	// previsto do patamar: a taxa alcançada considera o tempo efetivamente gasto
+	elapsed := max(hold.Duration, report.TotalTime-hold.Start)
+	step.Achieved = float64(hold.TotalRequests) / elapsed.Seconds()
+
+	// Os thresholds são avaliados como se o patamar fosse um teste completo
+	step.Checks = EvaluateThresholds(slo, Report{Stats: hold.Stats, TotalTime: elapsed})
+
+	var reasons []string
+	if report.Aborted != "" {
+		reasons = append(reasons, "passo interrompido: "+report.Aborted)
+	}
+	if step.Achieved < float64(rate)*capacityRateTolerance {
+		reasons = append(reasons, fmt.Sprintf("taxa alcançada (%.1f req/s) abaixo do alvo: aumente a concorrência", step.Achieved))
+	}
+	for _, check := range step.Checks {
+		if !check.Passed {
+			reasons = append(reasons, fmt.Sprintf("%s (observado %s)", check.Threshold.Expression,
+				formatMetric(check.Threshold.Metric, check.Actual)))
+		}
+	}
+
+	step.Passed = len(reasons) == 0
+	step.Reason = strings.Join(reasons, "; ")
+	return step
+}
+
+// PrintCapacity exibe o resultado da busca de capacidade no terminal (os.Stdout). Ver WriteCapacity.
+func PrintCapacity(report CapacityReport) {
+	WriteCapacity(os.Stdout, report)
+}
+
+// WriteCapacity escreve uma tabela com as métricas de cada passo e a maior taxa sustentável.
+func WriteCapacity(out io.Writer, report CapacityReport) error {
+	w := &errWriter{w: out}
+	fmt.Fprintln(w, "\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(w, "🔎 BUSCA DE CAPACIDADE")
+	fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	fmt.Fprintf(w, "   %-8s %10s %12s %10s %10s %10s %8s\n", "status", "alvo", "alcançado", "p50", "p95", "p99", "erros")
+	for _, step := range report.Steps {
+		status := "✅ ok"
+		if !step.Passed {
+			status = "❌ falha"
+		}
+		fmt.Fprintf(w, "   %-8s %10s %12s %10v %10v %10v %7.2f%%\n", status,
+			fmt.Sprintf("%d/s", step.Rate), fmt.Sprintf("%.1f/s", step.Achieved),
+			round(step.Latency.Percentile(50)), round(step.Latency.Percentile(95)), round(step.Latency.Percentile(99)),
+			ratio(step.ErrorCount, step.TotalRequests)*100)
+		if step.Reason != "" {
+			fmt.Fprintf(w, "            └─ %s\n", step.Reason)
+		}
+	}
+
+	if report.Aborted != "" {
+		fmt.Fprintf(w, "\n⏹️  Busca interrompida: %s\n", report.Aborted)
+	}
+	if report.Sustainable > 0 {
+		fmt.Fprintf(w, "\n🏆 Maior taxa sustentável: %d req/s\n", report.Sustainable)
+	} else {
+		fmt.Fprintln(w, "\n⚠️  Nenhuma taxa testada atendeu o SLO")
+	}
+	if report.LimitReached {
+		fmt.Fprintln(w, "   A taxa máxima foi atingida sem violar o SLO: a capacidade pode ser maior")
+	}
+	fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	return w.err
+}
+
+// WriteJSONCapacity escreve o resultado da busca como uma linha JSON ("type":"capacity").
+func WriteJSONCapacity(w io.Writer, report CapacityReport) error {
+	return json.NewEncoder(w).Encode(struct {
+		Type string `json:"type"`
+		CapacityReport
+	}{Type: "capacity", CapacityReport: report})
+}
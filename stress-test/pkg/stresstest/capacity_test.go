@@ -0,0 +1,172 @@
+package stresstest
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// servidorComCapacidade responde com erro 503 quando há mais requests em andamento que o limite,
+// simulando um serviço que satura a partir de uma certa taxa
+func servidorComCapacidade(t *testing.T, limite int64, atraso time.Duration) *httptest.Server {
+	t.Helper()
+	var emAndamento atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer emAndamento.Add(-1)
+		if emAndamento.Add(1) > limite {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		time.Sleep(atraso)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// sloDeTeste interpreta os critérios informados
+func sloDeTeste(t *testing.T, expressoes ...string) []Threshold {
+	t.Helper()
+	var slo []Threshold
+	for _, expressao := range expressoes {
+		threshold, err := ParseThreshold(expressao)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		slo = append(slo, threshold)
+	}
+	return slo
+}
+
+func TestFindCapacity_ParaNoPrimeiroPassoReprovado(t *testing.T) {
+	// Com 20ms por request e no máximo 2 em andamento, o serviço sustenta cerca de 100 req/s
+	server := servidorComCapacidade(t, 2, 20*time.Millisecond)
+
+	var passos []int
+	capacity := CapacityConfig{
+		StartRate:    40,
+		RateStep:     80,
+		MaxRate:      1000,
+		StepDuration: 300 * time.Millisecond,
+		Warmup:       100 * time.Millisecond,
+		SLO:          sloDeTeste(t, "status_2xx>99%"),
+		OnStep:       func(step CapacityStep) { passos = append(passos, step.Rate) },
+	}
+	config := Config{RequestSpec: RequestSpec{URL: server.URL}, Concurrency: 50}
+
+	report, err := FindCapacity(context.Background(), config, capacity)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if report.Sustainable != 40 || report.LimitReached || len(report.Steps) != 2 || len(passos) != 2 {
+		t.Fatalf("Esperado 40 req/s sustentáveis e reprovação em 120 req/s, obtido %+v (passos %v)", report, passos)
+	}
+	reprovado := report.Steps[1]
+	if reprovado.Passed || !strings.Contains(reprovado.Reason, "status_2xx>99%") || reprovado.StatusCodes[http.StatusServiceUnavailable] == 0 {
+		t.Errorf("Esperado passo reprovado pelo SLO, obtido %+v", reprovado)
+	}
+
+	var saida bytes.Buffer
+	if err := WriteCapacity(&saida, report); err != nil || !strings.Contains(saida.String(), "Maior taxa sustentável: 40 req/s") {
+		t.Errorf("Resultado escrito incorretamente (%v):\n%s", err, saida.String())
+	}
+}
+
+func TestFindCapacity_TaxaMaximaAtingida(t *testing.T) {
+	server := novoServidorVazio(t)
+	capacity := CapacityConfig{
+		StartRate:    50,
+		RateStep:     50,
+		MaxRate:      100,
+		StepDuration: 200 * time.Millisecond,
+		Warmup:       50 * time.Millisecond,
+		SLO:          sloDeTeste(t, "error_rate<1%"),
+	}
+
+	report, err := FindCapacity(context.Background(), Config{RequestSpec: RequestSpec{URL: server.URL}, Concurrency: 4}, capacity)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if report.Sustainable != 100 || !report.LimitReached || len(report.Steps) != 2 {
+		t.Errorf("Esperado limite de 100 req/s atingido, obtido %+v", report)
+	}
+}
+
+func TestFindCapacity_ConcorrenciaInsuficiente(t *testing.T) {
+	// Um único worker com 20ms por request não passa de 50 req/s
+	server := servidorComCapacidade(t, 100, 20*time.Millisecond)
+	capacity := CapacityConfig{
+		StartRate:    200,
+		RateStep:     100,
+		StepDuration: 300 * time.Millisecond,
+		Warmup:       50 * time.Millisecond,
+		SLO:          sloDeTeste(t, "error_rate<1%"),
+	}
+
+	report, err := FindCapacity(context.Background(), Config{RequestSpec: RequestSpec{URL: server.URL}, Concurrency: 1}, capacity)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if report.Sustainable != 0 || len(report.Steps) != 1 || !strings.Contains(report.Steps[0].Reason, "aumente a concorrência") {
+		t.Errorf("Esperado passo reprovado pela taxa alcançada, obtido %+v", report.Steps)
+	}
+}
+
+func TestFindCapacity_Cancelada(t *testing.T) {
+	server := novoServidorVazio(t)
+	// O primeiro passo (250ms) termina antes do cancelamento e o segundo é interrompido. A taxa
+	// garante dezenas de requests no patamar, para que atrasos de poucos milissegundos não
+	// reprovem o passo pela taxa alcançada
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+
+	capacity := CapacityConfig{
+		StartRate:    100,
+		RateStep:     100,
+		StepDuration: 200 * time.Millisecond,
+		Warmup:       50 * time.Millisecond,
+		SLO:          sloDeTeste(t, "error_rate<1%"),
+	}
+	report, err := FindCapacity(ctx, Config{RequestSpec: RequestSpec{URL: server.URL}, Concurrency: 2, GracePeriod: time.Millisecond}, capacity)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if report.Aborted == "" || len(report.Steps) == 0 || report.Sustainable != report.Steps[len(report.Steps)-1].Rate {
+		t.Errorf("Esperado busca interrompida com os passos concluídos, obtido %+v", report)
+	}
+}
+
+func TestEvaluateCapacityStep_SemEstagios(t *testing.T) {
+	// Um passo que nem chegou a disparar (ex: feed ausente) é reprovado com o motivo da interrupção
+	report := Report{Stats: newStats(), Aborted: "configuração inválida: feed ceps: arquivo inexistente"}
+	step := evaluateCapacityStep(100, report, sloDeTeste(t, "p99<1s"))
+	if step.Passed || step.Rate != 100 || !strings.Contains(step.Reason, "feed ceps") {
+		t.Errorf("Esperado passo reprovado com o motivo da interrupção, obtido %+v", step)
+	}
+}
+
+func TestFindCapacity_Validacao(t *testing.T) {
+	valida := CapacityConfig{StartRate: 10, RateStep: 10, StepDuration: time.Second, SLO: sloDeTeste(t, "p99<1s")}
+	config := Config{RequestSpec: RequestSpec{URL: "http://localhost"}, Concurrency: 1}
+
+	casos := map[string]func(*CapacityConfig, *Config){
+		"sem SLO":                  func(c *CapacityConfig, _ *Config) { c.SLO = nil },
+		"taxa inicial zero":        func(c *CapacityConfig, _ *Config) { c.StartRate = 0 },
+		"incremento zero":          func(c *CapacityConfig, _ *Config) { c.RateStep = 0 },
+		"máximo menor que inicial": func(c *CapacityConfig, _ *Config) { c.MaxRate = 5 },
+		"sem duração":              func(c *CapacityConfig, _ *Config) { c.StepDuration = 0 },
+		"com requests":             func(_ *CapacityConfig, c *Config) { c.Requests = 10 },
+		"sem URL":                  func(_ *CapacityConfig, c *Config) { c.URL = "" },
+	}
+	for nome, alterar := range casos {
+		capacity, cfg := valida, config
+		alterar(&capacity, &cfg)
+		if _, err := FindCapacity(context.Background(), cfg, capacity); err == nil {
+			t.Errorf("%s: esperado erro", nome)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package stresstest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// Tolerance define quanto cada grupo de métricas pode piorar em relação ao baseline sem que
+// a diferença seja considerada uma regressão.
+type Tolerance struct {
+	Latency    float64 // Aumento relativo aceito na latência (0.1 = 10%)
+	Throughput float64 // Queda relativa aceita em requests por segundo (0.1 = 10%)
+	ErrorRate  float64 // Aumento absoluto aceito nas taxas de erro (0.01 = 1 ponto percentual)
+}
+
+// DefaultTolerance é a tolerância usada quando nenhuma outra é informada.
+var DefaultTolerance = Tolerance{Latency: 0.10, Throughput: 0.10, ErrorRate: 0.01}
+
+// Comparison é a comparação de uma métrica entre o baseline e a execução atual.
+type Comparison struct {
+	Metric     string  `json:"metric"`     // Nome da métrica (mesmos nomes dos thresholds: p95, rps, error_rate...)
+	Baseline   float64 `json:"baseline"`   // Valor no baseline
+	Current    float64 `json:"current"`    // Valor na execução atual
+	Change     float64 `json:"change"`     // Variação: relativa para latência e rps, absoluta para taxas
+	Regression bool    `json:"regression"` // Indica se a piora excedeu a tolerância
+}
+
+// comparedMetrics são as métricas comparadas, na ordem de exibição.
+var comparedMetrics = []string{"p50", "p90", "p95", "p99", "avg", "max", "rps", "error_rate", "assertion_fail_rate"}
+
+// LoadReport lê um relatório salvo com --save ou a saída NDJSON de --output json,
+// usando a última linha do tipo "report".
+func LoadReport(path string) (Report, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Report{}, fmt.Errorf("erro ao ler relatório: %w", err)
+	}
+	defer file.Close()
+
+	report, err := readReport(file)
+	if err != nil {
+		return Report{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return report, nil
+}
+
+// readReport procura o relatório entre as linhas JSON lidas.
+func readReport(r io.Reader) (Report, error) {
+	var found *jsonReport
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// Identifica o tipo da linha antes de decodificá-la (snapshots e checks são ignorados)
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(line), &header); err != nil {
+			return Report{}, fmt.Errorf("linha JSON inválida: %w", err)
+		}
+		if header.Type != "report" {
+			continue
+		}
+
+		var entry jsonReport
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return Report{}, fmt.Errorf("relatório inválido: %w", err)
+		}
+		found = &entry
+	}
+	if err := scanner.Err(); err != nil {
+		return Report{}, err
+	}
+
+	if found == nil {
+		return Report{}, fmt.Errorf("nenhum relatório encontrado")
+	}
+	return found.Report, nil
+}
+
+// CompareReports compara as métricas da execução atual com as do baseline.
+// Latências maiores, rps menor e taxas de erro maiores além da tolerância são regressões.
+func CompareReports(baseline, current Report, tolerance Tolerance) []Comparison {
+	comparisons := make([]Comparison, 0, len(comparedMetrics))
+	for _, metric := range comparedMetrics {
+		c := Comparison{
+			Metric:   metric,
+			Baseline: metricValue(metric, baseline),
+			Current:  metricValue(metric, current),
+		}
+
+		switch metricKind(metric) {
+		case metricRatio:
+			// Taxas são comparadas em pontos percentuais
+			c.Change = c.Current - c.Baseline
+			c.Regression = c.Change > tolerance.ErrorRate
+		case metricDuration:
+			c.Change = relativeChange(c.Baseline, c.Current)
+			c.Regression = c.Baseline > 0 && c.Change > tolerance.Latency
+		default:
+			c.Change = relativeChange(c.Baseline, c.Current)
+			c.Regression = c.Baseline > 0 && -c.Change > tolerance.Throughput
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons
+}
+
+// relativeChange calcula a variação relativa entre dois valores (0.1 = aumento de 10%).
+func relativeChange(baseline, current float64) float64 {
+	if baseline == 0 {
+		if current == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return (current - baseline) / baseline
+}
+
+// Regressions retorna quantas métricas regrediram.
+func Regressions(comparisons []Comparison) int {
+	count := 0
+	for _, c := range comparisons {
+		if c.Regression {
+			count++
+		}
+	}
+	return count
+}
+
+// PrintComparison exibe a comparação no terminal (os.Stdout). Ver WriteComparison.
+func PrintComparison(comparisons []Comparison) {
+	WriteComparison(os.Stdout, comparisons)
+}
+
+// WriteComparison escreve a comparação lado a lado entre o baseline e a execução atual.
+func WriteComparison(out io.Writer, comparisons []Comparison) error {
+	w := &errWriter{w: out}
+	fmt.Fprintln(w, "\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(w, "⚖️  COMPARAÇÃO COM O BASELINE")
+	fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(w, "   %-20s %12s %12s %10s  %s\n", "métrica", "baseline", "atual", "variação", "status")
+
+	for _, c := range comparisons {
+		status := "✅ ok"
+		if c.Regression {
+			status = "❌ regressão"
+		}
+		fmt.Fprintf(w, "   %-20s %12s %12s %10s  %s\n", c.Metric,
+			formatMetric(c.Metric, c.Baseline), formatMetric(c.Metric, c.Current),
+			formatChange(c), status)
+	}
+
+	regressions := Regressions(comparisons)
+	fmt.Fprintf(w, "\n   %d de %d métricas dentro da tolerância\n", len(comparisons)-regressions, len(comparisons))
+	fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	return w.err
+}
+
+// formatChange formata a variação: pontos percentuais para taxas e porcentagem para as demais.
+func formatChange(c Comparison) string {
+	if metricKind(c.Metric) == metricRatio {
+		return fmt.Sprintf("%+.2fpp", c.Change*100)
+	}
+	if math.IsInf(c.Change, 1) {
+		return "novo"
+	}
+	return fmt.Sprintf("%+.1f%%", c.Change*100)
+}
@@ -0,0 +1,100 @@
+package stresstest
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// novoRelatorio cria um relatório com latência constante, rps e erros informados
+func novoRelatorio(latencia time.Duration, rps float64, erros int) Report {
+	report := Report{Stats: newStats(), TotalTime: time.Duration(100 / rps * float64(time.Second))}
+	report.TotalRequests = 100
+	report.ErrorCount = erros
+	report.StatusCodes[200] = 100 - erros
+	for i := 0; i < 100-erros; i++ {
+		report.Latency.Record(latencia)
+	}
+	return report
+}
+
+// comparacao retorna a comparação da métrica informada
+func comparacao(t *testing.T, comparisons []Comparison, metric string) Comparison {
+	t.Helper()
+	for _, c := range comparisons {
+		if c.Metric == metric {
+			return c
+		}
+	}
+	t.Fatalf("Métrica %s não comparada", metric)
+	return Comparison{}
+}
+
+func TestCompareReports(t *testing.T) {
+	baseline := novoRelatorio(100*time.Millisecond, 50, 0)
+
+	// Dentro da tolerância: latência 5% maior e rps 5% menor
+	comparisons := CompareReports(baseline, novoRelatorio(105*time.Millisecond, 47.5, 0), DefaultTolerance)
+	if n := Regressions(comparisons); n != 0 {
+		t.Errorf("Esperado nenhuma regressão, obtido %d: %+v", n, comparisons)
+	}
+
+	// Fora da tolerância: latência 50% maior, rps pela metade e 3% de erros
+	comparisons = CompareReports(baseline, novoRelatorio(150*time.Millisecond, 25, 3), DefaultTolerance)
+	for _, metric := range []string{"p50", "p99", "avg", "rps", "error_rate"} {
+		if !comparacao(t, comparisons, metric).Regression {
+			t.Errorf("Esperado regressão em %s", metric)
+		}
+	}
+	if c := comparacao(t, comparisons, "error_rate"); c.Change < 0.029 || c.Change > 0.031 {
+		t.Errorf("Esperado variação de 3pp na taxa de erro, obtido %v", c.Change)
+	}
+
+	// Melhorias nunca são regressões
+	comparisons = CompareReports(baseline, novoRelatorio(50*time.Millisecond, 100, 0), DefaultTolerance)
+	if n := Regressions(comparisons); n != 0 {
+		t.Errorf("Esperado nenhuma regressão com melhorias, obtido %d", n)
+	}
+
+	// Tolerância maior aceita a piora
+	tolerante := Tolerance{Latency: 1, Throughput: 1, ErrorRate: 0.05}
+	comparisons = CompareReports(baseline, novoRelatorio(150*time.Millisecond, 25, 3), tolerante)
+	if n := Regressions(comparisons); n != 0 {
+		t.Errorf("Esperado nenhuma regressão com tolerância maior, obtido %d", n)
+	}
+}
+
+func TestLoadReport(t *testing.T) {
+	original := novoRelatorio(80*time.Millisecond, 40, 2)
+
+	// Arquivo salvo com --save: uma única linha do tipo report
+	var salvo bytes.Buffer
+	WriteJSONReport(&salvo, original)
+	caminho := escreverArquivo(t, t.TempDir(), "baseline.json", salvo.String())
+
+	report, err := LoadReport(caminho)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if report.TotalRequests != 100 || report.ErrorCount != 2 || report.Latency.Percentile(99) != original.Latency.Percentile(99) {
+		t.Errorf("Relatório carregado difere do original: %+v", report.Stats)
+	}
+
+	// Saída NDJSON de --output json: snapshots seguidos do relatório e dos checks
+	ndjson := `{"type":"snapshot","completed":10,"errors":0}` + "\n" + salvo.String() + `{"type":"checks","checks":[]}` + "\n"
+	caminho = escreverArquivo(t, t.TempDir(), "saida.ndjson", ndjson)
+	if report, err = LoadReport(caminho); err != nil || report.TotalRequests != 100 {
+		t.Errorf("Esperado relatório lido da saída NDJSON, obtido %+v (%v)", report.Stats, err)
+	}
+
+	// Arquivo sem relatório
+	caminho = escreverArquivo(t, t.TempDir(), "vazio.json", `{"type":"snapshot"}`)
+	if _, err := LoadReport(caminho); err == nil || !strings.Contains(err.Error(), "nenhum relatório") {
+		t.Errorf("Esperado erro de relatório ausente, obtido %v", err)
+	}
+	if _, err := LoadReport(filepath.Join(t.TempDir(), "inexistente.json")); err == nil {
+		t.Error("Esperado erro para arquivo inexistente")
+	}
+}
@@ -1,40 +1,199 @@
 // Package stresstest contém as funcionalidades principais para execução de testes de carga.
 package stresstest
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Config representa a configuração do teste de carga a ser executado.
 // Contém todos os parâmetros necessários para definir como o teste será realizado.
+// Concurrency define o tamanho do pool fixo de workers que executam os requests.
 type Config struct {
-	URL         string // URL do serviço web que será testado
-	Requests    int    // Número total de requests HTTP que serão enviados
-	Concurrency int    // Número máximo de requests simultâneos (concorrência)
+	RequestSpec                 // Requisição HTTP disparada (URL, método, cabeçalhos, corpo e autenticação)
+	Requests    int             // Número total de requests HTTP que serão enviados
+	Concurrency int             // Número máximo de requests simultâneos (concorrência)
+	Stages      []Stage         // Perfil de carga por estágios; quando definido substitui Requests
+	Scenario    *Scenario       // Cenário com várias requisições ponderadas; quando definido substitui RequestSpec
+	GRPC        *GRPCConfig     // Chamada gRPC; quando definida substitui RequestSpec (modo gRPC)
+	Stream      *StreamConfig   // Sessões WebSocket/SSE; quando definidas substituem RequestSpec (modo streaming)
+	Flow        *Flow           // Fluxo de usuário virtual com vários passos; cada iteração conta como um request
+	Replay      *Replay         // Log de acesso reproduzido contra a URL base; define os requests e os instantes de disparo
+	Transport   TransportConfig // Ajustes do cliente HTTP (timeouts, keep-alive, HTTP/2 e TLS)
+	TagBy       string          // Agrupamento automático das requisições sem tag: TagByName, TagByURL ou vazio (apenas tags informadas)
+
+	// Acompanhamento em tempo real (opcional, não enviado aos agentes no modo distribuído)
+	Progress         func(Snapshot) `json:"-"` // Chamada periodicamente com as métricas parciais e ao final do teste
+	ProgressInterval time.Duration  // Intervalo entre snapshots (padrão DefaultProgressInterval)
+	Sinks            []Sink         `json:"-"` // Destinos que recebem as métricas de cada snapshot (ex: Prometheus, InfluxDB)
+
+	// Critérios de aprovação (opcional)
+	Thresholds  []Threshold // Critérios avaliados contra o relatório final
+	AbortOnFail bool        // Interrompe o teste assim que um threshold for irremediavelmente violado
+
+	// Interrupção (opcional)
+	MaxErrors   int           // Interrompe o teste ao atingir este número de erros de transporte (0 = sem limite)
+	GracePeriod time.Duration // Tempo para os requests em andamento terminarem após a interrupção (padrão DefaultGracePeriod)
+
+	// Limite de taxa (opcional)
+	RespectRetryAfter bool          // Após uma resposta 429 o worker aguarda o Retry-After antes do próximo request
+	MaxRetryAfter     time.Duration // Maior espera após uma resposta 429 (padrão DefaultMaxRetryAfter)
 }
 
 // Validate verifica se a configuração fornecida é válida para execução do teste.
 // Retorna erro se algum parâmetro estiver incorreto ou inconsistente.
 func (c *Config) Validate() error {
-	// Verifica se a URL foi fornecida
-	if c.URL == "" {
-		return fmt.Errorf("URL é obrigatória")
-	}
-	
-	// Verifica se o número de requests é positivo
-	if c.Requests <= 0 {
-		return fmt.Errorf("número de requests deve ser maior que 0")
+	// Verifica se a requisição (URL, autenticação, etc.), o cenário, o fluxo, a chamada gRPC ou as sessões de streaming são válidos
+	if c.Replay != nil {
+		if c.Scenario != nil || c.Flow != nil || c.GRPC != nil || c.Stream != nil {
+			return fmt.Errorf("o replay não pode ser combinado com cenário, fluxo, gRPC ou streaming")
+		}
+		if err := c.Replay.validate(); err != nil {
+			return fmt.Errorf("replay inválido: %w", err)
+		}
+		if err := c.RequestSpec.validate(); err != nil {
+			return err
+		}
+	} else if c.Flow != nil {
+		if c.URL != "" || c.Scenario != nil || c.GRPC != nil || c.Stream != nil {
+			return fmt.Errorf("o fluxo não pode ser combinado com URL, cenário, gRPC ou streaming")
+		}
+		if err := c.Flow.validate(); err != nil {
+			return fmt.Errorf("fluxo inválido: %w", err)
+		}
+	} else if c.Stream != nil {
+		if c.URL != "" || c.Scenario != nil || c.GRPC != nil {
+			return fmt.Errorf("o modo streaming não pode ser combinado com URL, cenário ou gRPC")
+		}
+		if err := c.Stream.validate(); err != nil {
+			return err
+		}
+	} else if c.GRPC != nil {
+		if c.URL != "" || c.Scenario != nil {
+			return fmt.Errorf("o modo gRPC não pode ser combinado com URL ou cenário")
+		}
+		if err := c.GRPC.validate(c.Transport); err != nil {
+			return fmt.Errorf("gRPC: %w", err)
+		}
+	} else if c.Scenario != nil {
+		if c.URL != "" {
+			return fmt.Errorf("informe uma URL ou um cenário, não ambos")
+		}
+		if err := c.Scenario.validate(); err != nil {
+			return fmt.Errorf("cenário inválido: %w", err)
+		}
+	} else if err := c.RequestSpec.validate(); err != nil {
+		return err
 	}
-	
+
+	return c.validateLoad()
+}
+
+// validateLoad verifica os parâmetros de carga, comuns a todos os protocolos: concorrência,
+// acompanhamento, interrupção, transporte e quantidade de requests ou estágios.
+func (c *Config) validateLoad() error {
 	// Verifica se o nível de concorrência é positivo
 	if c.Concurrency <= 0 {
 		return fmt.Errorf("concorrência deve ser maior que 0")
 	}
-	
+
+	// Verifica o intervalo de acompanhamento
+	if c.ProgressInterval < 0 {
+		return fmt.Errorf("intervalo de progresso não pode ser negativo")
+	}
+
+	// Verifica os ajustes de interrupção
+	if c.MaxErrors < 0 {
+		return fmt.Errorf("limite de erros não pode ser negativo")
+	}
+	if c.GracePeriod < 0 {
+		return fmt.Errorf("prazo de tolerância não pode ser negativo")
+	}
+	if c.MaxRetryAfter < 0 {
+		return fmt.Errorf("espera máxima após respostas 429 não pode ser negativa")
+	}
+
+	// Verifica os ajustes do cliente HTTP
+	if err := c.Transport.validate(); err != nil {
+		return err
+	}
+
+	// O agrupamento em tags vale para requisições HTTP; o replay já agrupa por caminho
+	if err := validateTagBy(c.TagBy); err != nil {
+		return err
+	}
+	if c.TagBy != "" && (c.Replay != nil || c.GRPC != nil || c.Stream != nil) {
+		return fmt.Errorf("o agrupamento de tags não se aplica ao replay, ao gRPC ou ao streaming")
+	}
+
+	// No replay os requests e os instantes de disparo vêm do log
+	if c.Replay != nil {
+		if c.Requests > 0 || len(c.Stages) > 0 {
+			return fmt.Errorf("o replay define os requests: não informe número de requests ou perfil de carga")
+		}
+		return nil
+	}
+
+	// Com perfil de carga a quantidade de requests é definida pelos estágios
+	if len(c.Stages) > 0 {
+		return c.validateStages()
+	}
+
+	// Verifica se o número de requests é positivo
+	if c.Requests <= 0 {
+		return fmt.Errorf("número de requests deve ser maior que 0")
+	}
+
 	// Verifica se a concorrência não excede o total de requests
 	// (não faz sentido ter mais workers que requests)
 	if c.Concurrency > c.Requests {
 		return fmt.Errorf("concorrência não pode ser maior que o número total de requests")
 	}
-	
+
 	// Configuração válida
 	return nil
-}
\ No newline at end of file
+}
+
+// validateStages verifica a consistência do perfil de carga.
+func (c *Config) validateStages() error {
+	// Perfil e quantidade fixa de requests são modos exclusivos
+	if c.Requests > 0 {
+		return fmt.Errorf("informe o número de requests ou um perfil de carga, não ambos")
+	}
+
+	for i, stage := range c.Stages {
+		if stage.Duration <= 0 {
+			return fmt.Errorf("%s: duração deve ser maior que 0", stageLabel(stage, i))
+		}
+		if stage.Target < 0 {
+			return fmt.Errorf("%s: alvo não pode ser negativo", stageLabel(stage, i))
+		}
+	}
+	return nil
+}
+
+// nextRequest retorna a especificação da próxima requisição a ser disparada e a sua tag:
+// a requisição fixa da configuração ou uma requisição sorteada do cenário.
+func (c *Config) nextRequest() (string, RequestSpec, error) {
+	if c.Scenario == nil {
+		return requestTag(c.TagBy, "", c.RequestSpec), c.RequestSpec, nil
+	}
+	name, spec, err := c.Scenario.next()
+	return requestTag(c.TagBy, name, spec), spec, err
+}
+
+// plannedRequests retorna quantos requests o teste deve disparar no total.
+// Em um fluxo cada iteração dispara até um request por passo.
+func (c *Config) plannedRequests() int {
+	planned := c.Requests
+	if c.Replay != nil {
+		planned = len(c.Replay.Entries)
+	}
+	if len(c.Stages) > 0 {
+		planned = int(expectedRequests(c.Stages, profileDuration(c.Stages)))
+	}
+	if c.Flow != nil {
+		planned *= len(c.Flow.Steps)
+	}
+	return planned
+}
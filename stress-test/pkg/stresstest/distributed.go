@@ -0,0 +1,468 @@
+package stresstest
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DistributedStartDelay é a antecedência com que o coordenador agenda o início do teste nos
+// agentes, suficiente para que todos recebam a configuração antes do horário combinado.
+const DistributedStartDelay = time.Second
+
+// agentHealthTimeout limita a verificação de disponibilidade de cada agente.
+const agentHealthTimeout = 5 * time.Second
+
+// AgentJob é o trabalho enviado pelo coordenador a um agente: a fração da configuração que
+// cabe ao agente e o instante em que todos os agentes devem começar a disparar.
+// Os relógios das máquinas precisam estar sincronizados (NTP) para o início ser simultâneo.
+//
+// Agent e Agents intercalam as sequências entre os agentes: o agente i (a partir de 0) de n
+// produz os números de sequência i+1, i+1+n, i+1+2n... e consome as linhas dos feeds na mesma
+// ordem, de forma que {{.Seq}} continua único em toda a execução.
+type AgentJob struct {
+	Config  Config    `json:"config"`   // Configuração do teste para este agente
+	StartAt time.Time `json:"start_at"` // Instante combinado para o início do teste
+	Agent   int       `json:"agent"`    // Posição do agente na lista do coordenador
+	Agents  int       `json:"agents"`   // Quantidade de agentes do teste
+}
+
+// agent executa os trabalhos recebidos do coordenador, um de cada vez.
+type agent struct {
+	token string      // Token exigido do coordenador (vazio aceita qualquer chamada)
+	busy  atomic.Bool // Indica se há um teste em andamento
+}
+
+// NewAgent cria o handler HTTP de um agente de teste distribuído. Quando token não é vazio,
+// as rotas exigem o cabeçalho "Authorization: Bearer <token>", enviado pelo coordenador que
+// recebe o mesmo token em RunDistributed.
+//
+// Rotas:
+//   - GET /health: indica que o agente está disponível
+//   - POST /run: recebe um AgentJob, aguarda o instante combinado, executa o teste e
+//     responde com o relatório parcial em JSON
+//
+// O agente dispara carga contra o alvo escolhido por quem o chama: sem token ele deve escutar
+// apenas em endereços acessíveis aos coordenadores confiáveis.
+func NewAgent(token string) http.Handler {
+	a := &agent{token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", a.authorize(a.health))
+	mux.HandleFunc("POST /run", a.authorize(a.run))
+	return mux
+}
+
+// authorize rejeita as chamadas sem o token do agente.
+func (a *agent) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.token != "" {
+			received := []byte(r.Header.Get("Authorization"))
+			if subtle.ConstantTimeCompare(received, []byte("Bearer "+a.token)) != 1 {
+				http.Error(w, "token do agente inválido", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// health responde se o agente está livre para receber um novo teste.
+func (a *agent) health(w http.ResponseWriter, r *http.Request) {
+	if a.busy.Load() {
+		http.Error(w, "agente ocupado com outro teste", http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// run executa a fração do teste recebida do coordenador e devolve o relatório parcial.
+func (a *agent) run(w http.ResponseWriter, r *http.Request) {
+	var job AgentJob
+	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+		http.Error(w, fmt.Sprintf("trabalho inválido: %v", err), http.StatusBadRequest)
+		return
+	}
+	// Os arquivos do agente nunca são lidos a pedido do coordenador: o conteúdo poderia ser
+	// enviado ao alvo do teste por meio dos templates
+	if err := checkLocalFiles(job.Config); err != nil {
+		http.Error(w, fmt.Sprintf("configuração inválida: %v", err), http.StatusBadRequest)
+		return
+	}
+	if job.Agent < 0 || job.Agent >= max(job.Agents, 1) {
+		http.Error(w, fmt.Sprintf("trabalho inválido: agente %d de %d", job.Agent, job.Agents), http.StatusBadRequest)
+		return
+	}
+	job.interleave()
+	if err := job.Config.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("configuração inválida: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Apenas um teste por vez, para que os agentes não disputem recursos da máquina
+	if !a.busy.CompareAndSwap(false, true) {
+		http.Error(w, "agente ocupado com outro teste", http.StatusConflict)
+		return
+	}
+	defer a.busy.Store(false)
+
+	// Aguarda o instante combinado para que todos os agentes comecem juntos. Se o coordenador
+	// desistir durante a espera, o teste nem chega a começar
+	start := time.NewTimer(time.Until(job.StartAt))
+	defer start.Stop()
+	select {
+	case <-start.C:
+	case <-r.Context().Done():
+		return
+	}
+
+	// O teste é interrompido se o coordenador desistir da requisição (ex: Ctrl-C no coordenador)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Run(r.Context(), job.Config))
+}
+
+// RunDistributed executa o teste de carga dividindo a configuração entre os agentes informados
+// (endereços host:porta ou URLs). Os agentes começam no mesmo instante e os relatórios parciais
+// são combinados em um único relatório, somando os histogramas sem perda de precisão.
+//
+// O acompanhamento em tempo real não é suportado no modo distribuído e os thresholds são
+// avaliados apenas contra o relatório combinado, ao final. Cancelar ctx interrompe os agentes,
+// e nesse caso os relatórios parciais são perdidos. O token (opcional) é enviado aos agentes
+// criados com NewAgent e o mesmo token.
+func RunDistributed(ctx context.Context, config Config, agents []string, token string) (Report, error) {
+	if len(agents) == 0 {
+		return Report{}, fmt.Errorf("nenhum agente informado")
+	}
+
+	// Verifica se todos os agentes estão disponíveis antes de distribuir o teste
+	client := &http.Client{}
+	for _, address := range agents {
+		if err := checkAgent(address, token); err != nil {
+			return Report{}, err
+		}
+	}
+
+	parts, err := splitConfig(config, len(agents))
+	if err != nil {
+		return Report{}, err
+	}
+
+	// Agenda o início e dispara todos os agentes em paralelo. Um relatório sem a parte de algum
+	// agente subestimaria a carga: a primeira falha interrompe os demais e o teste inteiro falha
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	startAt := time.Now().Add(DistributedStartDelay)
+	reports := make([]Report, len(agents))
+	errs := make([]error, len(agents))
+
+	var wg sync.WaitGroup
+	for i, address := range agents {
+		wg.Add(1)
+		go func(i int, address string) {
+			defer wg.Done()
+			reports[i], errs[i] = runAgent(ctx, client, address, token, AgentJob{Config: parts[i], StartAt: startAt, Agent: i, Agents: len(agents)})
+			if errs[i] != nil {
+				cancel(errs[i])
+			}
+		}(i, address)
+	}
+	wg.Wait()
+
+	// Relata a falha que interrompeu o teste, e não os cancelamentos que ela provocou
+	if errors.Join(errs...) != nil {
+		return Report{}, context.Cause(ctx)
+	}
+
+	return mergeReports(config, agents, reports), nil
+}
+
+// interleave intercala as sequências do cenário ou do fluxo com as dos demais agentes.
+// Deve ser chamado antes da preparação, que aplica a mesma ordem aos feeds.
+func (job *AgentJob) interleave() {
+	offset, stride := int64(job.Agent), int64(max(job.Agents, 1))
+	if job.Config.Scenario != nil {
+		job.Config.Scenario.seq.interleave(offset, stride)
+	}
+	if job.Config.Flow != nil {
+		job.Config.Flow.seq.interleave(offset, stride)
+	}
+}
+
+// agentURL monta a URL de uma rota do agente a partir do seu endereço.
+func agentURL(address, path string) string {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	return strings.TrimSuffix(address, "/") + path
+}
+
+// checkAgent verifica se o agente está acessível e livre.
+func checkAgent(address, token string) error {
+	req, err := http.NewRequest(http.MethodGet, agentURL(address, "/health"), nil)
+	if err != nil {
+		return fmt.Errorf("agente %s: %w", address, err)
+	}
+	setAgentToken(req, token)
+
+	client := &http.Client{Timeout: agentHealthTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("agente %s indisponível: %w", address, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("agente %s indisponível: %s", address, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// setAgentToken adiciona o token do agente à requisição, quando informado.
+func setAgentToken(req *http.Request, token string) {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// runAgent envia o trabalho ao agente e aguarda o relatório parcial.
+func runAgent(ctx context.Context, client *http.Client, address, token string, job AgentJob) (Report, error) {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return Report{}, fmt.Errorf("agente %s: erro ao serializar configuração: %w", address, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, agentURL(address, "/run"), bytes.NewReader(payload))
+	if err != nil {
+		return Report{}, fmt.Errorf("agente %s: %w", address, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setAgentToken(req, token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Report{}, fmt.Errorf("agente %s: %w", address, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(resp.Body)
+		return Report{}, fmt.Errorf("agente %s: %s", address, strings.TrimSpace(string(message)))
+	}
+
+	var report Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return Report{}, fmt.Errorf("agente %s: relatório inválido: %w", address, err)
+	}
+	return report, nil
+}
+
+// splitConfig divide a configuração em n partes equivalentes, uma por agente.
+// Requests, concorrência e alvos dos estágios são repartidos e o resto da divisão fica com os
+// primeiros agentes, assim como o limite de erros. No replay cada agente recebe uma parte das
+// entradas do log. Os feeds e os corpos lidos de arquivo seguem junto com a configuração.
+// Os thresholds não são enviados: são avaliados sobre o relatório combinado.
+func splitConfig(config Config, n int) ([]Config, error) {
+	config, err := embedFiles(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLocalFiles(config); err != nil {
+		return nil, err
+	}
+	if config.Replay != nil && len(config.Replay.Entries) < n {
+		return nil, fmt.Errorf("log de acesso com %d requisições, menos que o número de agentes (%d)", len(config.Replay.Entries), n)
+	}
+	if config.Replay == nil && len(config.Stages) == 0 && config.Requests < n {
+		return nil, fmt.Errorf("número de requests (%d) menor que o número de agentes (%d)", config.Requests, n)
+	}
+
+	parts := make([]Config, n)
+	for i := range parts {
+		part := config
+		part.Progress = nil
+		part.Sinks = nil
+		part.Thresholds = nil
+		part.AbortOnFail = false
+
+		part.Concurrency = max(1, share(config.Concurrency, n, i))
+		if config.Replay != nil {
+			// As entradas do log são distribuídas alternadamente, preservando os instantes de disparo
+			part.Replay = &Replay{Speed: config.Replay.Speed}
+			for j := i; j < len(config.Replay.Entries); j += n {
+				part.Replay.Entries = append(part.Replay.Entries, config.Replay.Entries[j])
+			}
+		} else if len(config.Stages) > 0 {
+			part.Stages = make([]Stage, len(config.Stages))
+			for j, stage := range config.Stages {
+				stage.Target = share(stage.Target, n, i)
+				part.Stages[j] = stage
+			}
+		} else {
+			part.Requests = share(config.Requests, n, i)
+			part.Concurrency = min(part.Concurrency, part.Requests)
+		}
+		if config.MaxErrors > 0 {
+			part.MaxErrors = max(1, share(config.MaxErrors, n, i))
+		}
+		parts[i] = part
+	}
+	return parts, nil
+}
+
+// embedFiles substitui os feeds e os body_file do cenário ou do fluxo pelo conteúdo dos arquivos,
+// lidos no coordenador. A configuração original não é alterada.
+func embedFiles(config Config) (Config, error) {
+	if scenario := config.Scenario; scenario != nil {
+		rows, err := readFeeds(scenario.Feeds, scenario.FeedRows)
+		if err != nil {
+			return Config{}, err
+		}
+		config.Scenario = &Scenario{FeedRows: rows, Requests: slices.Clone(scenario.Requests)}
+		for i := range config.Scenario.Requests {
+			request := &config.Scenario.Requests[i]
+			if request.Body, err = readBody(request.Body, request.BodyFile); err != nil {
+				return Config{}, fmt.Errorf("%s: %w", request.label(i), err)
+			}
+			request.BodyFile = ""
+		}
+	}
+	if flow := config.Flow; flow != nil {
+		rows, err := readFeeds(flow.Feeds, flow.FeedRows)
+		if err != nil {
+			return Config{}, err
+		}
+		config.Flow = &Flow{FeedRows: rows, Steps: slices.Clone(flow.Steps)}
+		for i := range config.Flow.Steps {
+			step := &config.Flow.Steps[i]
+			if step.Body, err = readBody(step.Body, step.BodyFile); err != nil {
+				return Config{}, fmt.Errorf("%s: %w", step.label(i), err)
+			}
+			step.BodyFile = ""
+		}
+	}
+	return config, nil
+}
+
+// readFeeds lê os arquivos CSV dos feeds e os combina com os feeds já carregados.
+func readFeeds(paths map[string]string, rows FeedRows) (FeedRows, error) {
+	if len(paths) == 0 {
+		return rows, nil
+	}
+	loaded := maps.Clone(rows)
+	if loaded == nil {
+		loaded = make(FeedRows, len(paths))
+	}
+	for name, path := range paths {
+		feed, err := loadFeed(path)
+		if err != nil {
+			return nil, fmt.Errorf("feed %s: %w", name, err)
+		}
+		loaded[name] = feed.rows
+	}
+	return loaded, nil
+}
+
+// checkLocalFiles rejeita configurações que dependem de arquivos locais (caminhos de feeds,
+// body_file, certificados e arquivos .proto). No coordenador, feeds e body_file já foram
+// incorporados à configuração (ver embedFiles) e os demais arquivos não são enviados: os agentes,
+// em outras máquinas, falhariam na validação ou leriam outro arquivo com o mesmo caminho.
+func checkLocalFiles(config Config) error {
+	var files []string
+	if config.Scenario != nil {
+		files = append(files, feedFiles(config.Scenario.Feeds)...)
+		for i, request := range config.Scenario.Requests {
+			if request.BodyFile != "" {
+				files = append(files, "body_file de "+request.label(i))
+			}
+		}
+	}
+	if config.Flow != nil {
+		files = append(files, feedFiles(config.Flow.Feeds)...)
+		for i, step := range config.Flow.Steps {
+			if step.BodyFile != "" {
+				files = append(files, "body_file de "+step.label(i))
+			}
+		}
+	}
+	if config.Transport.ClientCert != "" {
+		files = append(files, "certificado do cliente")
+	}
+	if config.Transport.CACert != "" {
+		files = append(files, "arquivo de CAs")
+	}
+	if config.GRPC != nil && len(config.GRPC.ProtoFiles) > 0 {
+		files = append(files, "arquivos .proto")
+	}
+
+	if len(files) > 0 {
+		return fmt.Errorf("o teste distribuído não envia arquivos aos agentes: remova %s", strings.Join(files, ", "))
+	}
+	return nil
+}
+
+// feedFiles descreve os feeds declarados, em ordem alfabética.
+func feedFiles(feeds map[string]string) []string {
+	var files []string
+	for _, name := range slices.Sorted(maps.Keys(feeds)) {
+		files = append(files, "feed "+name)
+	}
+	return files
+}
+
+// share retorna a parcela do i-ésimo de n participantes na divisão de total.
+func share(total, n, i int) int {
+	part := total / n
+	if i < total%n {
+		part++
+	}
+	return part
+}
+
+// mergeReports combina os relatórios parciais dos agentes em um único relatório.
+// O tempo total é o do agente mais demorado.
+func mergeReports(config Config, agents []string, reports []Report) Report {
+	merged := newReport(config)
+
+	var aborted []string
+	var tags tagMerger
+	for i, report := range reports {
+		merged.Stats.merge(report.Stats)
+		merged.Timeline = mergeTimeline(merged.Timeline, report.Timeline)
+		for j := range merged.Stages {
+			if j < len(report.Stages) {
+				merged.Stages[j].Stats.merge(report.Stages[j].Stats)
+			}
+		}
+		for j := range merged.Steps {
+			if j < len(report.Steps) {
+				merged.Steps[j].Stats.merge(report.Steps[j].Stats)
+			}
+		}
+		for _, tag := range report.Tags {
+			tags.merge(tag.Tag, tag.Stats)
+		}
+		merged.Iterations += report.Iterations
+		merged.CompletedIterations += report.CompletedIterations
+
+		merged.TotalTime = max(merged.TotalTime, report.TotalTime)
+		merged.Abandoned += report.Abandoned
+		if report.Aborted != "" {
+			aborted = append(aborted, fmt.Sprintf("agente %s: %s", agents[i], report.Aborted))
+		}
+	}
+	merged.Aborted = strings.Join(aborted, "; ")
+	merged.Tags = tags.sorted()
+
+	return merged
+}
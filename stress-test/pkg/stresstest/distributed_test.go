@@ -0,0 +1,338 @@
+package stresstest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// iniciarAgentes sobe n agentes locais e retorna seus endereços
+func iniciarAgentes(t *testing.T, n int) []string {
+	t.Helper()
+	var enderecos []string
+	for i := 0; i < n; i++ {
+		agente := httptest.NewServer(NewAgent(""))
+		t.Cleanup(agente.Close)
+		enderecos = append(enderecos, agente.URL)
+	}
+	return enderecos
+}
+
+func TestSplitConfig(t *testing.T) {
+	config := Config{RequestSpec: RequestSpec{URL: "http://localhost"}, Requests: 10, Concurrency: 4}
+	partes, err := splitConfig(config, 3)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	requests, concorrencia := 0, 0
+	for _, parte := range partes {
+		requests += parte.Requests
+		concorrencia += parte.Concurrency
+		if err := parte.Validate(); err != nil {
+			t.Errorf("Parte inválida %+v: %v", parte, err)
+		}
+	}
+	if requests != 10 || concorrencia != 4 {
+		t.Errorf("Esperado 10 requests e concorrência 4 no total, obtido %d e %d", requests, concorrencia)
+	}
+
+	// Estágios: o alvo de cada estágio é repartido entre os agentes
+	config = Config{
+		RequestSpec: RequestSpec{URL: "http://localhost"},
+		Concurrency: 2,
+		Stages:      []Stage{{Duration: time.Second, Target: 100}, {Duration: time.Second, Target: 7}},
+	}
+	partes, _ = splitConfig(config, 2)
+	if partes[0].Stages[0].Target != 50 || partes[1].Stages[1].Target != 3 || partes[0].Stages[1].Target != 4 {
+		t.Errorf("Alvos divididos incorretamente: %+v / %+v", partes[0].Stages, partes[1].Stages)
+	}
+
+	if _, err := splitConfig(Config{Requests: 2, Concurrency: 1}, 3); err == nil {
+		t.Error("Esperado erro com menos requests que agentes")
+	}
+}
+
+func TestSplitConfig_ArquivosLocais(t *testing.T) {
+	// Os caminhos do coordenador não existem (ou são outros arquivos) nas máquinas dos agentes
+	casos := map[string]Config{
+		"certificado do cliente": {RequestSpec: RequestSpec{URL: "http://x"},
+			Transport: TransportConfig{ClientCert: "cliente.pem", ClientKey: "cliente.key"}},
+		"arquivos .proto": {GRPC: &GRPCConfig{Target: "x:50051", ProtoFiles: []string{"pedidos.proto"}}},
+	}
+	for nome, config := range casos {
+		config.Requests, config.Concurrency = 10, 2
+		if _, err := splitConfig(config, 2); err == nil || !strings.Contains(err.Error(), "não envia arquivos") {
+			t.Errorf("%s: esperado erro de arquivo local, obtido %v", nome, err)
+		}
+	}
+}
+
+func TestSplitConfig_IncorporaFeedsECorpos(t *testing.T) {
+	dir := t.TempDir()
+	feed := escreverArquivo(t, dir, "ceps.csv", "cep\n01001000\n20040002\n")
+	corpo := escreverArquivo(t, dir, "login.json", `{"user":"{{.Feed "ceps" "cep"}}"}`)
+
+	config := Config{
+		Scenario: &Scenario{Feeds: map[string]string{"ceps": feed},
+			Requests: []ScenarioRequest{{BodyFile: corpo, RequestSpec: RequestSpec{URL: "http://x", Method: "POST"}}}},
+		Requests:    10,
+		Concurrency: 2,
+	}
+	partes, err := splitConfig(config, 2)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	// Os agentes recebem o conteúdo dos arquivos, sem os caminhos do coordenador
+	for i, parte := range partes {
+		cenario := parte.Scenario
+		if len(cenario.Feeds) != 0 || len(cenario.FeedRows["ceps"]) != 2 || cenario.FeedRows["ceps"][1]["cep"] != "20040002" {
+			t.Errorf("Parte %d: esperado feed incorporado, obtido feeds %v e linhas %v", i, cenario.Feeds, cenario.FeedRows)
+		}
+		if cenario.Requests[0].BodyFile != "" || !strings.Contains(cenario.Requests[0].Body, `"user"`) {
+			t.Errorf("Parte %d: esperado corpo incorporado, obtido %+v", i, cenario.Requests[0])
+		}
+		if err := checkLocalFiles(parte); err != nil {
+			t.Errorf("Parte %d: esperado aceita pelo agente, obtido %v", i, err)
+		}
+	}
+
+	// A configuração do coordenador não é alterada
+	if config.Scenario.Feeds["ceps"] != feed || config.Scenario.Requests[0].BodyFile != corpo {
+		t.Errorf("Configuração original alterada: %+v", config.Scenario)
+	}
+
+	// Arquivos inexistentes falham no coordenador, antes de contatar os agentes
+	config.Scenario = &Scenario{Feeds: map[string]string{"ceps": filepath.Join(dir, "inexistente.csv")},
+		Requests: []ScenarioRequest{{RequestSpec: RequestSpec{URL: "http://x"}}}}
+	if _, err := splitConfig(config, 2); err == nil || !strings.Contains(err.Error(), "feed ceps") {
+		t.Errorf("Esperado erro do feed inexistente, obtido %v", err)
+	}
+}
+
+func TestRunDistributed(t *testing.T) {
+	var recebidos atomic.Int64
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recebidos.Add(1)
+	}))
+	defer servidor.Close()
+
+	config := Config{
+		RequestSpec: RequestSpec{URL: servidor.URL, Headers: map[string]string{"X-Teste": "distribuido"}},
+		Requests:    30,
+		Concurrency: 6,
+		Thresholds:  []Threshold{{Expression: "requests>=30", Metric: "requests", Operator: ">=", Value: 30}},
+	}
+	report, err := RunDistributed(context.Background(), config, iniciarAgentes(t, 3), "")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if recebidos.Load() != 30 || report.TotalRequests != 30 || report.SuccessCount != 30 {
+		t.Errorf("Esperado 30 requests em todos os agentes, servidor recebeu %d e relatório tem %d (%d sucessos)",
+			recebidos.Load(), report.TotalRequests, report.SuccessCount)
+	}
+	// Os histogramas dos agentes são somados sem perda de amostras
+	if report.Latency.Total != 30 || report.Latency.Percentile(99) <= 0 {
+		t.Errorf("Esperado histograma combinado com 30 amostras, obtido %d", report.Latency.Total)
+	}
+	if results := EvaluateThresholds(config.Thresholds, report); FailedChecks(results) != 0 {
+		t.Errorf("Esperado threshold atendido no relatório combinado: %+v", results)
+	}
+}
+
+func TestRunDistributed_PerfilDeCarga(t *testing.T) {
+	servidor := novoServidorVazio(t)
+
+	config := Config{
+		RequestSpec: RequestSpec{URL: servidor.URL},
+		Concurrency: 4,
+		Stages:      []Stage{{Name: "constante", Duration: 500 * time.Millisecond, Target: 200}},
+	}
+	report, err := RunDistributed(context.Background(), config, iniciarAgentes(t, 2), "")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	// 200 req/s subindo linearmente por 0,5s: 50 requests no total
+	if report.TotalRequests < 45 || report.TotalRequests > 50 {
+		t.Errorf("Esperado cerca de 50 requests, obtido %d", report.TotalRequests)
+	}
+	if len(report.Stages) != 1 || report.Stages[0].TotalRequests != report.TotalRequests || report.Stages[0].Target != 200 {
+		t.Errorf("Estágio combinado incorreto: %+v", report.Stages)
+	}
+}
+
+func TestRunDistributed_SequenciaEFeeds(t *testing.T) {
+	var mu sync.Mutex
+	corpos := make(map[string]int)
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		corpo, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		corpos[string(corpo)]++
+		mu.Unlock()
+	}))
+	defer servidor.Close()
+
+	feed := escreverArquivo(t, t.TempDir(), "ids.csv", "id\na\nb\nc\nd\n")
+	config := Config{
+		Scenario: &Scenario{Feeds: map[string]string{"ids": feed}, Requests: []ScenarioRequest{{
+			RequestSpec: RequestSpec{URL: servidor.URL, Method: "POST", Body: `{{.Seq}}`},
+		}}},
+		Requests:    8,
+		Concurrency: 2,
+	}
+	if _, err := RunDistributed(context.Background(), config, iniciarAgentes(t, 2), ""); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	// Os agentes intercalam as sequências: cada número de 1 a 8 aparece uma única vez
+	for seq := 1; seq <= 8; seq++ {
+		if corpos[strconv.Itoa(seq)] != 1 {
+			t.Errorf("Esperado seq %d uma única vez, corpos recebidos: %v", seq, corpos)
+		}
+	}
+
+	// As linhas do feed também são intercaladas: cada uma é usada duas vezes (uma por volta)
+	clear(corpos)
+	config.Scenario = &Scenario{Feeds: map[string]string{"ids": feed}, Requests: []ScenarioRequest{{
+		RequestSpec: RequestSpec{URL: servidor.URL, Method: "POST", Body: `{{.Feed "ids" "id"}}`},
+	}}}
+	if _, err := RunDistributed(context.Background(), config, iniciarAgentes(t, 2), ""); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if corpos[id] != 2 {
+			t.Errorf("Esperado linha %s usada duas vezes, corpos recebidos: %v", id, corpos)
+		}
+	}
+}
+
+func TestRunDistributed_AgenteIndisponivel(t *testing.T) {
+	agentes := iniciarAgentes(t, 1)
+	agentes = append(agentes, "127.0.0.1:1")
+
+	config := Config{RequestSpec: RequestSpec{URL: "http://localhost"}, Requests: 10, Concurrency: 2}
+	if _, err := RunDistributed(context.Background(), config, agentes, ""); err == nil {
+		t.Error("Esperado erro com agente indisponível")
+	}
+}
+
+func TestRunDistributed_FalhaInterrompeOutrosAgentes(t *testing.T) {
+	servidor := novoServidorVazio(t)
+
+	// Um agente livre que falha depois que o teste já começou nos demais
+	recusa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/run" {
+			time.Sleep(DistributedStartDelay + 500*time.Millisecond)
+			http.Error(w, "falha simulada", http.StatusInternalServerError)
+		}
+	}))
+	defer recusa.Close()
+	agentes := append(iniciarAgentes(t, 1), recusa.URL)
+
+	// A parte do agente saudável levaria 30s se não fosse interrompida
+	config := Config{
+		RequestSpec: RequestSpec{URL: servidor.URL},
+		Concurrency: 2,
+		Stages:      []Stage{{Duration: 30 * time.Second, Target: 10}},
+	}
+	inicio := time.Now()
+	_, err := RunDistributed(context.Background(), config, agentes, "")
+	if err == nil || !strings.Contains(err.Error(), "falha simulada") {
+		t.Fatalf("Esperado erro do agente que falhou, obtido %v", err)
+	}
+	if decorrido := time.Since(inicio); decorrido > 5*time.Second {
+		t.Errorf("Esperado interromper os demais agentes após a falha, levou %v", decorrido)
+	}
+}
+
+func TestAgente_CancelamentoDuranteEspera(t *testing.T) {
+	var recebidos atomic.Int64
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recebidos.Add(1)
+	}))
+	defer servidor.Close()
+	agente := iniciarAgentes(t, 1)[0]
+
+	// O início é agendado para daqui a uma hora, mas o coordenador desiste antes
+	job := AgentJob{
+		Config:  Config{RequestSpec: RequestSpec{URL: servidor.URL}, Requests: 10, Concurrency: 1},
+		StartAt: time.Now().Add(time.Hour),
+	}
+	payload, _ := json.Marshal(job)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, agente+"/run", bytes.NewReader(payload))
+	if _, err := http.DefaultClient.Do(req); err == nil {
+		t.Fatal("Esperado erro ao cancelar a requisição")
+	}
+
+	// O agente abandona a espera e volta a ficar livre sem executar o teste
+	prazo := time.Now().Add(5 * time.Second)
+	for checkAgent(agente, "") != nil {
+		if time.Now().After(prazo) {
+			t.Fatal("Agente continuou ocupado após o cancelamento")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if recebidos.Load() != 0 {
+		t.Errorf("Esperado nenhum request após o cancelamento, obtido %d", recebidos.Load())
+	}
+}
+
+func TestAgente_Token(t *testing.T) {
+	agente := httptest.NewServer(NewAgent("segredo"))
+	defer agente.Close()
+
+	if err := checkAgent(agente.URL, ""); err == nil {
+		t.Error("Esperado erro sem o token do agente")
+	}
+	if err := checkAgent(agente.URL, "outro"); err == nil {
+		t.Error("Esperado erro com token incorreto")
+	}
+	if err := checkAgent(agente.URL, "segredo"); err != nil {
+		t.Errorf("Esperado agente disponível com o token correto, obtido %v", err)
+	}
+
+	// O teste não é executado sem o token, mesmo chamando /run diretamente
+	config := Config{RequestSpec: RequestSpec{URL: "http://127.0.0.1:1"}, Requests: 1, Concurrency: 1}
+	if _, err := RunDistributed(context.Background(), config, []string{agente.URL}, "outro"); err == nil {
+		t.Error("Esperado erro ao executar com token incorreto")
+	}
+}
+
+func TestAgente_RecusaArquivosLocais(t *testing.T) {
+	agente := iniciarAgentes(t, 1)[0]
+
+	// Um coordenador que não usa RunDistributed não pode fazer o agente ler os próprios arquivos
+	job := AgentJob{
+		Config: Config{
+			Scenario: &Scenario{Requests: []ScenarioRequest{{BodyFile: "/etc/passwd",
+				RequestSpec: RequestSpec{URL: "http://127.0.0.1:1", Method: "POST"}}}},
+			Requests:    1,
+			Concurrency: 1,
+		},
+		StartAt: time.Now(),
+	}
+	payload, _ := json.Marshal(job)
+	resp, err := http.Post(agente+"/run", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("Erro ao chamar o agente: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Esperado status %d, obtido %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
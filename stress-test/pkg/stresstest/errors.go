@@ -0,0 +1,118 @@
+package stresstest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+)
+
+// Categorias de erros de transporte usadas no relatório
+const (
+	ErrorTimeout           = "timeout"            // Tempo limite excedido (conexão, handshake ou resposta)
+	ErrorConnectionRefused = "connection_refused" // Conexão recusada (serviço fora do ar ou porta errada)
+	ErrorDNS               = "dns"                // Falha na resolução do nome do host
+	ErrorTLS               = "tls"                // Falha no handshake ou na validação do certificado TLS
+	ErrorConnectionReset   = "connection_reset"   // Conexão encerrada pelo servidor durante o request
+	ErrorBodyRead          = "body_read"          // Falha ao ler o corpo da resposta
+	ErrorCanceled          = "canceled"           // Request cancelado antes de concluir
+	ErrorInvalidRequest    = "invalid_request"    // Requisição não pôde ser montada (template, URL...)
+	ErrorOther             = "other"              // Qualquer outro erro
+)
+
+// maxErrorSamples limita quantas mensagens de exemplo são guardadas por categoria.
+const maxErrorSamples = 3
+
+// ErrorStat contém a contagem e exemplos de mensagens de uma categoria de erro.
+type ErrorStat struct {
+	Count   int      `json:"count"`             // Quantidade de erros da categoria
+	Samples []string `json:"samples,omitempty"` // Mensagens distintas de exemplo (até maxErrorSamples)
+}
+
+// bodyReadError marca erros ocorridos durante a leitura do corpo da resposta.
+type bodyReadError struct {
+	err error
+}
+
+func (e *bodyReadError) Error() string { return "erro ao ler corpo da resposta: " + e.err.Error() }
+func (e *bodyReadError) Unwrap() error { return e.err }
+
+// requestError marca erros ocorridos ao montar a requisição, antes de qualquer envio.
+type requestError struct {
+	err error
+}
+
+func (e *requestError) Error() string { return "requisição inválida: " + e.err.Error() }
+func (e *requestError) Unwrap() error { return e.err }
+
+// ClassifyError identifica a categoria de um erro de transporte.
+// Permite distinguir um servidor saturado (timeouts, resets) de uma URL mal configurada
+// (DNS, conexão recusada) ou de problemas de certificado (TLS).
+func ClassifyError(err error) string {
+	var bodyErr *bodyReadError
+	var reqErr *requestError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &reqErr):
+		return ErrorInvalidRequest
+	case errors.Is(err, context.Canceled):
+		return ErrorCanceled
+	case errors.As(err, &dnsErr):
+		// Verificado antes do timeout, pois falhas de DNS também podem expirar
+		return ErrorDNS
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case errors.As(err, &bodyErr):
+		return ErrorBodyRead
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorConnectionRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorConnectionReset
+	case isTLSError(err):
+		return ErrorTLS
+	}
+	return ErrorOther
+}
+
+// isTLSError indica se o erro ocorreu no handshake ou na validação do certificado TLS.
+func isTLSError(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+
+	return errors.As(err, &verifyErr) || errors.As(err, &recordErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &unknownAuthority) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr)
+}
+
+// recordError contabiliza um erro na sua categoria, guardando mensagens distintas de exemplo.
+func recordError(errs map[string]ErrorStat, err error) {
+	kind := ClassifyError(err)
+	stat := errs[kind]
+	stat.Count++
+	stat.Samples = addSample(stat.Samples, err.Error())
+	errs[kind] = stat
+}
+
+// addSample acrescenta uma mensagem de exemplo caso ainda não exista e haja espaço.
+func addSample(samples []string, message string) []string {
+	if len(samples) >= maxErrorSamples {
+		return samples
+	}
+	for _, sample := range samples {
+		if sample == message {
+			return samples
+		}
+	}
+	return append(samples, message)
+}
@@ -0,0 +1,111 @@
+package stresstest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// executarUm dispara um único request com a configuração informada e retorna o relatório
+func executarUm(url string, transport TransportConfig) Report {
+	return Run(context.Background(), Config{RequestSpec: RequestSpec{URL: url}, Requests: 1, Concurrency: 1, Transport: transport})
+}
+
+// categoriaUnica retorna a única categoria de erro presente no relatório
+func categoriaUnica(t *testing.T, report Report) string {
+	t.Helper()
+	if report.ErrorCount != 1 || len(report.Errors) != 1 {
+		t.Fatalf("Esperado exatamente um erro, obtido %d (%v)", report.ErrorCount, report.Errors)
+	}
+	for kind, stat := range report.Errors {
+		if len(stat.Samples) != 1 {
+			t.Errorf("Esperado uma mensagem de exemplo, obtido %v", stat.Samples)
+		}
+		return kind
+	}
+	return ""
+}
+
+func TestClassificacaoDeErros(t *testing.T) {
+	lento := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer lento.Close()
+
+	seguro := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer seguro.Close()
+
+	// Servidor que encerra a conexão sem responder
+	reset := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer reset.Close()
+
+	// Servidor que promete um corpo maior do que envia
+	corpoIncompleto := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, _ := w.(http.Hijacker).Hijack()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 100\r\n\r\nparcial")
+		buf.Flush()
+		conn.Close()
+	}))
+	defer corpoIncompleto.Close()
+
+	// Porta sem nenhum serviço escutando
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	fechada := "http://" + listener.Addr().String()
+	listener.Close()
+
+	casos := []struct {
+		nome      string
+		url       string
+		transport TransportConfig
+		esperado  string
+	}{
+		{"timeout", lento.URL, TransportConfig{Timeout: 50 * time.Millisecond}, ErrorTimeout},
+		{"conexão recusada", fechada, TransportConfig{}, ErrorConnectionRefused},
+		{"certificado não confiável", seguro.URL, TransportConfig{}, ErrorTLS},
+		{"conexão encerrada", reset.URL, TransportConfig{}, ErrorConnectionReset},
+		{"corpo incompleto", corpoIncompleto.URL, TransportConfig{}, ErrorBodyRead},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if obtido := categoriaUnica(t, executarUm(c.url, c.transport)); obtido != c.esperado {
+				t.Errorf("Categoria incorreta: esperado %s, obtido %s", c.esperado, obtido)
+			}
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	casos := map[string]error{
+		ErrorDNS:            &net.DNSError{Err: "no such host", Name: "nao-existe.invalid", IsNotFound: true},
+		ErrorCanceled:       context.Canceled,
+		ErrorTimeout:        context.DeadlineExceeded,
+		ErrorInvalidRequest: &requestError{errors.New("template inválido")},
+		ErrorOther:          errors.New("falha desconhecida"),
+	}
+	for esperado, err := range casos {
+		if obtido := ClassifyError(err); obtido != esperado {
+			t.Errorf("%v: esperado %s, obtido %s", err, esperado, obtido)
+		}
+	}
+}
+
+func TestRun_Non2xxSeparadoDosErros(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	report := Run(context.Background(), Config{RequestSpec: RequestSpec{URL: server.URL}, Requests: 10, Concurrency: 2})
+
+	if report.Non2xxCount != 10 || report.ErrorCount != 0 || len(report.Errors) != 0 {
+		t.Errorf("Esperado 10 respostas não-2xx e nenhum erro de transporte, obtido %d/%d",
+			report.Non2xxCount, report.ErrorCount)
+	}
+}
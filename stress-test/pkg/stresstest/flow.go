@@ -0,0 +1,335 @@
+package stresstest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flow descreve o roteiro de um usuário virtual: passos executados em ordem, em que cada passo
+// pode extrair valores da resposta (caminho JSON, cabeçalho ou expressão regular) e usá-los nos
+// passos seguintes com {{.Var "nome"}}. Cada iteração do fluxo é uma sessão independente, com os
+// próprios cookies, e conta como um request na quantidade (Config.Requests) ou na taxa dos estágios.
+type Flow struct {
+	Feeds    map[string]string `yaml:"feeds,omitempty"` // Feeds de dados: nome -> caminho do arquivo CSV
+	FeedRows FeedRows          `yaml:"-"`               // Feeds já carregados (enviados aos agentes no teste distribuído)
+	Steps    []FlowStep        `yaml:"steps"`           // Passos executados em ordem a cada iteração
+
+	once     sync.Once          // Garante que a preparação ocorra uma única vez
+	err      error              // Erro encontrado durante a preparação
+	compiled []*compiledRequest // Requisições dos passos com templates já compilados
+	feeds    map[string]*feed   // Dados carregados de cada feed
+	seq      sequence           // Contador global de iterações
+}
+
+// FlowStep é um passo do fluxo: a requisição, os valores extraídos da resposta e a pausa até o
+// próximo passo.
+type FlowStep struct {
+	Name        string                `yaml:"name,omitempty"`       // Nome do passo (usado no relatório)
+	BodyFile    string                `yaml:"body_file,omitempty"`  // Arquivo com o corpo da requisição (alternativa a body)
+	Extract     map[string]*Extractor `yaml:"extract,omitempty"`    // Variáveis extraídas da resposta: nome -> origem
+	ThinkTime   time.Duration         `yaml:"think_time,omitempty"` // Pausa antes do próximo passo, simulando o usuário
+	RequestSpec `yaml:",inline"`
+}
+
+// Extractor indica de onde um valor é extraído da resposta. Apenas uma origem deve ser informada.
+type Extractor struct {
+	JSON   string `yaml:"json,omitempty"`   // Caminho JSON (ex: $.access_token)
+	Header string `yaml:"header,omitempty"` // Nome do cabeçalho da resposta
+	Regex  string `yaml:"regex,omitempty"`  // Expressão regular aplicada ao corpo (primeiro grupo ou a correspondência inteira)
+
+	regex *regexp.Regexp // Expressão regular compilada
+}
+
+// LoadFlow lê um arquivo de fluxo em YAML ou JSON.
+// Caminhos de feeds e de body_file são resolvidos relativamente ao diretório do arquivo.
+//
+// Exemplo de arquivo:
+//
+//	feeds:
+//	  usuarios: usuarios.csv
+//	steps:
+//	  - name: login
+//	    method: POST
+//	    url: http://localhost:8000/login
+//	    content_type: application/json
+//	    body: '{"user":"{{.Feed "usuarios" "user"}}","password":"{{.Feed "usuarios" "password"}}"}'
+//	    extract:
+//	      token: {json: $.access_token}
+//	    think_time: 1s
+//	  - name: pedidos
+//	    url: http://localhost:8000/orders
+//	    headers: {Authorization: 'Bearer {{.Var "token"}}'}
+func LoadFlow(path string) (*Flow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler fluxo: %w", err)
+	}
+
+	// JSON é um subconjunto de YAML, então o mesmo decodificador atende os dois formatos
+	flow := &Flow{}
+	if err := yaml.Unmarshal(data, flow); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar fluxo: %w", err)
+	}
+
+	// Resolve caminhos relativos ao diretório do fluxo
+	dir := filepath.Dir(path)
+	for name, file := range flow.Feeds {
+		flow.Feeds[name] = resolvePath(dir, file)
+	}
+	for i := range flow.Steps {
+		if flow.Steps[i].BodyFile != "" {
+			flow.Steps[i].BodyFile = resolvePath(dir, flow.Steps[i].BodyFile)
+		}
+	}
+
+	return flow, nil
+}
+
+// validate prepara o fluxo e verifica se todos os passos são válidos.
+func (f *Flow) validate() error {
+	if len(f.Steps) == 0 {
+		return fmt.Errorf("fluxo não possui passos")
+	}
+	return f.prepare()
+}
+
+// prepare carrega os feeds, lê os arquivos de corpo e compila os templates e as extrações.
+// É executado uma única vez; chamadas seguintes retornam o mesmo resultado.
+func (f *Flow) prepare() error {
+	f.once.Do(func() {
+		f.err = f.compile()
+	})
+	return f.err
+}
+
+// compile realiza a preparação efetiva do fluxo (ver prepare).
+func (f *Flow) compile() error {
+	var err error
+	if f.feeds, err = loadFeeds(f.Feeds, f.FeedRows, &f.seq); err != nil {
+		return err
+	}
+
+	// Variáveis disponíveis para cada passo: as extraídas pelos passos anteriores
+	declared := make(map[string]bool)
+	for i, step := range f.Steps {
+		label := step.label(i)
+
+		if step.ThinkTime < 0 {
+			return fmt.Errorf("%s: think_time não pode ser negativo", label)
+		}
+		if step.Body, err = readBody(step.Body, step.BodyFile); err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+
+		compiled, err := compileRequest(step.RequestSpec)
+		if err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		compiled.name = step.Name
+
+		// Renderiza uma vez para validar os templates, as variáveis usadas e a requisição resultante
+		spec, err := compiled.render(&templateData{feeds: f.feeds, declared: declared})
+		if err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		if err := spec.validate(); err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+
+		for name, extractor := range step.Extract {
+			if err := extractor.validate(); err != nil {
+				return fmt.Errorf("%s: extract %s: %w", label, name, err)
+			}
+			declared[name] = true
+		}
+
+		f.compiled = append(f.compiled, compiled)
+	}
+
+	return nil
+}
+
+// label retorna o nome do passo ou um rótulo gerado a partir da sua posição.
+func (s *FlowStep) label(index int) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return fmt.Sprintf("passo %d", index+1)
+}
+
+// needsBody indica se alguma extração do passo depende do corpo da resposta.
+func (s *FlowStep) needsBody() bool {
+	for _, extractor := range s.Extract {
+		if extractor.JSON != "" || extractor.Regex != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validate verifica se exatamente uma origem foi informada e compila a expressão regular.
+func (x *Extractor) validate() error {
+	if x == nil {
+		return fmt.Errorf("informe json, header ou regex")
+	}
+
+	sources := 0
+	for _, source := range []string{x.JSON, x.Header, x.Regex} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("informe apenas uma origem: json, header ou regex")
+	}
+
+	switch {
+	case x.JSON != "":
+		if _, err := parseJSONPath(x.JSON); err != nil {
+			return err
+		}
+	case x.Regex != "" && x.regex == nil:
+		re, err := regexp.Compile(x.Regex)
+		if err != nil {
+			return fmt.Errorf("expressão regular inválida %q: %w", x.Regex, err)
+		}
+		x.regex = re
+	}
+	return nil
+}
+
+// extract obtém o valor da resposta. O documento JSON é decodificado sob demanda e reaproveitado
+// pelas demais extrações do mesmo passo.
+func (x *Extractor) extract(resp *response, document *any, decoded *bool) (string, bool) {
+	switch {
+	case x.Header != "":
+		value := resp.header.Get(x.Header)
+		return value, value != ""
+
+	case x.JSON != "":
+		if !*decoded {
+			*decoded = true
+			if json.Unmarshal(resp.body, document) != nil {
+				*document = nil
+			}
+		}
+		value, ok := lookupJSONPath(*document, x.JSON)
+		if !ok || value == nil {
+			return "", false
+		}
+		return formatJSONValue(value), true
+
+	default:
+		match := x.regex.FindSubmatch(resp.body)
+		if match == nil {
+			return "", false
+		}
+		if len(match) > 1 {
+			return string(match[1]), true
+		}
+		return string(match[0]), true
+	}
+}
+
+// stepPassed indica se o fluxo pode seguir para o próximo passo: a resposta chegou, o status está
+// na faixa 2xx e todas as asserções e extrações foram atendidas.
+func stepPassed(result Result) bool {
+	return result.Error == nil && result.ok() && len(result.Failed) == 0
+}
+
+// flowExecutor executa iterações do fluxo usando um transporte HTTP compartilhado.
+type flowExecutor struct {
+	flow   *Flow        // Fluxo executado a cada iteração
+	client *http.Client // Cliente HTTP cujo transporte é compartilhado por todas as sessões
+	tagBy  string       // Agrupamento automático dos passos em tags (Config.TagBy)
+}
+
+// newFlowExecutor cria o executor de fluxos da configuração.
+func newFlowExecutor(config Config) *flowExecutor {
+	return &flowExecutor{flow: config.Flow, client: newClient(config), tagBy: config.TagBy}
+}
+
+// Execute executa uma iteração completa do fluxo, retornando o resultado de cada passo em Steps.
+// A iteração para no primeiro passo que não for concluído com sucesso, pois os passos seguintes
+// normalmente dependem dele (ex: login).
+func (e *flowExecutor) Execute(ctx context.Context) Result {
+	// Cada iteração é a sessão de um usuário virtual: cookies próprios, conexões compartilhadas
+	jar, _ := cookiejar.New(nil)
+	client := *e.client
+	client.Jar = jar
+
+	// Os dados de template são compartilhados pelos passos: a mesma linha de cada feed é usada
+	// em toda a iteração e as variáveis extraídas ficam disponíveis para os passos seguintes
+	data := &templateData{feeds: e.flow.feeds, seq: e.flow.seq.next(), vars: make(map[string]string)}
+
+	var iteration Result
+	for i, compiled := range e.flow.compiled {
+		step := &e.flow.Steps[i]
+
+		var result Result
+		spec, err := compiled.render(data)
+		if err != nil {
+			result = Result{Error: &requestError{err}}
+		} else {
+			var resp *response
+			result, resp = sendRequest(ctx, &client, spec, step.needsBody())
+			if resp != nil {
+				result.Failed = append(result.Failed, extractVars(step, resp, data.vars)...)
+			}
+		}
+		result.Tag = requestTag(e.tagBy, step.Name, spec)
+		iteration.Steps = append(iteration.Steps, result)
+
+		if !stepPassed(result) || i == len(e.flow.compiled)-1 {
+			break
+		}
+
+		// Pausa do usuário entre os passos (interrompida quando os requests são cancelados)
+		if step.ThinkTime > 0 {
+			timer := time.NewTimer(step.ThinkTime)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return iteration
+			}
+		}
+	}
+	return iteration
+}
+
+// Close fecha as conexões ociosas do cliente.
+func (e *flowExecutor) Close() {
+	e.client.CloseIdleConnections()
+}
+
+// extractVars extrai as variáveis do passo para vars e retorna as extrações que falharam,
+// no formato das falhas de asserção ("extract: nome").
+func extractVars(step *FlowStep, resp *response, vars map[string]string) []string {
+	var failed []string
+	var document any
+	decoded := false
+
+	// Nomes em ordem alfabética para que as falhas sejam estáveis
+	for _, name := range slices.Sorted(maps.Keys(step.Extract)) {
+		value, ok := step.Extract[name].extract(resp, &document, &decoded)
+		if !ok {
+			failed = append(failed, "extract: "+name)
+			continue
+		}
+		vars[name] = value
+	}
+	return failed
+}
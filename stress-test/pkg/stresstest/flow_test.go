@@ -0,0 +1,231 @@
+package stresstest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// lojaDeTeste simula um serviço que exige login: o token vem no corpo, a sessão em um cookie e o
+// token CSRF em um formulário HTML
+type lojaDeTeste struct {
+	mu      sync.Mutex
+	tokens  map[string]string // token -> usuário
+	pedidos map[string]int    // usuário -> pedidos criados
+	senha   atomic.Value      // Senha aceita no login
+}
+
+func novaLojaDeTeste(t *testing.T) (*lojaDeTeste, *httptest.Server) {
+	t.Helper()
+	loja := &lojaDeTeste{tokens: make(map[string]string), pedidos: make(map[string]int)}
+	loja.senha.Store("segredo")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		var credenciais struct{ User, Password string }
+		json.NewDecoder(r.Body).Decode(&credenciais)
+		if credenciais.Password != loja.senha.Load() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		token := newUUID()
+		loja.mu.Lock()
+		loja.tokens[token] = credenciais.User
+		loja.mu.Unlock()
+
+		http.SetCookie(w, &http.Cookie{Name: "sessao", Value: credenciais.User})
+		w.Header().Set("X-Request-Id", "req-"+credenciais.User)
+		fmt.Fprintf(w, `{"access_token":%q,"user":{"id":42}}`, token)
+	})
+	mux.HandleFunc("GET /checkout", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<form><input type="hidden" name="csrf" value="csrf-123"></form>`)
+	})
+	mux.HandleFunc("POST /orders", func(w http.ResponseWriter, r *http.Request) {
+		loja.mu.Lock()
+		defer loja.mu.Unlock()
+
+		usuario, ok := loja.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+		cookie, err := r.Cookie("sessao")
+		switch {
+		case !ok || err != nil || cookie.Value != usuario:
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Header.Get("X-CSRF") != "csrf-123" || r.URL.Query().Get("cliente") != "42":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			loja.pedidos[usuario]++
+			w.WriteHeader(http.StatusCreated)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return loja, server
+}
+
+// fluxoDeCompra monta o fluxo login -> checkout -> pedido contra o servidor informado
+func fluxoDeCompra(t *testing.T, url string) *Flow {
+	t.Helper()
+	dir := t.TempDir()
+	usuarios := escreverArquivo(t, dir, "usuarios.csv", "user,password\nana,segredo\nbia,segredo\n")
+
+	return &Flow{
+		Feeds: map[string]string{"usuarios": usuarios},
+		Steps: []FlowStep{
+			{
+				Name: "login",
+				RequestSpec: RequestSpec{
+					Method: "POST",
+					URL:    url + "/login",
+					Body:   `{"user":"{{.Feed "usuarios" "user"}}","password":"{{.Feed "usuarios" "password"}}"}`,
+				},
+				Extract: map[string]*Extractor{
+					"token":   {JSON: "$.access_token"},
+					"cliente": {JSON: "$.user.id"},
+					"req":     {Header: "X-Request-Id"},
+				},
+			},
+			{
+				Name:        "checkout",
+				RequestSpec: RequestSpec{URL: url + "/checkout"},
+				Extract:     map[string]*Extractor{"csrf": {Regex: `name="csrf" value="([^"]+)"`}},
+				ThinkTime:   5 * time.Millisecond,
+			},
+			{
+				Name: "pedido",
+				RequestSpec: RequestSpec{
+					Method:  "POST",
+					URL:     url + `/orders?cliente={{.Var "cliente"}}`,
+					Headers: map[string]string{"Authorization": `Bearer {{.Var "token"}}`, "X-CSRF": `{{.Var "csrf"}}`},
+				},
+			},
+		},
+	}
+}
+
+func TestFlow_ExtraiVariaveisEMantemCookies(t *testing.T) {
+	loja, server := novaLojaDeTeste(t)
+	config := Config{Flow: fluxoDeCompra(t, server.URL), Requests: 20, Concurrency: 4}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	report := Run(context.Background(), config)
+
+	if report.Iterations != 20 || report.CompletedIterations != 20 {
+		t.Fatalf("Esperado 20 iterações concluídas, obtido %d de %d", report.CompletedIterations, report.Iterations)
+	}
+	// Cada iteração dispara um request por passo
+	if report.TotalRequests != 60 || report.ErrorCount != 0 || report.AssertionFailedCount != 0 {
+		t.Errorf("Esperado 60 requests sem falhas, obtido %d (erros %d, falhas %v)",
+			report.TotalRequests, report.ErrorCount, report.AssertionFailures)
+	}
+	if len(report.Steps) != 3 || report.Steps[2].Name != "pedido" || report.Steps[2].StatusCodes[http.StatusCreated] != 20 {
+		t.Errorf("Métricas por passo incorretas: %+v", report.Steps)
+	}
+	// As linhas do feed se alternam entre as iterações
+	if loja.pedidos["ana"] != 10 || loja.pedidos["bia"] != 10 {
+		t.Errorf("Esperado 10 pedidos por usuário, obtido %v", loja.pedidos)
+	}
+}
+
+func TestFlow_InterrompeIteracaoNaFalha(t *testing.T) {
+	loja, server := novaLojaDeTeste(t)
+	loja.senha.Store("outra")
+
+	report := Run(context.Background(), Config{Flow: fluxoDeCompra(t, server.URL), Requests: 5, Concurrency: 1})
+
+	// O login falha com 401 e os passos seguintes não são executados
+	if report.Iterations != 5 || report.CompletedIterations != 0 {
+		t.Errorf("Esperado 5 iterações sem conclusão, obtido %d de %d", report.CompletedIterations, report.Iterations)
+	}
+	if report.Steps[0].TotalRequests != 5 || report.Steps[1].TotalRequests != 0 || report.TotalRequests != 5 {
+		t.Errorf("Esperado apenas o login executado, obtido %+v", report.Steps)
+	}
+}
+
+func TestFlow_ExtracaoAusenteContaComoFalha(t *testing.T) {
+	_, server := novaLojaDeTeste(t)
+	flow := fluxoDeCompra(t, server.URL)
+	flow.Steps[1].Extract["csrf"] = &Extractor{Regex: `name="token" value="([^"]+)"`}
+
+	report := Run(context.Background(), Config{Flow: flow, Requests: 3, Concurrency: 1})
+
+	if report.AssertionFailures["extract: csrf"] != 3 || report.Steps[2].TotalRequests != 0 {
+		t.Errorf("Esperado falha na extração interrompendo o fluxo, obtido %v / %+v", report.AssertionFailures, report.Steps)
+	}
+}
+
+func TestFlow_Validacao(t *testing.T) {
+	casos := map[string]*Flow{
+		"sem passos": {},
+		"variável não extraída": {Steps: []FlowStep{
+			{RequestSpec: RequestSpec{URL: `http://x/{{.Var "id"}}`}},
+		}},
+		"variável extraída depois": {Steps: []FlowStep{
+			{RequestSpec: RequestSpec{URL: `http://x/{{.Var "id"}}`}},
+			{RequestSpec: RequestSpec{URL: "http://x/"}, Extract: map[string]*Extractor{"id": {JSON: "$.id"}}},
+		}},
+		"duas origens": {Steps: []FlowStep{
+			{RequestSpec: RequestSpec{URL: "http://x/"}, Extract: map[string]*Extractor{"id": {JSON: "$.id", Header: "X-Id"}}},
+		}},
+		"regex inválida": {Steps: []FlowStep{
+			{RequestSpec: RequestSpec{URL: "http://x/"}, Extract: map[string]*Extractor{"id": {Regex: "("}}},
+		}},
+		"think time negativo": {Steps: []FlowStep{
+			{RequestSpec: RequestSpec{URL: "http://x/"}, ThinkTime: -time.Second},
+		}},
+	}
+
+	for nome, flow := range casos {
+		config := Config{Flow: flow, Requests: 1, Concurrency: 1}
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: esperado erro", nome)
+		}
+	}
+
+	combinado := Config{Flow: &Flow{Steps: []FlowStep{{RequestSpec: RequestSpec{URL: "http://x/"}}}},
+		RequestSpec: RequestSpec{URL: "http://x/"}, Requests: 1, Concurrency: 1}
+	if err := combinado.Validate(); err == nil {
+		t.Error("Esperado erro ao combinar fluxo e URL")
+	}
+}
+
+func TestLoadFlow(t *testing.T) {
+	dir := t.TempDir()
+	escreverArquivo(t, dir, "usuarios.csv", "user\nana\n")
+	path := escreverArquivo(t, dir, "fluxo.yaml", `
+feeds:
+  usuarios: usuarios.csv
+steps:
+  - name: login
+    method: POST
+    url: http://localhost/login
+    body: '{"user":"{{.Feed "usuarios" "user"}}"}'
+    extract:
+      token: {json: $.access_token}
+      sessao: {header: X-Session}
+    think_time: 1s
+  - name: perfil
+    url: http://localhost/me
+    headers: {Authorization: 'Bearer {{.Var "token"}}'}
+`)
+
+	flow, err := LoadFlow(path)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if err := flow.validate(); err != nil {
+		t.Fatalf("Fluxo inválido: %v", err)
+	}
+	if len(flow.Steps) != 2 || flow.Steps[0].ThinkTime != time.Second || flow.Steps[0].Extract["sessao"].Header != "X-Session" {
+		t.Errorf("Fluxo interpretado incorretamente: %+v", flow.Steps)
+	}
+}
@@ -0,0 +1,265 @@
+package stresstest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProtocolGRPC identifica relatórios de testes gRPC, cujos códigos de status são códigos gRPC.
+const ProtocolGRPC = "grpc"
+
+// reflectionTimeout limita a consulta ao serviço de reflexão do servidor.
+const reflectionTimeout = 10 * time.Second
+
+// GRPCConfig descreve a chamada gRPC unária disparada no modo gRPC.
+// O esquema da mensagem vem dos arquivos .proto informados ou, quando nenhum é informado,
+// da reflexão do servidor (grpc.reflection).
+type GRPCConfig struct {
+	Target      string            // Endereço do servidor (host:porta)
+	Method      string            // Método totalmente qualificado (ex: pedidos.OrderService/CreateOrder)
+	Message     string            // Mensagem de requisição em JSON
+	ProtoFiles  []string          // Arquivos .proto com a definição do serviço (opcional)
+	ImportPaths []string          // Diretórios para resolver imports dos arquivos .proto
+	Metadata    map[string]string // Metadados enviados em cada chamada
+	TLS         bool              // Conecta usando TLS (texto puro quando falso)
+
+	once     sync.Once                     // Garante que a preparação ocorra uma única vez
+	err      error                         // Erro encontrado durante a preparação
+	method   protoreflect.MethodDescriptor // Descritor do método chamado
+	fullName string                        // Nome do método no formato /pacote.Serviço/Método
+	request  proto.Message                 // Mensagem de requisição já decodificada
+}
+
+// validate verifica os campos obrigatórios sem acessar a rede. Com arquivos .proto o esquema do
+// método também é resolvido; via reflexão ele só é consultado ao criar o executor, e uma falha
+// na consulta é registrada como erro em cada request.
+func (g *GRPCConfig) validate(transport TransportConfig) error {
+	if g.Target == "" {
+		return fmt.Errorf("endereço do servidor gRPC é obrigatório")
+	}
+	if g.Method == "" {
+		return fmt.Errorf("método gRPC é obrigatório")
+	}
+	if _, _, err := splitMethod(g.Method); err != nil {
+		return err
+	}
+	if g.Message != "" && !json.Valid([]byte(g.Message)) {
+		return fmt.Errorf("mensagem gRPC deve ser um JSON válido")
+	}
+	if len(g.ProtoFiles) > 0 {
+		return g.prepare(transport)
+	}
+	return nil
+}
+
+// prepare resolve o descritor do método e decodifica a mensagem de requisição.
+// É executado uma única vez; chamadas seguintes retornam o mesmo resultado.
+func (g *GRPCConfig) prepare(transport TransportConfig) error {
+	g.once.Do(func() {
+		g.err = g.resolve(transport)
+	})
+	return g.err
+}
+
+// resolve realiza a preparação efetiva (ver prepare).
+func (g *GRPCConfig) resolve(transport TransportConfig) error {
+	service, method, err := splitMethod(g.Method)
+	if err != nil {
+		return err
+	}
+
+	var descriptor *desc.ServiceDescriptor
+	if len(g.ProtoFiles) > 0 {
+		descriptor, err = serviceFromProto(service, g.ProtoFiles, g.ImportPaths)
+	} else {
+		descriptor, err = g.serviceFromReflection(service, transport)
+	}
+	if err != nil {
+		return err
+	}
+
+	md := descriptor.FindMethodByName(method)
+	if md == nil {
+		return fmt.Errorf("método %s não encontrado no serviço %s", method, service)
+	}
+	if md.IsClientStreaming() || md.IsServerStreaming() {
+		return fmt.Errorf("método %s usa streaming: apenas chamadas unárias são suportadas", g.Method)
+	}
+	g.method = md.UnwrapMethod()
+	g.fullName = "/" + service + "/" + method
+
+	// A mensagem é decodificada uma vez e reaproveitada em todas as chamadas
+	request := dynamicpb.NewMessage(g.method.Input())
+	message := g.Message
+	if message == "" {
+		message = "{}"
+	}
+	if err := protojson.Unmarshal([]byte(message), request); err != nil {
+		return fmt.Errorf("mensagem inválida para %s: %w", g.method.Input().FullName(), err)
+	}
+	g.request = request
+
+	return nil
+}
+
+// splitMethod separa serviço e método de nomes como "pacote.Serviço/Método" ou "pacote.Serviço.Método".
+func splitMethod(name string) (string, string, error) {
+	name = strings.TrimPrefix(name, "/")
+	separator := strings.LastIndex(name, "/")
+	if separator < 0 {
+		separator = strings.LastIndex(name, ".")
+	}
+	if separator <= 0 || separator == len(name)-1 {
+		return "", "", fmt.Errorf("método gRPC inválido %q: use o formato pacote.Serviço/Método", name)
+	}
+	return name[:separator], name[separator+1:], nil
+}
+
+// serviceFromProto procura o serviço nos arquivos .proto informados.
+func serviceFromProto(service string, files, importPaths []string) (*desc.ServiceDescriptor, error) {
+	parser := protoparse.Parser{ImportPaths: importPaths}
+	descriptors, err := parser.ParseFiles(files...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao interpretar arquivos .proto: %w", err)
+	}
+	for _, fd := range descriptors {
+		if sd := fd.FindService(service); sd != nil {
+			return sd, nil
+		}
+	}
+	return nil, fmt.Errorf("serviço %s não encontrado nos arquivos .proto", service)
+}
+
+// serviceFromReflection consulta o serviço de reflexão do servidor.
+func (g *GRPCConfig) serviceFromReflection(service string, transport TransportConfig) (*desc.ServiceDescriptor, error) {
+	conn, err := g.dial(transport)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), reflectionTimeout)
+	defer cancel()
+
+	client := grpcreflect.NewClientAuto(ctx, conn)
+	defer client.Reset()
+
+	sd, err := client.ResolveService(service)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao resolver %s via reflexão (informe o .proto se o servidor não expõe reflexão): %w", service, err)
+	}
+	return sd, nil
+}
+
+// dial cria a conexão com o servidor gRPC.
+func (g *GRPCConfig) dial(transport TransportConfig) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if g.TLS {
+		tlsConfig, err := transport.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	options := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+
+	// Com substituições de DNS a conexão usa o dialer próprio, que troca o host pelo IP informado
+	if len(transport.Resolve) > 0 {
+		dialer := &net.Dialer{Timeout: orDefault(transport.DialTimeout, 10*time.Second), KeepAlive: 30 * time.Second}
+		dial := transport.dialContext(dialer)
+		options = append(options, grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return dial(ctx, "tcp", address)
+		}))
+	}
+	conn, err := grpc.NewClient(g.Target, options...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar em %s: %w", g.Target, err)
+	}
+	return conn, nil
+}
+
+// grpcExecutor dispara as chamadas gRPC usando uma única conexão HTTP/2 multiplexada.
+type grpcExecutor struct {
+	config  *GRPCConfig      // Chamada a ser disparada
+	conn    *grpc.ClientConn // Conexão compartilhada por todos os workers
+	timeout time.Duration    // Prazo máximo de cada chamada
+	err     error            // Erro de preparação, registrado em cada request
+}
+
+// newGRPCExecutor prepara a chamada e abre a conexão com o servidor.
+func newGRPCExecutor(config Config) *grpcExecutor {
+	e := &grpcExecutor{
+		config:  config.GRPC,
+		timeout: orDefault(config.Transport.Timeout, DefaultTimeout),
+	}
+	if e.err = config.GRPC.prepare(config.Transport); e.err == nil {
+		e.conn, e.err = config.GRPC.dial(config.Transport)
+	}
+	return e
+}
+
+// Execute realiza uma chamada gRPC e registra o código de status retornado.
+func (e *grpcExecutor) Execute(parent context.Context) Result {
+	if e.err != nil {
+		return Result{Error: &requestError{e.err}, Protocol: ProtocolGRPC}
+	}
+
+	ctx, cancel := context.WithTimeout(parent, e.timeout)
+	defer cancel()
+	if len(e.config.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(e.config.Metadata))
+	}
+
+	response := dynamicpb.NewMessage(e.config.method.Output())
+
+	start := time.Now()
+	err := e.conn.Invoke(ctx, e.config.fullName, e.config.request, response)
+	duration := time.Since(start)
+
+	// Chamada cancelada pelo teste (e não pelo servidor): registra como erro de transporte
+	if err != nil && parent.Err() != nil {
+		return Result{Duration: duration, Error: err, Protocol: ProtocolGRPC}
+	}
+
+	// Falhas de rede também chegam como códigos gRPC (ex: Unavailable, DeadlineExceeded)
+	result := Result{
+		StatusCode: int(status.Code(err)),
+		Duration:   duration,
+		Protocol:   ProtocolGRPC,
+	}
+	if err == nil {
+		result.BodySize = int64(proto.Size(response))
+	}
+	return result
+}
+
+// Close encerra a conexão com o servidor.
+func (e *grpcExecutor) Close() {
+	if e.conn != nil {
+		e.conn.Close()
+	}
+}
+
+// grpcCodeName retorna o nome de um código de status gRPC (ex: 14 -> Unavailable).
+func grpcCodeName(code int) string {
+	return codes.Code(code).String()
+}
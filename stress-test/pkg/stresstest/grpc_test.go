@@ -0,0 +1,182 @@
+package stresstest
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protoPedidos define o serviço de pedidos usado nos testes
+const protoPedidos = `syntax = "proto3";
+package pedidos;
+
+service OrderService {
+  rpc CreateOrder(CreateOrderRequest) returns (Order);
+  rpc ListOrders(CreateOrderRequest) returns (stream Order);
+}
+
+message CreateOrderRequest {
+  string id = 1;
+  double price = 2;
+  double tax = 3;
+}
+
+message Order {
+  string id = 1;
+  double price = 2;
+  double tax = 3;
+  double final_price = 4;
+}
+`
+
+// novoServidorGRPC sobe um OrderService com reflexão que rejeita preços negativos.
+// Retorna o endereço do servidor e o caminho do arquivo .proto.
+func novoServidorGRPC(t *testing.T) (string, string) {
+	t.Helper()
+	arquivo := escreverArquivo(t, t.TempDir(), "pedidos.proto", protoPedidos)
+
+	service, err := serviceFromProto("pedidos.OrderService", []string{arquivo}, nil)
+	if err != nil {
+		t.Fatalf("Erro ao interpretar o .proto: %v", err)
+	}
+	metodo := service.FindMethodByName("CreateOrder").UnwrapMethod()
+
+	servidor := grpc.NewServer()
+	servidor.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "pedidos.OrderService",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "CreateOrder",
+			Handler: func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				req := dynamicpb.NewMessage(metodo.Input())
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				campos := metodo.Input().Fields()
+				preco := req.Get(campos.ByName("price")).Float()
+				if preco < 0 {
+					return nil, status.Error(codes.InvalidArgument, "preço negativo")
+				}
+
+				resp := dynamicpb.NewMessage(metodo.Output())
+				saida := metodo.Output().Fields()
+				resp.Set(saida.ByName("id"), req.Get(campos.ByName("id")))
+				resp.Set(saida.ByName("final_price"), req.Get(campos.ByName("price")))
+				return resp, nil
+			},
+		}},
+	}, struct{}{})
+
+	// Reflexão servida a partir dos descritores do próprio arquivo .proto
+	arquivos := new(protoregistry.Files)
+	arquivos.RegisterFile(service.GetFile().UnwrapFile())
+	reflectionpb.RegisterServerReflectionServer(servidor,
+		reflection.NewServerV1(reflection.ServerOptions{Services: servidor, DescriptorResolver: arquivos}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Erro ao abrir porta: %v", err)
+	}
+	go servidor.Serve(listener)
+	t.Cleanup(servidor.Stop)
+
+	return listener.Addr().String(), arquivo
+}
+
+func TestRun_GRPC(t *testing.T) {
+	endereco, arquivo := novoServidorGRPC(t)
+
+	testes := []struct {
+		nome   string
+		config *GRPCConfig
+	}{
+		{"reflexão", &GRPCConfig{Target: endereco, Method: "pedidos.OrderService/CreateOrder", Message: `{"id":"a1","price":10,"tax":0.5}`}},
+		{"arquivo proto", &GRPCConfig{Target: endereco, Method: "pedidos.OrderService.CreateOrder", Message: `{"id":"a1","price":10}`, ProtoFiles: []string{arquivo}}},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			config := Config{GRPC: tt.config, Requests: 20, Concurrency: 4}
+			if err := config.Validate(); err != nil {
+				t.Fatalf("Erro inesperado na validação: %v", err)
+			}
+
+			report := Run(context.Background(), config)
+			if report.Protocol != ProtocolGRPC {
+				t.Errorf("Esperado protocolo gRPC, obtido %q", report.Protocol)
+			}
+			if report.SuccessCount != 20 || report.StatusCodes[int(codes.OK)] != 20 || report.Non2xxCount != 0 {
+				t.Errorf("Esperado 20 chamadas OK, obtido %d (%v)", report.SuccessCount, report.StatusCodes)
+			}
+			if report.Latency.Total != 20 || report.BytesReceived == 0 {
+				t.Errorf("Esperado latência e bytes de 20 respostas, obtido %d amostras e %d bytes",
+					report.Latency.Total, report.BytesReceived)
+			}
+		})
+	}
+}
+
+func TestRun_GRPCCodigosDeStatus(t *testing.T) {
+	endereco, _ := novoServidorGRPC(t)
+
+	config := Config{
+		GRPC:        &GRPCConfig{Target: endereco, Method: "pedidos.OrderService/CreateOrder", Message: `{"price":-1}`},
+		Requests:    5,
+		Concurrency: 1,
+	}
+	report := Run(context.Background(), config)
+
+	// Códigos gRPC no lugar dos códigos HTTP
+	if report.StatusCodes[int(codes.InvalidArgument)] != 5 || report.SuccessCount != 0 || report.Non2xxCount != 5 {
+		t.Errorf("Esperado 5 chamadas InvalidArgument, obtido %v", report.StatusCodes)
+	}
+}
+
+func TestGRPCConfig_Validacao(t *testing.T) {
+	endereco, arquivo := novoServidorGRPC(t)
+
+	invalidas := map[string]Config{
+		"sem método":         {GRPC: &GRPCConfig{Target: endereco}, Requests: 1, Concurrency: 1},
+		"método inválido":    {GRPC: &GRPCConfig{Target: endereco, Method: "CreateOrder"}, Requests: 1, Concurrency: 1},
+		"método inexistente": {GRPC: &GRPCConfig{Target: endereco, Method: "pedidos.OrderService/Delete", ProtoFiles: []string{arquivo}}, Requests: 1, Concurrency: 1},
+		"streaming":          {GRPC: &GRPCConfig{Target: endereco, Method: "pedidos.OrderService/ListOrders", ProtoFiles: []string{arquivo}}, Requests: 1, Concurrency: 1},
+		"mensagem inválida":  {GRPC: &GRPCConfig{Target: endereco, Method: "pedidos.OrderService/CreateOrder", Message: `{"preco":1}`, ProtoFiles: []string{arquivo}}, Requests: 1, Concurrency: 1},
+		"JSON inválido":      {GRPC: &GRPCConfig{Target: endereco, Method: "pedidos.OrderService/CreateOrder", Message: `{"price":`}, Requests: 1, Concurrency: 1},
+		"com URL":            {RequestSpec: RequestSpec{URL: "http://localhost"}, GRPC: &GRPCConfig{Target: endereco, Method: "pedidos.OrderService/CreateOrder"}, Requests: 1, Concurrency: 1},
+	}
+	for nome, config := range invalidas {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: esperado erro de validação", nome)
+		}
+	}
+}
+
+func TestRun_GRPCReflexaoFalha(t *testing.T) {
+	endereco, _ := novoServidorGRPC(t)
+
+	// A validação não acessa a rede: o servidor inexistente não é consultado
+	if err := (&Config{GRPC: &GRPCConfig{Target: "127.0.0.1:1", Method: "pedidos.OrderService/CreateOrder"}, Requests: 1, Concurrency: 1}).Validate(); err != nil {
+		t.Fatalf("Erro inesperado na validação: %v", err)
+	}
+
+	// A falha da reflexão aparece como erro em cada request
+	config := Config{GRPC: &GRPCConfig{Target: endereco, Method: "pedidos.Outro/CreateOrder"}, Requests: 3, Concurrency: 1}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Erro inesperado na validação: %v", err)
+	}
+	report := Run(context.Background(), config)
+
+	erro := report.Errors[ErrorInvalidRequest]
+	if erro.Count != 3 || len(erro.Samples) == 0 || !strings.Contains(erro.Samples[0], "pedidos.Outro") {
+		t.Errorf("Esperado 3 erros de reflexão, obtido %+v", report.Errors)
+	}
+}
@@ -0,0 +1,144 @@
+package stresstest
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// subBuckets define quantos sub-intervalos lineares existem em cada potência de dois.
+// Com 64 sub-intervalos o erro relativo de qualquer percentil fica abaixo de ~1,6%.
+const subBuckets = 64
+
+// Histogram acumula tempos de resposta em intervalos log-lineares (em microssegundos).
+// Ocupa memória proporcional ao número de intervalos distintos e não ao número de amostras,
+// e pode ser combinado com outros histogramas sem perda de informação.
+type Histogram struct {
+	Counts map[int]int64 `json:"counts,omitempty"` // Quantidade de amostras por intervalo
+	Total  int64         `json:"total"`            // Número total de amostras registradas
+	Sum    time.Duration `json:"sum"`              // Soma de todas as amostras (para a média)
+	Min    time.Duration `json:"min"`              // Menor amostra registrada
+	Max    time.Duration `json:"max"`              // Maior amostra registrada
+}
+
+// Record registra uma nova amostra de duração no histograma.
+func (h *Histogram) Record(d time.Duration) {
+	// Inicializa o mapa de forma preguiçosa para permitir o valor zero do tipo
+	if h.Counts == nil {
+		h.Counts = make(map[int]int64)
+	}
+	if d < 0 {
+		d = 0
+	}
+
+	h.Counts[bucketIndex(d.Microseconds())]++
+
+	// Atualiza mínimo e máximo
+	if h.Total == 0 || d < h.Min {
+		h.Min = d
+	}
+	if d > h.Max {
+		h.Max = d
+	}
+
+	h.Total++
+	h.Sum += d
+}
+
+// Merge soma as amostras de outro histograma neste.
+func (h *Histogram) Merge(other Histogram) {
+	if other.Total == 0 {
+		return
+	}
+	if h.Counts == nil {
+		h.Counts = make(map[int]int64, len(other.Counts))
+	}
+	for idx, count := range other.Counts {
+		h.Counts[idx] += count
+	}
+
+	if h.Total == 0 || other.Min < h.Min {
+		h.Min = other.Min
+	}
+	if other.Max > h.Max {
+		h.Max = other.Max
+	}
+
+	h.Total += other.Total
+	h.Sum += other.Sum
+}
+
+// Mean retorna a média aritmética das amostras registradas.
+func (h *Histogram) Mean() time.Duration {
+	if h.Total == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Total)
+}
+
+// Percentile retorna o valor aproximado do percentil p (entre 0 e 100).
+// O valor retornado é o ponto médio do intervalo onde o percentil se encontra,
+// limitado pelos valores mínimo e máximo realmente observados.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.Total == 0 {
+		return 0
+	}
+
+	// Posição (1-based) da amostra que corresponde ao percentil
+	rank := int64(math.Ceil(p / 100 * float64(h.Total)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	// Percorre os intervalos em ordem crescente acumulando as contagens
+	indexes := make([]int, 0, len(h.Counts))
+	for idx := range h.Counts {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+
+	var seen int64
+	for _, idx := range indexes {
+		seen += h.Counts[idx]
+		if seen >= rank {
+			return h.clamp(bucketMidpoint(idx))
+		}
+	}
+	return h.Max
+}
+
+// clamp limita um valor estimado ao intervalo [Min, Max] observado.
+func (h *Histogram) clamp(d time.Duration) time.Duration {
+	if d < h.Min {
+		return h.Min
+	}
+	if d > h.Max {
+		return h.Max
+	}
+	return d
+}
+
+// bucketIndex calcula o índice do intervalo onde o valor (em microssegundos) deve ser contado.
+// Valores menores que subBuckets têm intervalos exatos; acima disso cada potência de dois
+// é dividida em subBuckets intervalos de mesmo tamanho.
+func bucketIndex(v int64) int {
+	if v < subBuckets {
+		return int(v)
+	}
+	// Deslocamento necessário para que v>>shift fique em [subBuckets, 2*subBuckets)
+	shift := bits.Len64(uint64(v)) - bits.Len64(subBuckets)
+	return subBuckets + shift*subBuckets + int(v>>shift) - subBuckets
+}
+
+// bucketMidpoint retorna o valor central (como duração) de um intervalo do histograma.
+func bucketMidpoint(idx int) time.Duration {
+	if idx < subBuckets {
+		return time.Duration(idx) * time.Microsecond
+	}
+	shift := (idx - subBuckets) / subBuckets
+	mantissa := int64((idx-subBuckets)%subBuckets + subBuckets)
+	lower := mantissa << shift
+	upper := (mantissa + 1) << shift
+	return time.Duration((lower+upper)/2) * time.Microsecond
+}
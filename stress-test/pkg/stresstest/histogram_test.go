@@ -0,0 +1,58 @@
+package stresstest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogram_Percentis(t *testing.T) {
+	var h Histogram
+	for i := 1; i <= 1000; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	// Os percentis devem ficar dentro do erro relativo dos intervalos (~1,6%)
+	casos := map[float64]time.Duration{
+		50: 500 * time.Millisecond,
+		95: 950 * time.Millisecond,
+		99: 990 * time.Millisecond,
+	}
+	for p, esperado := range casos {
+		obtido := h.Percentile(p)
+		erro := float64(obtido-esperado) / float64(esperado)
+		if erro < -0.02 || erro > 0.02 {
+			t.Errorf("p%.0f incorreto: esperado ~%v, obtido %v", p, esperado, obtido)
+		}
+	}
+
+	if h.Min != time.Millisecond || h.Max != time.Second {
+		t.Errorf("Mínimo/máximo incorretos: obtido %v/%v", h.Min, h.Max)
+	}
+	if h.Mean() != 500500*time.Microsecond {
+		t.Errorf("Média incorreta: obtido %v", h.Mean())
+	}
+}
+
+func TestHistogram_Merge(t *testing.T) {
+	var a, b, todos Histogram
+	for i := 1; i <= 100; i++ {
+		d := time.Duration(i) * time.Millisecond
+		todos.Record(d)
+		if i%2 == 0 {
+			a.Record(d)
+		} else {
+			b.Record(d)
+		}
+	}
+
+	a.Merge(b)
+
+	if a.Total != todos.Total || a.Min != todos.Min || a.Max != todos.Max {
+		t.Fatalf("Merge incorreto: total %d, mín %v, máx %v", a.Total, a.Min, a.Max)
+	}
+	for _, p := range []float64{50, 90, 99} {
+		if a.Percentile(p) != todos.Percentile(p) {
+			t.Errorf("p%.0f diverge após merge: %v != %v", p, a.Percentile(p), todos.Percentile(p))
+		}
+	}
+}
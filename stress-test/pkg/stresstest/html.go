@@ -0,0 +1,394 @@
+package stresstest
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// htmlTemplate é o modelo do relatório HTML. Estilos e gráficos (SVG) ficam embutidos no
+// arquivo gerado, que pode ser aberto sem acesso à rede.
+//
+//go:embed report.html
+var htmlTemplate string
+
+// Dimensões dos gráficos de linha (em unidades do SVG)
+const (
+	chartWidth  = 860
+	chartHeight = 260
+	chartLeft   = 70 // Espaço para os rótulos do eixo Y
+	chartRight  = 20
+	chartTop    = 16
+	chartBottom = 30 // Espaço para os rótulos do eixo X
+)
+
+// maxTimelineRows limita as linhas da tabela por intervalo; intervalos vizinhos são agrupados.
+const maxTimelineRows = 60
+
+// chartPalette são as cores usadas nas séries e fatias dos gráficos.
+var chartPalette = []string{"#2563eb", "#16a34a", "#dc2626", "#d97706", "#7c3aed", "#0891b2", "#db2777", "#65a30d"}
+
+// htmlPercentiles são os percentis exibidos na tabela de latência do relatório HTML.
+var htmlPercentiles = []float64{50, 75, 90, 95, 99, 99.9}
+
+// WriteHTMLReport escreve o relatório como uma página HTML autocontida, com gráficos de latência
+// e de throughput ao longo do tempo, distribuição dos códigos de status e tabelas de percentis.
+func WriteHTMLReport(w io.Writer, report Report) error {
+	tmpl, err := template.New("report").Parse(htmlTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, newHTMLReport(report, time.Now()))
+}
+
+// htmlReport contém os dados do relatório já formatados para o modelo HTML.
+type htmlReport struct {
+	Generated   string            // Data e hora da geração
+	Protocol    string            // Protocolo testado (vazio para HTTP)
+	Aborted     string            // Motivo da interrupção (relatório parcial)
+	Summary     []htmlMetric      // Métricas principais exibidas em destaque
+	Percentiles []htmlMetric      // Tabela de percentis da latência
+	Latency     *lineChart        // Latência ao longo do tempo (p50, p95 e p99)
+	Throughput  *lineChart        // Requests por segundo ao longo do tempo
+	Status      []pieSlice        // Distribuição dos códigos de status
+	Errors      []htmlMetric      // Erros de transporte por categoria
+	Stages      []htmlStageRow    // Métricas por estágio do perfil de carga
+	Steps       []htmlStepRow     // Métricas por passo do fluxo
+	Timeline    []htmlTimelineRow // Métricas por intervalo de tempo
+}
+
+// htmlMetric é um par nome/valor exibido no relatório.
+type htmlMetric struct {
+	Name  string
+	Value string
+}
+
+// htmlStageRow é uma linha da tabela de estágios.
+type htmlStageRow struct {
+	Name, Target, Requests, RPS, P50, P95, P99, Errors string
+}
+
+// htmlStepRow é uma linha da tabela de passos do fluxo.
+type htmlStepRow struct {
+	Name, Requests, P50, P95, P99, Errors, Non2xx, Failed string
+}
+
+// htmlTimelineRow é uma linha da tabela por intervalo de tempo.
+type htmlTimelineRow struct {
+	Interval, Requests, RPS, Errors, P50, P95, P99 string
+}
+
+// newHTMLReport prepara os dados do relatório para o modelo HTML.
+func newHTMLReport(report Report, now time.Time) htmlReport {
+	view := htmlReport{
+		Generated: now.Format("02/01/2006 15:04:05"),
+		Protocol:  report.Protocol,
+		Aborted:   report.Aborted,
+	}
+
+	// Métricas principais
+	rps := 0.0
+	if report.TotalTime > 0 {
+		rps = float64(report.TotalRequests) / report.TotalTime.Seconds()
+	}
+	view.Summary = []htmlMetric{
+		{"Tempo total", round(report.TotalTime).String()},
+		{"Requests", fmt.Sprint(report.TotalRequests)},
+		{"Sucesso", fmt.Sprintf("%d (%.1f%%)", report.SuccessCount, ratio(report.SuccessCount, report.TotalRequests)*100)},
+		{"Erros de transporte", fmt.Sprintf("%d (%.2f%%)", report.ErrorCount, ratio(report.ErrorCount, report.TotalRequests)*100)},
+		{"Requests por segundo", fmt.Sprintf("%.2f", rps)},
+		{"Latência média", round(report.Latency.Mean()).String()},
+	}
+	if report.AssertionFailedCount > 0 {
+		view.Summary = append(view.Summary, htmlMetric{"Reprovadas nas asserções", fmt.Sprint(report.AssertionFailedCount)})
+	}
+	if report.Iterations > 0 {
+		view.Summary = append(view.Summary, htmlMetric{"Iterações concluídas", fmt.Sprintf("%d de %d (%.1f%%)",
+			report.CompletedIterations, report.Iterations, ratio(report.CompletedIterations, report.Iterations)*100)})
+	}
+
+	// Percentis da latência
+	if report.Latency.Total > 0 {
+		view.Percentiles = append(view.Percentiles, htmlMetric{"mín", round(report.Latency.Min).String()})
+		for _, p := range htmlPercentiles {
+			view.Percentiles = append(view.Percentiles, htmlMetric{
+				"p" + strings.TrimSuffix(fmt.Sprintf("%g", p), ".0"), round(report.Latency.Percentile(p)).String(),
+			})
+		}
+		view.Percentiles = append(view.Percentiles, htmlMetric{"máx", round(report.Latency.Max).String()})
+	}
+
+	// Gráficos construídos a partir da linha do tempo
+	if len(report.Timeline) > 0 {
+		view.Latency = latencyChart(report.Timeline)
+		view.Throughput = throughputChart(report.Timeline, report.TotalTime)
+		view.Timeline = timelineRows(report.Timeline, report.TotalTime)
+	}
+	view.Status = statusSlices(report.StatusCodes, report.Protocol)
+
+	// Erros por categoria, do mais frequente para o menos frequente
+	for kind, stat := range report.Errors {
+		view.Errors = append(view.Errors, htmlMetric{kind, fmt.Sprint(stat.Count)})
+	}
+	sort.Slice(view.Errors, func(i, j int) bool {
+		a, b := report.Errors[view.Errors[i].Name].Count, report.Errors[view.Errors[j].Name].Count
+		if a != b {
+			return a > b
+		}
+		return view.Errors[i].Name < view.Errors[j].Name
+	})
+
+	for i, stage := range report.Stages {
+		view.Stages = append(view.Stages, htmlStageRow{
+			Name:     stageLabel(stage.Stage, i),
+			Target:   fmt.Sprint(stage.Target),
+			Requests: fmt.Sprint(stage.TotalRequests),
+			RPS:      fmt.Sprintf("%.2f", float64(stage.TotalRequests)/stage.Duration.Seconds()),
+			P50:      round(stage.Latency.Percentile(50)).String(),
+			P95:      round(stage.Latency.Percentile(95)).String(),
+			P99:      round(stage.Latency.Percentile(99)).String(),
+			Errors:   fmt.Sprint(stage.ErrorCount),
+		})
+	}
+
+	for _, step := range report.Steps {
+		view.Steps = append(view.Steps, htmlStepRow{
+			Name:     step.Name,
+			Requests: fmt.Sprint(step.TotalRequests),
+			P50:      round(step.Latency.Percentile(50)).String(),
+			P95:      round(step.Latency.Percentile(95)).String(),
+			P99:      round(step.Latency.Percentile(99)).String(),
+			Errors:   fmt.Sprint(step.ErrorCount),
+			Non2xx:   fmt.Sprint(step.Non2xxCount),
+			Failed:   fmt.Sprint(step.AssertionFailedCount),
+		})
+	}
+
+	return view
+}
+
+// latencyChart monta o gráfico de p50, p95 e p99 da latência por intervalo.
+func latencyChart(timeline []TimelineBucket) *lineChart {
+	series := []chartSeries{{Name: "p50"}, {Name: "p95"}, {Name: "p99"}}
+	for _, bucket := range timeline {
+		series[0].Values = append(series[0].Values, float64(bucket.Latency.Percentile(50)))
+		series[1].Values = append(series[1].Values, float64(bucket.Latency.Percentile(95)))
+		series[2].Values = append(series[2].Values, float64(bucket.Latency.Percentile(99)))
+	}
+	return newLineChart(series, func(v float64) string { return round(time.Duration(v)).String() })
+}
+
+// throughputChart monta o gráfico de requests (e erros) por segundo em cada intervalo.
+func throughputChart(timeline []TimelineBucket, total time.Duration) *lineChart {
+	series := []chartSeries{{Name: "req/s"}, {Name: "erros/s"}}
+	for _, bucket := range timeline {
+		width := bucketWidth(bucket.Start, TimelineInterval, total)
+		series[0].Values = append(series[0].Values, float64(bucket.Requests)/width.Seconds())
+		series[1].Values = append(series[1].Values, float64(bucket.Errors)/width.Seconds())
+	}
+	return newLineChart(series, func(v float64) string { return fmt.Sprintf("%.0f", v) })
+}
+
+// bucketWidth retorna a duração efetiva de um intervalo: o último termina junto com o teste.
+func bucketWidth(start, width, total time.Duration) time.Duration {
+	if total > start && total-start < width {
+		return total - start
+	}
+	return width
+}
+
+// timelineRows agrupa os intervalos da linha do tempo em no máximo maxTimelineRows linhas.
+func timelineRows(timeline []TimelineBucket, total time.Duration) []htmlTimelineRow {
+	group := (len(timeline) + maxTimelineRows - 1) / maxTimelineRows
+
+	var rows []htmlTimelineRow
+	for start := 0; start < len(timeline); start += group {
+		end := min(start+group, len(timeline))
+
+		var merged TimelineBucket
+		for _, bucket := range timeline[start:end] {
+			merged.merge(bucket)
+		}
+
+		width := bucketWidth(timeline[start].Start, time.Duration(end-start)*TimelineInterval, total)
+		rows = append(rows, htmlTimelineRow{
+			Interval: fmt.Sprintf("%v – %v", timeline[start].Start, round(timeline[start].Start+width)),
+			Requests: fmt.Sprint(merged.Requests),
+			RPS:      fmt.Sprintf("%.2f", float64(merged.Requests)/width.Seconds()),
+			Errors:   fmt.Sprint(merged.Errors),
+			P50:      round(merged.Latency.Percentile(50)).String(),
+			P95:      round(merged.Latency.Percentile(95)).String(),
+			P99:      round(merged.Latency.Percentile(99)).String(),
+		})
+	}
+	return rows
+}
+
+// chartSeries é uma série de valores de um gráfico de linha, um por intervalo da linha do tempo.
+type chartSeries struct {
+	Name   string
+	Values []float64
+}
+
+// lineChart contém os elementos SVG de um gráfico de linha já posicionados.
+type lineChart struct {
+	Width, Height int
+	Left, Right   int // Limites horizontais da área de plotagem
+	Top, Bottom   int // Limites verticais da área de plotagem
+	Lines         []chartLine
+	YTicks        []chartTick
+	XTicks        []chartTick
+}
+
+// chartLine é uma série já convertida em pontos do SVG.
+type chartLine struct {
+	Name   string
+	Color  string
+	Points string // Pontos da polyline no formato "x,y x,y ..."
+}
+
+// chartTick é uma marcação de eixo com a sua posição e rótulo.
+type chartTick struct {
+	Pos   float64
+	Label string
+}
+
+// newLineChart posiciona as séries na área do gráfico. O eixo X é o tempo do teste e o eixo Y
+// vai de zero até um valor "redondo" acima do maior valor das séries.
+func newLineChart(series []chartSeries, format func(float64) string) *lineChart {
+	chart := &lineChart{
+		Width: chartWidth, Height: chartHeight,
+		Left: chartLeft, Right: chartWidth - chartRight,
+		Top: chartTop, Bottom: chartHeight - chartBottom,
+	}
+
+	points := 0
+	highest := 0.0
+	for _, s := range series {
+		points = max(points, len(s.Values))
+		for _, v := range s.Values {
+			highest = math.Max(highest, v)
+		}
+	}
+	top := niceCeil(highest)
+
+	plotWidth := float64(chart.Right - chart.Left)
+	plotHeight := float64(chart.Bottom - chart.Top)
+	x := func(i int) float64 {
+		if points <= 1 {
+			return float64(chart.Left) + plotWidth/2
+		}
+		return float64(chart.Left) + plotWidth*float64(i)/float64(points-1)
+	}
+	y := func(v float64) float64 {
+		return float64(chart.Bottom) - plotHeight*v/top
+	}
+
+	for i, s := range series {
+		var b strings.Builder
+		for j, v := range s.Values {
+			fmt.Fprintf(&b, "%.1f,%.1f ", x(j), y(v))
+		}
+		chart.Lines = append(chart.Lines, chartLine{
+			Name:   s.Name,
+			Color:  chartPalette[i%len(chartPalette)],
+			Points: strings.TrimSpace(b.String()),
+		})
+	}
+
+	// Cinco divisões no eixo Y e até oito marcações de tempo no eixo X
+	for i := 0; i <= 4; i++ {
+		v := top * float64(i) / 4
+		chart.YTicks = append(chart.YTicks, chartTick{Pos: y(v), Label: format(v)})
+	}
+	step := max(1, (points+7)/8)
+	for i := 0; i < points; i += step {
+		chart.XTicks = append(chart.XTicks, chartTick{Pos: x(i), Label: (time.Duration(i) * TimelineInterval).String()})
+	}
+
+	return chart
+}
+
+// niceCeil arredonda v para cima até 1, 2, 2.5 ou 5 vezes uma potência de dez.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, factor := range []float64{1, 2, 2.5, 5, 10} {
+		if v <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// pieSlice é uma fatia do gráfico de pizza dos códigos de status.
+type pieSlice struct {
+	Label   string  // Código de status (com o nome no gRPC)
+	Count   int     // Quantidade de respostas
+	Percent float64 // Porcentagem do total
+	Color   string
+	Path    string // Caminho SVG da fatia (vazio quando a fatia ocupa o círculo inteiro)
+}
+
+// Centro e raio do gráfico de pizza (em unidades do SVG)
+const (
+	pieCenter = 110
+	pieRadius = 100
+)
+
+// statusSlices monta as fatias do gráfico de pizza, da menor para a maior ordem de código.
+func statusSlices(codes map[int]int, protocol string) []pieSlice {
+	sorted := make([]int, 0, len(codes))
+	total := 0
+	for code, count := range codes {
+		sorted = append(sorted, code)
+		total += count
+	}
+	sort.Ints(sorted)
+
+	var slices []pieSlice
+	angle := 0.0
+	for i, code := range sorted {
+		label := fmt.Sprint(code)
+		if protocol == ProtocolGRPC {
+			label += " " + grpcCodeName(code)
+		}
+
+		fraction := float64(codes[code]) / float64(total)
+		slice := pieSlice{
+			Label:   label,
+			Count:   codes[code],
+			Percent: fraction * 100,
+			Color:   chartPalette[i%len(chartPalette)],
+		}
+		if fraction < 1 {
+			slice.Path = arcPath(angle, angle+fraction*2*math.Pi)
+		}
+		angle += fraction * 2 * math.Pi
+		slices = append(slices, slice)
+	}
+	return slices
+}
+
+// arcPath descreve a fatia do círculo entre os ângulos informados (em radianos, a partir do topo).
+func arcPath(from, to float64) string {
+	point := func(angle float64) (float64, float64) {
+		return pieCenter + pieRadius*math.Sin(angle), pieCenter - pieRadius*math.Cos(angle)
+	}
+	x1, y1 := point(from)
+	x2, y2 := point(to)
+
+	large := 0
+	if to-from > math.Pi {
+		large = 1
+	}
+	return fmt.Sprintf("M%d,%d L%.2f,%.2f A%d,%d 0 %d 1 %.2f,%.2f Z",
+		pieCenter, pieCenter, x1, y1, pieRadius, pieRadius, large, x2, y2)
+}
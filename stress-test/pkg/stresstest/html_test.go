@@ -0,0 +1,131 @@
+package stresstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun_LinhaDoTempo(t *testing.T) {
+	server := novoServidorVazio(t)
+
+	report := Run(context.Background(), Config{RequestSpec: RequestSpec{URL: server.URL}, Requests: 200, Concurrency: 4})
+
+	if len(report.Timeline) == 0 {
+		t.Fatal("Esperado relatório com linha do tempo")
+	}
+	total := 0
+	for i, bucket := range report.Timeline {
+		if bucket.Start != time.Duration(i)*TimelineInterval {
+			t.Errorf("Intervalo %d: esperado início em %v, obtido %v", i, time.Duration(i)*TimelineInterval, bucket.Start)
+		}
+		total += bucket.Requests
+	}
+	if total != 200 {
+		t.Errorf("Esperado 200 requests na linha do tempo, obtido %d", total)
+	}
+}
+
+func TestTimeline_RecordEMerge(t *testing.T) {
+	var a []TimelineBucket
+	a = recordTimeline(a, 500*time.Millisecond, Result{StatusCode: 200, Duration: time.Millisecond})
+	a = recordTimeline(a, 2500*time.Millisecond, Result{Error: errors.New("falha")})
+
+	var b []TimelineBucket
+	b = recordTimeline(b, 100*time.Millisecond, Result{StatusCode: 500, Duration: time.Millisecond})
+
+	merged := mergeTimeline(a, b)
+	if len(merged) != 3 {
+		t.Fatalf("Esperado 3 intervalos, obtido %d", len(merged))
+	}
+	if merged[0].Requests != 2 || merged[0].Non2xx != 1 || merged[0].Latency.Total != 2 {
+		t.Errorf("Intervalo 0 incorreto: %+v", merged[0])
+	}
+	if merged[1].Requests != 0 || merged[2].Errors != 1 || merged[2].Latency.Total != 0 {
+		t.Errorf("Intervalos 1 e 2 incorretos: %+v %+v", merged[1], merged[2])
+	}
+}
+
+func TestStartTimeline(t *testing.T) {
+	start := time.Now()
+	tags := newTagSet()
+	accumulators := []*accumulator{newAccumulator(Config{}, start, tags), newAccumulator(Config{}, start, tags)}
+	stop := startTimeline(start, accumulators)
+
+	// Um request no primeiro intervalo e dois no segundo, vindos de workers diferentes
+	accumulators[0].add(Result{StatusCode: 200, Duration: time.Millisecond, Stage: -1})
+	time.Sleep(TimelineInterval + 200*time.Millisecond)
+	accumulators[0].add(Result{StatusCode: 500, Duration: time.Millisecond, Stage: -1})
+	accumulators[1].add(Result{Error: errors.New("falha"), Stage: -1})
+
+	timeline := stop()
+	if len(timeline) != 2 {
+		t.Fatalf("Esperado 2 intervalos, obtido %d: %+v", len(timeline), timeline)
+	}
+	if timeline[0].Requests != 1 || timeline[1].Requests != 2 || timeline[1].Non2xx != 1 || timeline[1].Errors != 1 {
+		t.Errorf("Intervalos incorretos: %+v", timeline)
+	}
+
+	// Os workers não guardam a linha do tempo: apenas o intervalo em andamento, já coletado
+	for i, acc := range accumulators {
+		if acc.pending.Requests != 0 {
+			t.Errorf("Worker %d: esperado intervalo coletado, restam %d requests", i, acc.pending.Requests)
+		}
+	}
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	report := Report{Stats: newStats(), TotalTime: 2500 * time.Millisecond, Aborted: "limite de 10 erros atingido"}
+	for i := 0; i < 30; i++ {
+		result := Result{StatusCode: 200, Duration: time.Duration(i+1) * time.Millisecond}
+		if i%10 == 0 {
+			result.StatusCode = 503
+		}
+		report.Stats.add(result)
+		report.Timeline = recordTimeline(report.Timeline, time.Duration(i)*80*time.Millisecond, result)
+	}
+	report.Stats.add(Result{Error: errors.New("connection refused")})
+
+	var buf bytes.Buffer
+	if err := WriteHTMLReport(&buf, report); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	html := buf.String()
+
+	esperados := []string{
+		"Relatório parcial", "limite de 10 erros atingido",
+		"Latência ao longo do tempo", "Requests por segundo ao longo do tempo",
+		"<polyline", "<path d=\"M110,110", " 503</td>", "p99.9", "Métricas por intervalo",
+	}
+	for _, esperado := range esperados {
+		if !strings.Contains(html, esperado) {
+			t.Errorf("Esperado %q no relatório HTML", esperado)
+		}
+	}
+
+	// O arquivo deve ser autocontido: nenhum recurso carregado da rede
+	for _, externo := range []string{"http://", "https://", "<script src", "<link"} {
+		if strings.Contains(html, externo) {
+			t.Errorf("Relatório HTML não deve referenciar recursos externos (%q)", externo)
+		}
+	}
+}
+
+func TestStatusSlices_StatusUnico(t *testing.T) {
+	slices := statusSlices(map[int]int{200: 10}, "")
+	if len(slices) != 1 || slices[0].Path != "" || slices[0].Percent != 100 {
+		t.Errorf("Esperado círculo completo para um único status, obtido %+v", slices)
+	}
+}
+
+func TestNiceCeil(t *testing.T) {
+	casos := map[float64]float64{0: 1, 0.7: 1, 3: 5, 12: 20, 230: 250, 1000: 1000, 4.2e6: 5e6}
+	for valor, esperado := range casos {
+		if obtido := niceCeil(valor); obtido != esperado {
+			t.Errorf("niceCeil(%v): esperado %v, obtido %v", valor, esperado, obtido)
+		}
+	}
+}
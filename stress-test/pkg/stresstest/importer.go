@@ -0,0 +1,626 @@
+package stresstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// curlOptions mapeia as opções do curl que recebem um argumento e alteram a requisição
+// para o nome canônico usado na interpretação.
+var curlOptions = map[string]string{
+	"-X":               "request",
+	"--request":        "request",
+	"-H":               "header",
+	"--header":         "header",
+	"-d":               "data",
+	"--data":           "data",
+	"--data-ascii":     "data",
+	"--data-binary":    "data-binary",
+	"--data-raw":       "data-raw",
+	"--data-urlencode": "data-urlencode",
+	"--json":           "json",
+	"-u":               "user",
+	"--user":           "user",
+	"--oauth2-bearer":  "bearer",
+	"-A":               "user-agent",
+	"--user-agent":     "user-agent",
+	"-e":               "referer",
+	"--referer":        "referer",
+	"-b":               "cookie",
+	"--cookie":         "cookie",
+	"--url":            "url",
+	"-F":               "form",
+	"--form":           "form",
+	"-T":               "upload",
+	"--upload-file":    "upload",
+}
+
+// curlIgnoredOptions são as opções do curl que recebem um argumento, mas não alteram a
+// requisição enviada (saída, timeouts, proxy, certificados...). São aceitas e descartadas.
+var curlIgnoredOptions = map[string]bool{
+	"-o": true, "--output": true, "-m": true, "--max-time": true, "--connect-timeout": true,
+	"-w": true, "--write-out": true, "--retry": true, "--retry-delay": true, "--retry-max-time": true,
+	"-x": true, "--proxy": true, "--resolve": true, "--connect-to": true, "--cacert": true,
+	"--capath": true, "-E": true, "--cert": true, "--key": true, "--cert-type": true,
+	"--key-type": true, "--ciphers": true, "-c": true, "--cookie-jar": true, "-D": true,
+	"--dump-header": true, "--limit-rate": true, "--max-redirs": true, "--interface": true,
+	"--proto": true, "--proto-redir": true, "-r": true, "--range": true, "-C": true,
+	"--continue-at": true, "--stderr": true, "--trace": true, "--trace-ascii": true,
+}
+
+// takesArgument informa se a opção do curl consome a palavra seguinte como argumento.
+func takesArgument(option string) bool {
+	_, ok := curlOptions[option]
+	return ok || curlIgnoredOptions[option]
+}
+
+// harSkippedHeaders são os cabeçalhos de uma exportação HAR que não são copiados para o cenário:
+// calculados pelo cliente HTTP a cada request ou dependentes da conexão original.
+var harSkippedHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"keep-alive":        true,
+	"accept-encoding":   true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+}
+
+// ParseCurl converte um comando curl (como o gerado por "Copy as cURL" nos navegadores) na
+// requisição equivalente: método, URL, cabeçalhos, corpo e autenticação.
+// Aspas simples e duplas, o formato $'...' e quebras de linha com "\" são aceitos.
+func ParseCurl(command string) (RequestSpec, error) {
+	var spec RequestSpec
+
+	words, err := splitShellWords(command)
+	if err != nil {
+		return spec, err
+	}
+	if len(words) == 0 || words[0] != "curl" {
+		return spec, fmt.Errorf("comando deve começar com curl")
+	}
+
+	var (
+		data    []string // Partes do corpo, unidas com "&" como faz o curl
+		getData bool     // -G: envia os dados como parâmetros de query
+		head    bool     // -I: requisição HEAD
+	)
+
+	// apply interpreta uma opção já associada ao seu argumento
+	apply := func(option, value string) error {
+		switch curlOptions[option] {
+		case "request":
+			spec.Method = strings.ToUpper(value)
+		case "header":
+			name, content, err := ParseHeader(value)
+			if err != nil {
+				// "Nome;" envia o cabeçalho vazio no curl
+				if name, ok := strings.CutSuffix(strings.TrimSpace(value), ";"); ok && name != "" {
+					setHeader(&spec, name, "")
+					return nil
+				}
+				return err
+			}
+			// "Nome:" remove um cabeçalho interno do curl: não há o que enviar
+			if content != "" {
+				setHeader(&spec, name, content)
+			}
+		case "data":
+			content, err := curlData(value, true)
+			if err != nil {
+				return err
+			}
+			data = append(data, content)
+		case "data-binary":
+			content, err := curlData(value, false)
+			if err != nil {
+				return err
+			}
+			data = append(data, content)
+		case "data-raw":
+			data = append(data, value)
+		case "data-urlencode":
+			data = append(data, urlEncodeData(value))
+		case "json":
+			content, err := curlData(value, false)
+			if err != nil {
+				return err
+			}
+			data = append(data, content)
+			if !hasHeader(spec, "Content-Type") {
+				setHeader(&spec, "Content-Type", "application/json")
+			}
+			if !hasHeader(spec, "Accept") {
+				setHeader(&spec, "Accept", "application/json")
+			}
+		case "user":
+			credentials, err := ParseBasicAuth(value)
+			if err != nil {
+				return err
+			}
+			spec.BasicAuth = credentials
+		case "bearer":
+			spec.BearerToken = value
+		case "user-agent":
+			setHeader(&spec, "User-Agent", value)
+		case "referer":
+			setHeader(&spec, "Referer", value)
+		case "cookie":
+			// Sem "=" o argumento é um arquivo de cookies, que não é importado
+			if strings.Contains(value, "=") {
+				setHeader(&spec, "Cookie", value)
+			}
+		case "url":
+			return setCurlURL(&spec, value)
+		case "form":
+			return fmt.Errorf("formulários multipart (%s) não são suportados", option)
+		case "upload":
+			return fmt.Errorf("envio de arquivo (%s) não é suportado", option)
+		}
+		return nil
+	}
+
+	args := words[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			// Opção longa: o argumento, quando existe, é a palavra seguinte
+			switch arg {
+			case "--get":
+				getData = true
+				continue
+			case "--head":
+				head = true
+				continue
+			}
+			if !takesArgument(arg) {
+				// Opções longas desconhecidas são tratadas como flags sem argumento
+				continue
+			}
+			if i+1 >= len(args) {
+				return spec, fmt.Errorf("opção %s sem argumento", arg)
+			}
+			i++
+			if err := apply(arg, args[i]); err != nil {
+				return spec, err
+			}
+
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// Opções curtas podem ser agrupadas (-sSL) e ter o argumento colado (-XPOST)
+			for j := 1; j < len(arg); j++ {
+				option := "-" + string(arg[j])
+				switch option {
+				case "-G":
+					getData = true
+					continue
+				case "-I":
+					head = true
+					continue
+				}
+				if !takesArgument(option) {
+					continue
+				}
+				value := arg[j+1:]
+				if value == "" {
+					if i+1 >= len(args) {
+						return spec, fmt.Errorf("opção %s sem argumento", option)
+					}
+					i++
+					value = args[i]
+				}
+				if err := apply(option, value); err != nil {
+					return spec, err
+				}
+				break
+			}
+
+		default:
+			if err := setCurlURL(&spec, arg); err != nil {
+				return spec, err
+			}
+		}
+	}
+
+	if spec.URL == "" {
+		return spec, fmt.Errorf("comando curl sem URL")
+	}
+
+	// Com -G os dados viram parâmetros de query; caso contrário formam o corpo
+	body := strings.Join(data, "&")
+	if getData && len(data) > 0 {
+		separator := "?"
+		if strings.Contains(spec.URL, "?") {
+			separator = "&"
+		}
+		spec.URL += separator + body
+	} else if len(data) > 0 {
+		spec.Body = body
+		if !hasHeader(spec, "Content-Type") {
+			spec.ContentType = "application/x-www-form-urlencoded"
+		}
+	}
+
+	// Método implícito, como no curl: HEAD com -I, POST quando há corpo e GET nos demais casos
+	if spec.Method == "" {
+		switch {
+		case head:
+			spec.Method = "HEAD"
+		case spec.Body != "":
+			spec.Method = "POST"
+		default:
+			spec.Method = "GET"
+		}
+	}
+
+	return spec, nil
+}
+
+// setCurlURL define a URL da requisição, assumindo http:// quando o esquema é omitido.
+func setCurlURL(spec *RequestSpec, value string) error {
+	if spec.URL != "" {
+		return fmt.Errorf("comando curl com mais de uma URL: %s e %s", spec.URL, value)
+	}
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+	spec.URL = value
+	return nil
+}
+
+// curlData retorna o conteúdo de um argumento -d/--data-binary, lendo o arquivo quando começa
+// com "@". Assim como o curl, -d remove as quebras de linha do arquivo.
+func curlData(value string, stripNewlines bool) (string, error) {
+	path, ok := strings.CutPrefix(value, "@")
+	if !ok {
+		return value, nil
+	}
+	if path == "-" {
+		return "", fmt.Errorf("corpo lido da entrada padrão (@-) não é suportado")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler corpo da requisição: %w", err)
+	}
+	if stripNewlines {
+		return strings.NewReplacer("\r", "", "\n", "").Replace(string(content)), nil
+	}
+	return string(content), nil
+}
+
+// urlEncodeData codifica um argumento --data-urlencode nos formatos "conteúdo", "=conteúdo"
+// e "nome=conteúdo" (apenas o conteúdo é codificado).
+func urlEncodeData(value string) string {
+	name, content, ok := strings.Cut(value, "=")
+	if !ok {
+		return url.QueryEscape(value)
+	}
+	if name == "" {
+		return url.QueryEscape(content)
+	}
+	return name + "=" + url.QueryEscape(content)
+}
+
+// setHeader define um cabeçalho da requisição, criando o mapa quando necessário.
+func setHeader(spec *RequestSpec, name, value string) {
+	if spec.Headers == nil {
+		spec.Headers = make(map[string]string)
+	}
+	spec.Headers[name] = value
+}
+
+// hasHeader informa se a requisição já possui o cabeçalho (sem diferenciar maiúsculas).
+func hasHeader(spec RequestSpec, name string) bool {
+	for key := range spec.Headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitShellWords separa um comando em palavras seguindo as regras de aspas do shell:
+// aspas simples preservam o texto literal, aspas duplas aceitam escapes com "\", $'...'
+// interpreta sequências como \n e \t, e "\" seguido de quebra de linha continua o comando.
+func splitShellWords(command string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool // Distingue uma palavra vazia ('') da ausência de palavra
+	)
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				// Continuação de linha (inclusive com \r\n) não produz caractere
+				if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+					i++
+				}
+				if runes[i] != '\n' && runes[i] != '\r' {
+					current.WriteRune(runes[i])
+					inWord = true
+				}
+			}
+
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("aspas simples não fechadas")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			inWord = true
+			i = end
+
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			end, err := ansiCQuoted(runes, i+2, &current)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i = end
+
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				// Dentro de aspas duplas "\" só escapa $, `, ", \ e a quebra de linha
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("$`\"\\\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("aspas duplas não fechadas")
+			}
+			inWord = true
+
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// indexRune retorna a posição da primeira ocorrência de r a partir de start, ou -1.
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// ansiCQuoted interpreta o conteúdo de $'...' a partir de start, escrevendo o resultado em out.
+// Retorna a posição da aspa que fecha o texto.
+func ansiCQuoted(runes []rune, start int, out *strings.Builder) (int, error) {
+	escapes := map[rune]string{'n': "\n", 't': "\t", 'r': "\r", '\\': "\\", '\'': "'", '"': "\"", '0': "\x00"}
+
+	for i := start; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\'':
+			return i, nil
+		case runes[i] == '\\' && i+1 < len(runes):
+			i++
+			if value, ok := escapes[runes[i]]; ok {
+				out.WriteString(value)
+				continue
+			}
+			// \xHH, \uHHHH e \UHHHHHHHH
+			if digits := map[rune]int{'x': 2, 'u': 4, 'U': 8}[runes[i]]; digits > 0 {
+				end := min(i+1+digits, len(runes))
+				code, err := strconv.ParseUint(string(runes[i+1:end]), 16, 32)
+				if err != nil {
+					return 0, fmt.Errorf("escape inválido \\%s", string(runes[i:end]))
+				}
+				if runes[i] == 'x' {
+					out.WriteByte(byte(code))
+				} else {
+					out.WriteRune(rune(code))
+				}
+				i = end - 1
+				continue
+			}
+			out.WriteRune('\\')
+			out.WriteRune(runes[i])
+		default:
+			out.WriteRune(runes[i])
+		}
+	}
+	return 0, fmt.Errorf("aspas $'...' não fechadas")
+}
+
+// harFile é o subconjunto de um arquivo HAR (HTTP Archive) usado na importação.
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request harRequest `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// harRequest é uma requisição registrada no HAR.
+type harRequest struct {
+	Method   string         `json:"method"`
+	URL      string         `json:"url"`
+	Headers  []harNameValue `json:"headers"`
+	PostData *harPostData   `json:"postData"`
+}
+
+// harPostData é o corpo de uma requisição registrada no HAR.
+type harPostData struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text"`
+	Params   []harNameValue `json:"params"`
+}
+
+// harNameValue é um par nome/valor (cabeçalho ou parâmetro de formulário) do HAR.
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ImportHAR converte uma exportação HAR do navegador em um cenário com uma requisição por
+// combinação distinta de método, URL e corpo. Requisições repetidas são agrupadas e o número
+// de ocorrências vira o peso, preservando a proporção do tráfego gravado.
+// Quando include é informado, apenas as URLs que satisfazem a expressão são importadas.
+func ImportHAR(r io.Reader, include *regexp.Regexp) (*Scenario, error) {
+	var har harFile
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar HAR: %w", err)
+	}
+
+	scenario := &Scenario{}
+	index := make(map[string]int) // Chave da requisição -> posição no cenário
+	names := make(map[string]int) // Nome -> quantas requisições já o utilizam
+	for _, entry := range har.Log.Entries {
+		request := entry.Request
+
+		// Ignora data:, blob:, extensões do navegador e URLs fora do filtro
+		if !strings.HasPrefix(request.URL, "http://") && !strings.HasPrefix(request.URL, "https://") {
+			continue
+		}
+		if include != nil && !include.MatchString(request.URL) {
+			continue
+		}
+
+		spec := harRequestSpec(request)
+		key := spec.Method + " " + spec.URL + "\n" + spec.Body
+		if position, ok := index[key]; ok {
+			scenario.Requests[position].Weight++
+			continue
+		}
+		index[key] = len(scenario.Requests)
+
+		// Nome legível (método e caminho), numerado quando se repete
+		name := spec.Method + " " + requestPath(spec.URL)
+		if names[name]++; names[name] > 1 {
+			name = fmt.Sprintf("%s #%d", name, names[name])
+		}
+		scenario.Requests = append(scenario.Requests, ScenarioRequest{
+			Name:        name,
+			Weight:      1,
+			RequestSpec: escapeTemplates(spec),
+		})
+	}
+
+	if len(scenario.Requests) == 0 {
+		return nil, fmt.Errorf("nenhuma requisição HTTP encontrada no HAR")
+	}
+
+	// Peso 1 é o padrão: omite no arquivo gerado
+	for i := range scenario.Requests {
+		if scenario.Requests[i].Weight == 1 {
+			scenario.Requests[i].Weight = 0
+		}
+	}
+	return scenario, nil
+}
+
+// harRequestSpec converte uma requisição do HAR na especificação equivalente.
+func harRequestSpec(request harRequest) RequestSpec {
+	spec := RequestSpec{Method: strings.ToUpper(request.Method), URL: request.URL}
+
+	for _, header := range request.Headers {
+		// Pseudo-cabeçalhos do HTTP/2 (:authority, :path...) e cabeçalhos de conexão
+		if strings.HasPrefix(header.Name, ":") || harSkippedHeaders[strings.ToLower(header.Name)] {
+			continue
+		}
+		setHeader(&spec, header.Name, header.Value)
+	}
+
+	if post := request.PostData; post != nil {
+		spec.Body = post.Text
+		// Formulários podem vir apenas como lista de parâmetros
+		if spec.Body == "" && len(post.Params) > 0 {
+			values := url.Values{}
+			for _, param := range post.Params {
+				values.Add(param.Name, param.Value)
+			}
+			spec.Body = values.Encode()
+		}
+		if post.MimeType != "" && !hasHeader(spec, "Content-Type") {
+			spec.ContentType = post.MimeType
+		}
+	}
+
+	return spec
+}
+
+// requestPath retorna o caminho da URL usado no nome da requisição importada.
+func requestPath(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Path == "" {
+		return "/"
+	}
+	return parsed.Path
+}
+
+// ImportCurl converte um comando curl em um cenário com uma única requisição, pronto para
+// receber pesos, feeds e templates.
+func ImportCurl(command string) (*Scenario, error) {
+	spec, err := ParseCurl(command)
+	if err != nil {
+		return nil, err
+	}
+	return &Scenario{Requests: []ScenarioRequest{{
+		Name:        spec.Method + " " + requestPath(spec.URL),
+		RequestSpec: escapeTemplates(spec),
+	}}}, nil
+}
+
+// escapeTemplates protege as marcações "{{" do conteúdo importado, que no cenário seriam
+// interpretadas como templates.
+func escapeTemplates(spec RequestSpec) RequestSpec {
+	escape := func(text string) string {
+		return strings.ReplaceAll(text, "{{", `{{"{{"}}`)
+	}
+
+	spec.URL = escape(spec.URL)
+	spec.Body = escape(spec.Body)
+	if spec.Headers != nil {
+		headers := make(map[string]string, len(spec.Headers))
+		for name, value := range spec.Headers {
+			headers[name] = escape(value)
+		}
+		spec.Headers = headers
+	}
+	return spec
+}
+
+// WriteScenario grava o cenário em YAML, no formato aceito por LoadScenario.
+func WriteScenario(w io.Writer, scenario *Scenario) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(scenario); err != nil {
+		return fmt.Errorf("erro ao gravar cenário: %w", err)
+	}
+	return encoder.Close()
+}
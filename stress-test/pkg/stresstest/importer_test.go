@@ -0,0 +1,208 @@
+package stresstest
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseCurl_CopiadoDoNavegador(t *testing.T) {
+	// Formato gerado pelo "Copy as cURL (bash)" do Chrome
+	comando := `curl 'https://api.exemplo.com/orders?page=2' \
+  -H 'accept: application/json' \
+  -H 'authorization: Bearer abc.def' \
+  -H 'content-type: application/json' \
+  --data-raw $'{"nome":"João","obs":"it\'s ok"}' \
+  --compressed`
+
+	spec, err := ParseCurl(comando)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if spec.Method != "POST" || spec.URL != "https://api.exemplo.com/orders?page=2" {
+		t.Errorf("Esperado POST na URL original, obtido %s %s", spec.Method, spec.URL)
+	}
+	if spec.Body != `{"nome":"João","obs":"it's ok"}` {
+		t.Errorf("Corpo interpretado incorretamente: %s", spec.Body)
+	}
+	if spec.Headers["authorization"] != "Bearer abc.def" || len(spec.Headers) != 3 {
+		t.Errorf("Cabeçalhos interpretados incorretamente: %v", spec.Headers)
+	}
+	// Com Content-Type nos cabeçalhos o padrão de formulário não é aplicado
+	if spec.ContentType != "" {
+		t.Errorf("Esperado Content-Type apenas nos cabeçalhos, obtido %q", spec.ContentType)
+	}
+}
+
+func TestParseCurl_Opcoes(t *testing.T) {
+	casos := []struct {
+		nome     string
+		comando  string
+		esperado RequestSpec
+	}{
+		{
+			nome:     "método colado e flags agrupadas",
+			comando:  `curl -sSL -XDELETE localhost:8080/item/1 -m 5`,
+			esperado: RequestSpec{Method: "DELETE", URL: "http://localhost:8080/item/1"},
+		},
+		{
+			nome:    "formulário com vários -d",
+			comando: `curl http://x/login -d user=ana -d "senha=a b"`,
+			esperado: RequestSpec{Method: "POST", URL: "http://x/login", Body: "user=ana&senha=a b",
+				ContentType: "application/x-www-form-urlencoded"},
+		},
+		{
+			nome:     "-G envia os dados na query",
+			comando:  `curl -G http://x/busca?a=1 --data-urlencode "q=café com leite"`,
+			esperado: RequestSpec{Method: "GET", URL: "http://x/busca?a=1&q=caf%C3%A9+com+leite"},
+		},
+		{
+			nome:    "autenticação, agente e -I",
+			comando: `curl -I -u ana:segredo -A "carga/1.0" --url http://x/`,
+			esperado: RequestSpec{Method: "HEAD", URL: "http://x/", BasicAuth: &BasicAuth{Username: "ana", Password: "segredo"},
+				Headers: map[string]string{"User-Agent": "carga/1.0"}},
+		},
+		{
+			nome:    "--json",
+			comando: `curl --json '{"a":1}' http://x/api`,
+			esperado: RequestSpec{Method: "POST", URL: "http://x/api", Body: `{"a":1}`,
+				Headers: map[string]string{"Content-Type": "application/json", "Accept": "application/json"}},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			spec, err := ParseCurl(caso.comando)
+			if err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			if spec.Method != caso.esperado.Method || spec.URL != caso.esperado.URL || spec.Body != caso.esperado.Body ||
+				spec.ContentType != caso.esperado.ContentType {
+				t.Errorf("Esperado %+v, obtido %+v", caso.esperado, spec)
+			}
+			if len(spec.Headers) != len(caso.esperado.Headers) {
+				t.Errorf("Esperado cabeçalhos %v, obtido %v", caso.esperado.Headers, spec.Headers)
+			}
+			for nome, valor := range caso.esperado.Headers {
+				if spec.Headers[nome] != valor {
+					t.Errorf("Cabeçalho %s: esperado %q, obtido %q", nome, valor, spec.Headers[nome])
+				}
+			}
+			if (caso.esperado.BasicAuth == nil) != (spec.BasicAuth == nil) ||
+				(spec.BasicAuth != nil && *spec.BasicAuth != *caso.esperado.BasicAuth) {
+				t.Errorf("Esperado autenticação %+v, obtido %+v", caso.esperado.BasicAuth, spec.BasicAuth)
+			}
+		})
+	}
+}
+
+func TestParseCurl_Invalido(t *testing.T) {
+	invalidos := []string{
+		`wget http://x`,
+		`curl -H 'accept: */*'`,
+		`curl 'http://x`,
+		`curl http://x http://y`,
+		`curl -F arquivo=@foto.png http://x`,
+		`curl http://x -H`,
+	}
+	for _, comando := range invalidos {
+		if _, err := ParseCurl(comando); err == nil {
+			t.Errorf("%s: esperado erro", comando)
+		}
+	}
+}
+
+// harExemplo simula uma exportação do navegador com tráfego repetido e recursos estáticos
+const harExemplo = `{"log": {"entries": [
+  {"request": {"method": "GET", "url": "https://loja.com/api/produtos?cat=1",
+    "headers": [{"name": ":authority", "value": "loja.com"}, {"name": "Accept", "value": "application/json"},
+                {"name": "Accept-Encoding", "value": "gzip"}, {"name": "Cookie", "value": "sessao=1"}]}},
+  {"request": {"method": "GET", "url": "https://loja.com/static/app.js", "headers": []}},
+  {"request": {"method": "GET", "url": "https://loja.com/api/produtos?cat=1", "headers": []}},
+  {"request": {"method": "POST", "url": "https://loja.com/api/carrinho", "headers": [],
+    "postData": {"mimeType": "application/json", "text": "{\"id\":7,\"tpl\":\"{{x}}\"}"}}},
+  {"request": {"method": "POST", "url": "https://loja.com/api/carrinho", "headers": [],
+    "postData": {"mimeType": "application/json", "text": "{\"id\":8}"}}},
+  {"request": {"method": "POST", "url": "https://loja.com/api/login", "headers": [],
+    "postData": {"mimeType": "application/x-www-form-urlencoded", "params": [{"name": "user", "value": "ana"}]}}},
+  {"request": {"method": "GET", "url": "data:image/png;base64,AAAA", "headers": []}}
+]}}`
+
+func TestImportHAR(t *testing.T) {
+	scenario, err := ImportHAR(strings.NewReader(harExemplo), regexp.MustCompile(`/api/`))
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	nomes := make([]string, len(scenario.Requests))
+	for i, request := range scenario.Requests {
+		nomes[i] = request.Name
+	}
+	esperados := "GET /api/produtos,POST /api/carrinho,POST /api/carrinho #2,POST /api/login"
+	if strings.Join(nomes, ",") != esperados {
+		t.Fatalf("Esperado requisições %s, obtido %s", esperados, strings.Join(nomes, ","))
+	}
+
+	produtos := scenario.Requests[0]
+	// Requisições repetidas viram peso
+	if produtos.Weight != 2 || scenario.Requests[1].Weight != 0 {
+		t.Errorf("Esperado peso 2 para a requisição repetida, obtido %d", produtos.Weight)
+	}
+	// Pseudo-cabeçalhos e cabeçalhos de conexão não são copiados
+	if len(produtos.Headers) != 2 || produtos.Headers["Cookie"] != "sessao=1" {
+		t.Errorf("Cabeçalhos importados incorretamente: %v", produtos.Headers)
+	}
+	// Marcações de template do conteúdo gravado são protegidas
+	if !strings.Contains(scenario.Requests[1].Body, `{{"{{"}}x}}`) || scenario.Requests[1].ContentType != "application/json" {
+		t.Errorf("Corpo importado incorretamente: %q (%s)", scenario.Requests[1].Body, scenario.Requests[1].ContentType)
+	}
+	if scenario.Requests[3].Body != "user=ana" {
+		t.Errorf("Esperado formulário montado a partir dos parâmetros, obtido %q", scenario.Requests[3].Body)
+	}
+
+	// O cenário gerado é válido e os templates protegidos reproduzem o corpo original
+	if err := scenario.validate(); err != nil {
+		t.Fatalf("Cenário importado inválido: %v", err)
+	}
+	spec, err := scenario.compiled[1].render(scenario.newTemplateData(1))
+	if err != nil || spec.Body != `{"id":7,"tpl":"{{x}}"}` {
+		t.Errorf("Esperado corpo original após renderizar, obtido %q (%v)", spec.Body, err)
+	}
+}
+
+func TestImportHAR_SemRequisicoes(t *testing.T) {
+	if _, err := ImportHAR(strings.NewReader(harExemplo), regexp.MustCompile(`/nada/`)); err == nil {
+		t.Error("Esperado erro quando nenhuma requisição é importada")
+	}
+	if _, err := ImportHAR(strings.NewReader("curl http://x"), nil); err == nil {
+		t.Error("Esperado erro para HAR inválido")
+	}
+}
+
+func TestWriteScenario_RecarregaOCenario(t *testing.T) {
+	scenario, err := ImportCurl(`curl -X PUT http://localhost:8000/order -H 'Content-Type: application/json' -d '{"id":1}'`)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	var saida bytes.Buffer
+	if err := WriteScenario(&saida, scenario); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	// Campos vazios são omitidos do arquivo gerado
+	if strings.Contains(saida.String(), "basic_auth") || strings.Contains(saida.String(), "weight") {
+		t.Errorf("Esperado apenas os campos preenchidos:\n%s", saida.String())
+	}
+
+	path := escreverArquivo(t, t.TempDir(), "cenario.yaml", saida.String())
+	carregado, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	request := carregado.Requests[0]
+	if request.Name != "PUT /order" || request.Method != "PUT" || request.Body != `{"id":1}` ||
+		request.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Cenário recarregado difere do importado: %+v", request)
+	}
+}
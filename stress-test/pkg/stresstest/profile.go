@@ -0,0 +1,132 @@
+package stresstest
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Stage representa um estágio do perfil de carga.
+// A taxa de requests varia linearmente do alvo do estágio anterior (ou 0 no primeiro)
+// até o alvo deste estágio ao longo da sua duração. Um estágio com o mesmo alvo do
+// anterior mantém a carga constante (hold); um estágio curto com alvo alto gera um pico.
+type Stage struct {
+	Name     string        `yaml:"name" json:"name"`         // Nome descritivo do estágio (ex: "ramp-up", "spike")
+	Duration time.Duration `yaml:"duration" json:"duration"` // Duração do estágio
+	Target   int           `yaml:"target" json:"target"`     // Taxa alvo (requests por segundo) ao final do estágio
+}
+
+// profileFile representa o formato do arquivo de perfil de carga (YAML ou JSON).
+type profileFile struct {
+	Stages []Stage `yaml:"stages"`
+}
+
+// LoadProfile lê um arquivo de perfil de carga em YAML ou JSON e retorna seus estágios.
+//
+// Exemplo de arquivo:
+//
+//	stages:
+//	  - {name: ramp-up, duration: 60s, target: 200}
+//	  - {name: hold, duration: 5m, target: 200}
+//	  - {name: spike, duration: 10s, target: 1000}
+//	  - {name: ramp-down, duration: 30s, target: 0}
+func LoadProfile(path string) ([]Stage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler perfil de carga: %w", err)
+	}
+
+	// JSON é um subconjunto de YAML, então o mesmo decodificador atende os dois formatos
+	var profile profileFile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar perfil de carga: %w", err)
+	}
+
+	if len(profile.Stages) == 0 {
+		return nil, fmt.Errorf("perfil de carga %s não possui estágios", path)
+	}
+	return profile.Stages, nil
+}
+
+// ParseStage interpreta um estágio no formato "duração:alvo[:nome]", por exemplo "60s:200:ramp-up".
+func ParseStage(value string) (Stage, error) {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) < 2 {
+		return Stage{}, fmt.Errorf("estágio inválido %q: use o formato duração:alvo[:nome]", value)
+	}
+
+	duration, err := time.ParseDuration(parts[0])
+	if err != nil {
+		return Stage{}, fmt.Errorf("duração inválida no estágio %q: %w", value, err)
+	}
+
+	target, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Stage{}, fmt.Errorf("alvo inválido no estágio %q: %w", value, err)
+	}
+
+	stage := Stage{Duration: duration, Target: target}
+	if len(parts) == 3 {
+		stage.Name = parts[2]
+	}
+	return stage, nil
+}
+
+// profileDuration retorna a duração total do perfil de carga.
+func profileDuration(stages []Stage) time.Duration {
+	var total time.Duration
+	for _, stage := range stages {
+		total += stage.Duration
+	}
+	return total
+}
+
+// stageIndexAt retorna o índice do estágio ativo no instante informado.
+// Após o fim do perfil retorna o último estágio.
+func stageIndexAt(stages []Stage, elapsed time.Duration) int {
+	for i, stage := range stages {
+		if elapsed < stage.Duration {
+			return i
+		}
+		elapsed -= stage.Duration
+	}
+	return len(stages) - 1
+}
+
+// expectedRequests calcula quantos requests já deveriam ter sido disparados no instante
+// informado, integrando a taxa (linear em cada estágio) desde o início do perfil.
+func expectedRequests(stages []Stage, elapsed time.Duration) float64 {
+	var total float64
+	from := 0.0
+
+	for _, stage := range stages {
+		to := float64(stage.Target)
+
+		// Estágio já concluído: soma a área completa do trapézio
+		if elapsed >= stage.Duration {
+			total += (from + to) / 2 * stage.Duration.Seconds()
+			elapsed -= stage.Duration
+			from = to
+			continue
+		}
+
+		// Estágio em andamento: soma a área até o instante atual
+		t := elapsed.Seconds()
+		rate := from + (to-from)*t/stage.Duration.Seconds()
+		total += (from + rate) / 2 * t
+		return total
+	}
+	return total
+}
+
+// stageLabel retorna o nome do estágio ou um rótulo gerado a partir da sua posição.
+func stageLabel(stage Stage, index int) string {
+	if stage.Name != "" {
+		return stage.Name
+	}
+	return fmt.Sprintf("estágio %d", index+1)
+}
@@ -0,0 +1,109 @@
+package stresstest
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseStage(t *testing.T) {
+	stage, err := ParseStage("60s:200:ramp-up")
+	if err != nil {
+		t.Fatalf("Erro ao interpretar estágio: %v", err)
+	}
+	if stage.Duration != time.Minute || stage.Target != 200 || stage.Name != "ramp-up" {
+		t.Errorf("Estágio incorreto: %+v", stage)
+	}
+
+	for _, invalido := range []string{"60s", "abc:10", "10s:abc"} {
+		if _, err := ParseStage(invalido); err == nil {
+			t.Errorf("Esperado erro para o estágio %q", invalido)
+		}
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "perfil.yaml")
+	conteudo := "stages:\n  - {name: ramp-up, duration: 60s, target: 200}\n  - {name: hold, duration: 5m, target: 200}\n"
+	if err := os.WriteFile(path, []byte(conteudo), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stages, err := LoadProfile(path)
+	if err != nil {
+		t.Fatalf("Erro ao carregar perfil: %v", err)
+	}
+	if len(stages) != 2 || stages[1].Duration != 5*time.Minute || stages[1].Target != 200 {
+		t.Errorf("Perfil carregado incorretamente: %+v", stages)
+	}
+}
+
+func TestExpectedRequests(t *testing.T) {
+	// Rampa de 0 a 200 rps em 60s, patamar de 200 rps por 5m, rampa de descida em 10s
+	stages := []Stage{
+		{Duration: 60 * time.Second, Target: 200},
+		{Duration: 5 * time.Minute, Target: 200},
+		{Duration: 10 * time.Second, Target: 0},
+	}
+
+	casos := []struct {
+		elapsed  time.Duration
+		esperado float64
+	}{
+		{30 * time.Second, 1500},                // metade da rampa: 100 rps médios * 30s / 2
+		{60 * time.Second, 6000},                // rampa completa
+		{6 * time.Minute, 6000 + 60000},         // rampa + patamar
+		{6*time.Minute + 10*time.Second, 67000}, // perfil completo
+	}
+	for _, c := range casos {
+		obtido := expectedRequests(stages, c.elapsed)
+		if math.Abs(obtido-c.esperado) > 0.001 {
+			t.Errorf("Em %v: esperado %.0f requests, obtido %.3f", c.elapsed, c.esperado, obtido)
+		}
+	}
+
+	if idx := stageIndexAt(stages, 90*time.Second); idx != 1 {
+		t.Errorf("Estágio ativo incorreto: esperado 1, obtido %d", idx)
+	}
+}
+
+func TestRun_PerfilDeCarga(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := Config{
+		RequestSpec: RequestSpec{URL: server.URL},
+		Concurrency: 10,
+		Stages: []Stage{
+			{Name: "ramp-up", Duration: 200 * time.Millisecond, Target: 100},
+			{Name: "hold", Duration: 200 * time.Millisecond, Target: 100},
+		},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Configuração inválida: %v", err)
+	}
+
+	report := Run(context.Background(), config)
+
+	// 10 requests na rampa (média de 50 rps por 0,2s) e 20 no patamar
+	if report.TotalRequests != 30 {
+		t.Errorf("Total de requests incorreto: esperado 30, obtido %d", report.TotalRequests)
+	}
+	if len(report.Stages) != 2 {
+		t.Fatalf("Esperado 2 estágios no relatório, obtido %d", len(report.Stages))
+	}
+	if report.Stages[0].TotalRequests+report.Stages[1].TotalRequests != report.TotalRequests {
+		t.Errorf("Soma dos estágios diverge do total: %d + %d != %d",
+			report.Stages[0].TotalRequests, report.Stages[1].TotalRequests, report.TotalRequests)
+	}
+	if report.Stages[1].Start != 200*time.Millisecond {
+		t.Errorf("Início do segundo estágio incorreto: %v", report.Stages[1].Start)
+	}
+}
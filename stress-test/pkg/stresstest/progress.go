@@ -0,0 +1,117 @@
+package stresstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultProgressInterval é o intervalo padrão entre snapshots de progresso.
+const DefaultProgressInterval = time.Second
+
+// Snapshot é uma fotografia das métricas de um teste em andamento.
+// As taxas e percentis se referem ao último intervalo; contadores são acumulados desde o início.
+type Snapshot struct {
+	Type        string        `json:"type"`         // Sempre "snapshot" (identifica a linha na saída NDJSON)
+	Elapsed     time.Duration `json:"elapsed"`      // Tempo decorrido desde o início do teste
+	Completed   int           `json:"completed"`    // Requests concluídos até o momento
+	Planned     int           `json:"planned"`      // Total de requests previstos para o teste
+	InFlight    int           `json:"in_flight"`    // Requests em andamento no momento da coleta
+	RPS         float64       `json:"rps"`          // Requests concluídos por segundo no último intervalo
+	P50         time.Duration `json:"p50"`          // Mediana da latência no último intervalo
+	P90         time.Duration `json:"p90"`          // Percentil 90 da latência no último intervalo
+	P95         time.Duration `json:"p95"`          // Percentil 95 da latência no último intervalo
+	P99         time.Duration `json:"p99"`          // Percentil 99 da latência no último intervalo
+	Errors      int           `json:"errors"`       // Requests com erro de rede/timeout até o momento
+	Non2xx      int           `json:"non_2xx"`      // Respostas com status fora da faixa 2xx até o momento
+	ErrorRate   float64       `json:"error_rate"`   // Proporção de requests com erro (0 a 1)
+	StatusCodes map[int]int   `json:"status_codes"` // Distribuição acumulada de códigos de status
+	Final       bool          `json:"final"`        // Indica o último snapshot, emitido ao final do teste
+}
+
+// LiveView exibe o progresso do teste no terminal, redesenhando as mesmas linhas a cada snapshot.
+// Deve ser usado apenas quando a saída é um terminal, pois depende de sequências de escape ANSI.
+type LiveView struct {
+	w     io.Writer // Destino da exibição (normalmente os.Stdout)
+	lines int       // Quantidade de linhas desenhadas na última atualização
+}
+
+// NewLiveView cria uma visualização em tempo real que escreve no destino informado.
+func NewLiveView(w io.Writer) *LiveView {
+	return &LiveView{w: w}
+}
+
+// Update redesenha a visualização com os dados do snapshot.
+func (v *LiveView) Update(s Snapshot) {
+	var b strings.Builder
+
+	// Volta o cursor para o início da visualização anterior
+	if v.lines > 0 {
+		fmt.Fprintf(&b, "\033[%dA", v.lines)
+	}
+
+	lines := []string{
+		fmt.Sprintf("%s %5.1f%%  %d/%d  ⏱️  %v",
+			progressBar(s.Completed, s.Planned, 30), percent(s.Completed, s.Planned), s.Completed, s.Planned,
+			s.Elapsed.Round(time.Second)),
+		fmt.Sprintf("🚀 %.1f req/s | ⚡ em andamento: %d | p50: %v | p99: %v | ❌ erros: %.2f%%",
+			s.RPS, s.InFlight, round(s.P50), round(s.P99), s.ErrorRate*100),
+		"📈 " + formatStatusCodes(s.StatusCodes),
+	}
+	for _, line := range lines {
+		// Limpa a linha antes de reescrevê-la
+		b.WriteString("\033[2K")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	v.lines = len(lines)
+	io.WriteString(v.w, b.String())
+}
+
+// NewJSONProgress retorna uma função que escreve cada snapshot como uma linha JSON (NDJSON).
+func NewJSONProgress(w io.Writer) func(Snapshot) {
+	encoder := json.NewEncoder(w)
+	return func(s Snapshot) {
+		encoder.Encode(s)
+	}
+}
+
+// progressBar desenha uma barra de progresso com a largura informada.
+func progressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(width, done*width/total)
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
+
+// percent calcula a porcentagem de done em relação a total.
+func percent(done, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(done) / float64(total) * 100
+}
+
+// formatStatusCodes formata a distribuição de códigos de status em ordem crescente.
+func formatStatusCodes(codes map[int]int) string {
+	if len(codes) == 0 {
+		return "sem respostas"
+	}
+
+	sorted := make([]int, 0, len(codes))
+	for code := range codes {
+		sorted = append(sorted, code)
+	}
+	sort.Ints(sorted)
+
+	parts := make([]string, 0, len(sorted))
+	for _, code := range sorted {
+		parts = append(parts, fmt.Sprintf("%d: %d", code, codes[code]))
+	}
+	return strings.Join(parts, " | ")
+}
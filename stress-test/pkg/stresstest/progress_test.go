@@ -0,0 +1,97 @@
+package stresstest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRun_Progresso(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var snapshots []Snapshot
+
+	report := Run(context.Background(), Config{
+		RequestSpec:      RequestSpec{URL: server.URL},
+		Requests:         100,
+		Concurrency:      4,
+		ProgressInterval: 20 * time.Millisecond,
+		Progress: func(s Snapshot) {
+			mu.Lock()
+			defer mu.Unlock()
+			snapshots = append(snapshots, s)
+		},
+	})
+
+	if len(snapshots) < 3 {
+		t.Fatalf("Esperado vários snapshots durante o teste, obtido %d", len(snapshots))
+	}
+
+	// Os snapshots intermediários mostram o teste em andamento
+	parcial := snapshots[0]
+	if parcial.Final || parcial.Completed >= 100 || parcial.InFlight == 0 || parcial.RPS == 0 || parcial.P50 == 0 {
+		t.Errorf("Snapshot parcial inesperado: %+v", parcial)
+	}
+
+	// O último snapshot corresponde ao relatório final
+	final := snapshots[len(snapshots)-1]
+	if !final.Final || final.Completed != report.TotalRequests || final.Planned != 100 || final.InFlight != 0 {
+		t.Errorf("Snapshot final inesperado: %+v", final)
+	}
+	if final.StatusCodes[200] != 100 {
+		t.Errorf("Distribuição de status incorreta no snapshot final: %v", final.StatusCodes)
+	}
+}
+
+func TestLiveView(t *testing.T) {
+	var out bytes.Buffer
+	view := NewLiveView(&out)
+
+	view.Update(Snapshot{Completed: 50, Planned: 100, RPS: 10, StatusCodes: map[int]int{200: 48, 500: 2}})
+	view.Update(Snapshot{Completed: 100, Planned: 100, RPS: 10, StatusCodes: map[int]int{200: 98, 500: 2}})
+
+	saida := out.String()
+	if !strings.Contains(saida, " 50.0%") || !strings.Contains(saida, "100.0%") {
+		t.Errorf("Porcentagens ausentes na visualização: %q", saida)
+	}
+	if !strings.Contains(saida, "200: 98 | 500: 2") {
+		t.Errorf("Distribuição de status ausente: %q", saida)
+	}
+	// A segunda atualização deve voltar o cursor para redesenhar as três linhas
+	if !strings.Contains(saida, "\033[3A") {
+		t.Errorf("Visualização não reposiciona o cursor: %q", saida)
+	}
+}
+
+func TestJSONProgress(t *testing.T) {
+	var out bytes.Buffer
+	emit := NewJSONProgress(&out)
+	emit(Snapshot{Type: "snapshot", Completed: 1, Planned: 2})
+	emit(Snapshot{Type: "snapshot", Completed: 2, Planned: 2, Final: true})
+	WriteJSONReport(&out, Report{Stats: Stats{TotalRequests: 2}})
+
+	var tipos []string
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var linha map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &linha); err != nil {
+			t.Fatalf("Linha NDJSON inválida %q: %v", scanner.Text(), err)
+		}
+		tipos = append(tipos, linha["type"].(string))
+	}
+
+	if strings.Join(tipos, ",") != "snapshot,snapshot,report" {
+		t.Errorf("Sequência de linhas inesperada: %v", tipos)
+	}
+}
@@ -0,0 +1,196 @@
+package stresstest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxRetryAfter é a maior espera feita por um usuário virtual após uma resposta 429 quando
+// Config.RespectRetryAfter está ativo. Esperas maiores pedidas pelo servidor são reduzidas a ela.
+const DefaultMaxRetryAfter = time.Minute
+
+// defaultRetryBackoff é a espera após uma resposta 429 sem Retry-After nem X-RateLimit-Reset.
+const defaultRetryBackoff = time.Second
+
+// epochThreshold separa os dois formatos de X-RateLimit-Reset: valores acima dele são instantes
+// Unix (segundos desde 1970) e os demais são segundos até a renovação da cota.
+const epochThreshold = 1_000_000_000
+
+// RateLimit contém os cabeçalhos de limite de taxa de uma resposta HTTP (Retry-After e
+// X-RateLimit-* ou RateLimit-*). Valores negativos indicam cabeçalho ausente.
+type RateLimit struct {
+	RetryAfter time.Duration // Espera pedida no Retry-After (segundos ou data HTTP)
+	Limit      int           // Requests permitidos na janela (X-RateLimit-Limit)
+	Remaining  int           // Requests restantes na janela (X-RateLimit-Remaining)
+	Reset      time.Duration // Tempo até a renovação da cota (X-RateLimit-Reset)
+}
+
+// parseRateLimit interpreta os cabeçalhos de limite de taxa da resposta. Retorna nil quando a
+// resposta não tem nenhum deles.
+func parseRateLimit(header http.Header, now time.Time) *RateLimit {
+	limit := &RateLimit{
+		RetryAfter: parseRetryAfter(header.Get("Retry-After"), now),
+		Limit:      parseLeadingInt(rateLimitHeader(header, "Limit")),
+		Remaining:  parseLeadingInt(rateLimitHeader(header, "Remaining")),
+		Reset:      -1,
+	}
+	if reset := parseLeadingInt(rateLimitHeader(header, "Reset")); reset > epochThreshold {
+		limit.Reset = max(time.Unix(int64(reset), 0).Sub(now), 0)
+	} else if reset >= 0 {
+		limit.Reset = time.Duration(reset) * time.Second
+	}
+
+	if limit.RetryAfter < 0 && limit.Limit < 0 && limit.Remaining < 0 && limit.Reset < 0 {
+		return nil
+	}
+	return limit
+}
+
+// rateLimitHeader obtém um cabeçalho X-RateLimit-<name> ou, na ausência dele, o equivalente
+// sem o prefixo X- (RateLimit-<name>, proposto pela IETF).
+func rateLimitHeader(header http.Header, name string) string {
+	if value := header.Get("X-RateLimit-" + name); value != "" {
+		return value
+	}
+	return header.Get("RateLimit-" + name)
+}
+
+// parseRetryAfter interpreta o Retry-After, em segundos ou como data HTTP. Retorna -1 quando o
+// cabeçalho está ausente ou é inválido.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return -1
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return -1
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return -1
+}
+
+// parseLeadingInt interpreta o número no início do valor, ignorando parâmetros como em
+// "100, 100;w=60". Retorna -1 quando não há número.
+func parseLeadingInt(value string) int {
+	value = strings.TrimSpace(value)
+	end := 0
+	for end < len(value) && value[end] >= '0' && value[end] <= '9' {
+		end++
+	}
+	n, err := strconv.Atoi(value[:end])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// throttled indica se a resposta é uma recusa por limite de taxa (HTTP 429 Too Many Requests).
+func (r Result) throttled() bool {
+	return r.Protocol == "" && r.Error == nil && r.StatusCode == http.StatusTooManyRequests
+}
+
+// retryWait retorna quanto um cliente bem-comportado espera antes do próximo request após o
+// resultado: o Retry-After, o tempo até a renovação da cota esgotada ou defaultRetryBackoff.
+// Retorna zero quando o resultado não é uma resposta 429.
+func retryWait(result Result) time.Duration {
+	if !result.throttled() {
+		return 0
+	}
+	if limit := result.RateLimit; limit != nil {
+		switch {
+		case limit.RetryAfter >= 0:
+			return limit.RetryAfter
+		case limit.Remaining == 0 && limit.Reset >= 0:
+			return limit.Reset
+		}
+	}
+	return defaultRetryBackoff
+}
+
+// ThrottleStats agrega as respostas recusadas por limite de taxa e os cabeçalhos de limite
+// anunciados pelo servidor.
+type ThrottleStats struct {
+	Throttled     int           `json:"throttled"`                // Respostas 429 (Too Many Requests)
+	FirstThrottle time.Duration `json:"first_throttle,omitempty"` // Instante da primeira resposta 429 relativo ao início do teste
+	RetryAfter    Histogram     `json:"retry_after"`              // Esperas pedidas no Retry-After
+	Reset         Histogram     `json:"reset"`                    // Tempo até a renovação da cota (X-RateLimit-Reset)
+	Limits        map[int]int   `json:"limits,omitempty"`         // Limites anunciados em X-RateLimit-Limit (limite -> respostas)
+
+	// X-RateLimit-Remaining
+	RemainingCount int   `json:"remaining_count"` // Respostas com o cabeçalho
+	RemainingSum   int64 `json:"remaining_sum"`   // Soma dos valores (para a média)
+	RemainingMin   int   `json:"remaining_min"`   // Menor cota restante anunciada
+	Exhausted      int   `json:"exhausted"`       // Respostas com a cota esgotada (Remaining 0)
+
+	// Esperas dos usuários virtuais (apenas com Config.RespectRetryAfter)
+	Backoffs    int           `json:"backoffs,omitempty"`     // Esperas feitas após respostas 429
+	BackoffTime time.Duration `json:"backoff_time,omitempty"` // Tempo total das esperas
+}
+
+// newThrottleStats cria um ThrottleStats vazio pronto para receber respostas.
+func newThrottleStats() *ThrottleStats {
+	return &ThrottleStats{Limits: make(map[int]int)}
+}
+
+// add contabiliza uma resposta nas métricas.
+func (t *ThrottleStats) add(result Result) {
+	if result.throttled() {
+		t.Throttled++
+	}
+	limit := result.RateLimit
+	if limit == nil {
+		return
+	}
+	if limit.RetryAfter >= 0 {
+		t.RetryAfter.Record(limit.RetryAfter)
+	}
+	if limit.Reset >= 0 {
+		t.Reset.Record(limit.Reset)
+	}
+	if limit.Limit >= 0 {
+		t.Limits[limit.Limit]++
+	}
+	if limit.Remaining >= 0 {
+		if t.RemainingCount == 0 || limit.Remaining < t.RemainingMin {
+			t.RemainingMin = limit.Remaining
+		}
+		t.RemainingCount++
+		t.RemainingSum += int64(limit.Remaining)
+		if limit.Remaining == 0 {
+			t.Exhausted++
+		}
+	}
+}
+
+// merge soma as métricas de outro ThrottleStats neste.
+func (t *ThrottleStats) merge(other ThrottleStats) {
+	t.Throttled += other.Throttled
+	if other.FirstThrottle > 0 && (t.FirstThrottle == 0 || other.FirstThrottle < t.FirstThrottle) {
+		t.FirstThrottle = other.FirstThrottle
+	}
+	t.RetryAfter.Merge(other.RetryAfter)
+	t.Reset.Merge(other.Reset)
+	if t.Limits == nil {
+		t.Limits = make(map[int]int, len(other.Limits))
+	}
+	for limit, count := range other.Limits {
+		t.Limits[limit] += count
+	}
+
+	if other.RemainingCount > 0 && (t.RemainingCount == 0 || other.RemainingMin < t.RemainingMin) {
+		t.RemainingMin = other.RemainingMin
+	}
+	t.RemainingCount += other.RemainingCount
+	t.RemainingSum += other.RemainingSum
+	t.Exhausted += other.Exhausted
+
+	t.Backoffs += other.Backoffs
+	t.BackoffTime += other.BackoffTime
+}
@@ -0,0 +1,189 @@
+package stresstest
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	agora := time.Date(2024, 10, 10, 13, 0, 0, 0, time.UTC)
+
+	casos := map[string]struct {
+		cabecalhos http.Header
+		esperado   *RateLimit
+	}{
+		"Retry-After em segundos": {
+			http.Header{"Retry-After": {"30"}},
+			&RateLimit{RetryAfter: 30 * time.Second, Limit: -1, Remaining: -1, Reset: -1},
+		},
+		"Retry-After como data HTTP": {
+			http.Header{"Retry-After": {agora.Add(90 * time.Second).Format(http.TimeFormat)}},
+			&RateLimit{RetryAfter: 90 * time.Second, Limit: -1, Remaining: -1, Reset: -1},
+		},
+		"X-RateLimit com reset em instante Unix": {
+			http.Header{"X-Ratelimit-Limit": {"100"}, "X-Ratelimit-Remaining": {"0"},
+				"X-Ratelimit-Reset": {strconv.FormatInt(agora.Add(45*time.Second).Unix(), 10)}},
+			&RateLimit{RetryAfter: -1, Limit: 100, Remaining: 0, Reset: 45 * time.Second},
+		},
+		"RateLimit da IETF com parâmetros": {
+			http.Header{"Ratelimit-Limit": {"100, 100;w=60"}, "Ratelimit-Remaining": {"42"}, "Ratelimit-Reset": {"12"}},
+			&RateLimit{RetryAfter: -1, Limit: 100, Remaining: 42, Reset: 12 * time.Second},
+		},
+	}
+	for nome, caso := range casos {
+		obtido := parseRateLimit(caso.cabecalhos, agora)
+		if obtido == nil || *obtido != *caso.esperado {
+			t.Errorf("%s: esperado %+v, obtido %+v", nome, caso.esperado, obtido)
+		}
+	}
+
+	// Sem cabeçalhos de limite (ou com valores inválidos) não há informação a registrar
+	if limite := parseRateLimit(http.Header{"Retry-After": {"amanhã"}, "Content-Type": {"text/plain"}}, agora); limite != nil {
+		t.Errorf("Esperado nil, obtido %+v", limite)
+	}
+}
+
+func TestRetryWait(t *testing.T) {
+	casos := map[string]struct {
+		resultado Result
+		esperado  time.Duration
+	}{
+		"sucesso":                 {Result{StatusCode: 200, RateLimit: &RateLimit{RetryAfter: time.Second}}, 0},
+		"429 com Retry-After":     {Result{StatusCode: 429, RateLimit: &RateLimit{RetryAfter: 3 * time.Second, Reset: -1}}, 3 * time.Second},
+		"429 com cota esgotada":   {Result{StatusCode: 429, RateLimit: &RateLimit{RetryAfter: -1, Remaining: 0, Reset: 5 * time.Second}}, 5 * time.Second},
+		"429 sem cabeçalhos":      {Result{StatusCode: 429}, defaultRetryBackoff},
+		"429 no gRPC não existe":  {Result{StatusCode: 429, Protocol: ProtocolGRPC}, 0},
+		"429 com erro de leitura": {Result{StatusCode: 429, Error: &bodyReadError{context.Canceled}}, 0},
+	}
+	for nome, caso := range casos {
+		if obtido := retryWait(caso.resultado); obtido != caso.esperado {
+			t.Errorf("%s: esperado %v, obtido %v", nome, caso.esperado, obtido)
+		}
+	}
+}
+
+// servidorComLimite aceita os primeiros requests e recusa os seguintes com 429, anunciando a cota
+// nos cabeçalhos X-RateLimit-* e pedindo a espera informada no Retry-After
+func servidorComLimite(t *testing.T, limite int64, retryAfter string) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var recebidos atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := recebidos.Add(1)
+		w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(limite, 10))
+		w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(max(limite-n, 0), 10))
+		w.Header().Set("X-RateLimit-Reset", "60")
+		if n > limite {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &recebidos
+}
+
+func TestRun_EstatisticasDeLimiteDeTaxa(t *testing.T) {
+	server, _ := servidorComLimite(t, 10, "2")
+
+	report := Run(context.Background(), Config{RequestSpec: RequestSpec{URL: server.URL}, Requests: 40, Concurrency: 1})
+
+	throttle := report.Throttle
+	if throttle == nil || throttle.Throttled != 30 || report.StatusCodes[http.StatusTooManyRequests] != 30 {
+		t.Fatalf("Esperado 30 respostas 429, obtido %+v", throttle)
+	}
+	if throttle.FirstThrottle <= 0 || throttle.FirstThrottle > report.TotalTime {
+		t.Errorf("Instante da primeira resposta 429 incorreto: %v", throttle.FirstThrottle)
+	}
+	if throttle.RetryAfter.Total != 30 || throttle.RetryAfter.Max != 2*time.Second {
+		t.Errorf("Esperado 30 Retry-After de 2s, obtido %+v", throttle.RetryAfter)
+	}
+	if throttle.Limits[10] != 40 || throttle.RemainingCount != 40 || throttle.RemainingMin != 0 || throttle.Exhausted != 31 {
+		t.Errorf("Cabeçalhos X-RateLimit contabilizados incorretamente: %+v", throttle)
+	}
+	if throttle.Reset.Total != 40 || throttle.Backoffs != 0 {
+		t.Errorf("Esperado 40 resets e nenhuma espera, obtido %+v", throttle)
+	}
+
+	throttled := 0
+	for _, bucket := range report.Timeline {
+		throttled += bucket.Throttled
+	}
+	if throttled != 30 {
+		t.Errorf("Esperado 30 respostas 429 na linha do tempo, obtido %d", throttled)
+	}
+
+	var saida bytes.Buffer
+	if err := WriteReport(&saida, report); err != nil || !strings.Contains(saida.String(), "respostas 429: 30 (75.0%)") {
+		t.Errorf("Relatório sem a seção de limitação de taxa (%v):\n%s", err, saida.String())
+	}
+}
+
+func TestRun_RespeitaRetryAfter(t *testing.T) {
+	// Sem Retry-After o worker espera defaultRetryBackoff, limitado por MaxRetryAfter
+	server, recebidos := servidorComLimite(t, 1, "")
+	config := Config{
+		RequestSpec:       RequestSpec{URL: server.URL},
+		Requests:          4,
+		Concurrency:       1,
+		RespectRetryAfter: true,
+		MaxRetryAfter:     50 * time.Millisecond,
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	report := Run(context.Background(), config)
+
+	if recebidos.Load() != 4 || report.Throttle == nil || report.Throttle.Throttled != 3 {
+		t.Fatalf("Esperado 3 respostas 429 em 4 requests, obtido %d requests e %+v", recebidos.Load(), report.Throttle)
+	}
+	// A espera acontece antes do próximo request: após o último não há espera
+	if report.Throttle.Backoffs != 2 || report.Throttle.BackoffTime < 100*time.Millisecond || report.TotalTime < 100*time.Millisecond {
+		t.Errorf("Esperado 2 esperas de 50ms, obtido %d (%v)", report.Throttle.Backoffs, report.Throttle.BackoffTime)
+	}
+
+	config.MaxRetryAfter = -time.Second
+	if err := config.Validate(); err == nil {
+		t.Error("Esperado erro para espera máxima negativa")
+	}
+}
+
+func TestRun_RetryAfterInterrompidoPeloCancelamento(t *testing.T) {
+	server, _ := servidorComLimite(t, 0, "3600")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	inicio := time.Now()
+	report := Run(ctx, Config{RequestSpec: RequestSpec{URL: server.URL}, Requests: 10, Concurrency: 2, RespectRetryAfter: true})
+
+	// As esperas (limitadas a DefaultMaxRetryAfter) terminam junto com o teste
+	if time.Since(inicio) > 5*time.Second || report.Aborted == "" || report.TotalRequests != 2 {
+		t.Errorf("Esperado teste interrompido durante as esperas, obtido %d requests em %v", report.TotalRequests, time.Since(inicio))
+	}
+}
+
+func TestThrottleStats_Merge(t *testing.T) {
+	a, b := newThrottleStats(), newThrottleStats()
+	a.add(Result{StatusCode: 429, RateLimit: &RateLimit{RetryAfter: time.Second, Limit: 10, Remaining: 0, Reset: -1}})
+	a.FirstThrottle = 3 * time.Second
+	b.add(Result{StatusCode: 200, RateLimit: &RateLimit{RetryAfter: -1, Limit: 10, Remaining: 5, Reset: -1}})
+	b.FirstThrottle = time.Second
+
+	var total Stats
+	total.merge(Stats{Throttle: a})
+	total.merge(Stats{Throttle: b})
+
+	merged := total.Throttle
+	if merged.Throttled != 1 || merged.FirstThrottle != time.Second || merged.Limits[10] != 2 ||
+		merged.RemainingMin != 0 || merged.RemainingSum != 5 || merged.Exhausted != 1 || merged.RetryAfter.Total != 1 {
+		t.Errorf("Métricas combinadas incorretamente: %+v", merged)
+	}
+}
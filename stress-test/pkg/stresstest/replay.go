@@ -0,0 +1,317 @@
+package stresstest
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxReplayPaths limita quantos caminhos distintos têm métricas próprias no replay; os demais são
+// agrupados em otherTags para que a memória não cresça com a variedade de URLs do log. O limite é
+// aplicado ao log inteiro, de modo que todos os workers agrupem os mesmos caminhos.
+const maxReplayPaths = maxTags
+
+// clfTimeLayout é o formato de data dos logs Common/Combined Log Format.
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// Replay reproduz um log de acesso: cada entrada é disparada contra a URL base do teste
+// (Config.URL) no mesmo instante relativo em que aparece no log, dividido por Speed.
+// Método e caminho vêm do log; cabeçalhos, autenticação e asserções vêm da configuração.
+// Os logs não trazem o corpo das requisições, então as requisições são enviadas sem corpo.
+type Replay struct {
+	Entries []LogEntry `json:"entries"` // Requisições do log, ordenadas pelo instante relativo
+	Speed   float64    `json:"speed"`   // Fator de aceleração: 1 = tempo real, 2 = duas vezes mais rápido (padrão 1)
+	Skipped int        `json:"-"`       // Linhas do log ignoradas por não serem reconhecidas
+
+	once   sync.Once // Garante que a preparação ocorra uma única vez
+	groups []string  // Tag de cada entrada nas métricas por tag (método e caminho normalizado)
+}
+
+// LogEntry é uma requisição lida do log de acesso.
+type LogEntry struct {
+	Offset time.Duration `json:"offset"` // Instante da requisição relativo à primeira do log
+	Method string        `json:"method"` // Método HTTP
+	Path   string        `json:"path"`   // Caminho com a query string, como registrado no log
+}
+
+// clfPattern reconhece linhas nos formatos Common e Combined Log Format, por exemplo:
+//
+//	127.0.0.1 - ana [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.1" 200 2326 "-" "curl/8.0"
+var clfPattern = regexp.MustCompile(`^\S+ \S+ .+? \[([^\]]+)\] "([A-Z]+) (\S+)[^"]*"`)
+
+// Campos aceitos nos logs em JSON, em ordem de preferência
+var (
+	jsonTimeFields    = []string{"time", "timestamp", "@timestamp", "time_local", "time_iso8601", "ts"}
+	jsonMethodFields  = []string{"method", "request_method", "http_method"}
+	jsonPathFields    = []string{"path", "uri", "request_uri", "url"}
+	jsonRequestFields = []string{"request"}
+)
+
+// LoadAccessLog lê um log de acesso nos formatos Common/Combined Log Format ou JSON (um objeto
+// por linha). Linhas não reconhecidas são ignoradas e contadas em Replay.Skipped.
+func LoadAccessLog(path string) (*Replay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler log de acesso: %w", err)
+	}
+	defer file.Close()
+	return ParseAccessLog(file)
+}
+
+// ParseAccessLog interpreta um log de acesso (ver LoadAccessLog). O formato é detectado em cada
+// linha: objetos JSON começam com "{" e as demais linhas são lidas como Common/Combined Log Format.
+func ParseAccessLog(r io.Reader) (*Replay, error) {
+	type timedEntry struct {
+		time  time.Time
+		entry LogEntry
+	}
+	var entries []timedEntry
+	replay := &Replay{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var t time.Time
+		var entry LogEntry
+		var ok bool
+		if strings.HasPrefix(line, "{") {
+			t, entry, ok = parseJSONLogLine(line)
+		} else {
+			t, entry, ok = parseCLFLine(line)
+		}
+		if !ok {
+			replay.Skipped++
+			continue
+		}
+		entries = append(entries, timedEntry{t, entry})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler log de acesso: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("nenhuma requisição reconhecida no log de acesso (%d linhas ignoradas)", replay.Skipped)
+	}
+
+	// O log registra a requisição ao terminar, então pode não estar em ordem de chegada
+	slices.SortStableFunc(entries, func(a, b timedEntry) int { return a.time.Compare(b.time) })
+
+	start := entries[0].time
+	replay.Entries = make([]LogEntry, len(entries))
+	for i, e := range entries {
+		e.entry.Offset = e.time.Sub(start)
+		replay.Entries[i] = e.entry
+	}
+	return replay, nil
+}
+
+// parseCLFLine interpreta uma linha no formato Common/Combined Log Format.
+func parseCLFLine(line string) (time.Time, LogEntry, bool) {
+	match := clfPattern.FindStringSubmatch(line)
+	if match == nil {
+		return time.Time{}, LogEntry{}, false
+	}
+	t, err := time.Parse(clfTimeLayout, match[1])
+	if err != nil || !strings.HasPrefix(match[3], "/") {
+		return time.Time{}, LogEntry{}, false
+	}
+	return t, LogEntry{Method: match[2], Path: match[3]}, true
+}
+
+// parseJSONLogLine interpreta uma linha de log em JSON. O instante aceita RFC 3339, o formato
+// do Common Log Format ou um timestamp Unix em segundos (com fração); o caminho pode vir em um
+// campo próprio ou na linha da requisição ("request": "GET /x HTTP/1.1").
+func parseJSONLogLine(line string) (time.Time, LogEntry, bool) {
+	var fields map[string]any
+	if json.Unmarshal([]byte(line), &fields) != nil {
+		return time.Time{}, LogEntry{}, false
+	}
+
+	t, ok := parseLogTime(firstField(fields, jsonTimeFields))
+	if !ok {
+		return time.Time{}, LogEntry{}, false
+	}
+
+	entry := LogEntry{
+		Method: strings.ToUpper(stringField(fields, jsonMethodFields)),
+		Path:   stringField(fields, jsonPathFields),
+	}
+	if request := strings.Fields(stringField(fields, jsonRequestFields)); len(request) >= 2 {
+		entry.Method = cmp.Or(entry.Method, request[0])
+		entry.Path = cmp.Or(entry.Path, request[1])
+	}
+
+	// URLs absolutas são reduzidas ao caminho: o destino é sempre a URL base do teste
+	if i := strings.Index(entry.Path, "://"); i >= 0 {
+		rest := entry.Path[i+3:]
+		if j := strings.Index(rest, "/"); j >= 0 {
+			entry.Path = rest[j:]
+		} else {
+			entry.Path = "/"
+		}
+	}
+
+	if entry.Method == "" || !strings.HasPrefix(entry.Path, "/") {
+		return time.Time{}, LogEntry{}, false
+	}
+	return t, entry, true
+}
+
+// firstField retorna o valor do primeiro campo presente, ou nil.
+func firstField(fields map[string]any, names []string) any {
+	for _, name := range names {
+		if value, ok := fields[name]; ok {
+			return value
+		}
+	}
+	return nil
+}
+
+// stringField retorna o valor textual do primeiro campo presente, ou vazio.
+func stringField(fields map[string]any, names []string) string {
+	value, _ := firstField(fields, names).(string)
+	return value
+}
+
+// parseLogTime interpreta o instante de uma linha de log em JSON.
+func parseLogTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case float64:
+		seconds := int64(v)
+		return time.Unix(seconds, int64((v-float64(seconds))*1e9)), true
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, clfTimeLayout} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, true
+			}
+		}
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			return parseLogTime(seconds)
+		}
+	}
+	return time.Time{}, false
+}
+
+// validate verifica se o replay possui requisições e se o fator de aceleração é válido.
+func (r *Replay) validate() error {
+	if len(r.Entries) == 0 {
+		return fmt.Errorf("log de acesso sem requisições")
+	}
+	if r.Speed < 0 {
+		return fmt.Errorf("fator de aceleração não pode ser negativo")
+	}
+	r.prepare()
+	return nil
+}
+
+// prepare calcula a tag de cada entrada, usada nas métricas por caminho. É executado uma única vez.
+func (r *Replay) prepare() {
+	r.once.Do(func() {
+		seen := make(map[string]bool)
+		r.groups = make([]string, len(r.Entries))
+		for i, entry := range r.Entries {
+			group := entry.Method + " " + normalizePath(entry.Path)
+			if !seen[group] && len(seen) >= maxReplayPaths {
+				group = otherTags
+			}
+			seen[group] = true
+			r.groups[i] = group
+		}
+	})
+}
+
+// delay retorna quando a entrada deve ser disparada, relativo ao início do teste.
+func (r *Replay) delay(entry LogEntry) time.Duration {
+	if r.Speed == 0 {
+		return entry.Offset
+	}
+	return time.Duration(float64(entry.Offset) / r.Speed)
+}
+
+// idSegment reconhece segmentos do caminho que identificam um recurso: números, UUIDs e
+// hashes hexadecimais longos.
+var idSegment = regexp.MustCompile(`^(\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+
+// normalizePath remove a query string e substitui identificadores por ":id", para que
+// /users/1 e /users/2 sejam contabilizados juntos.
+func normalizePath(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if idSegment.MatchString(segment) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// dispatchReplay dispara uma entrada do log por vez, no instante relativo registrado.
+// Retorna antes do fim quando o canal abort é fechado (teste interrompido).
+func dispatchReplay(replay *Replay, startTime time.Time, abort <-chan struct{}, dispatch func(stage int) bool) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for _, entry := range replay.Entries {
+		if wait := time.Until(startTime.Add(replay.delay(entry))); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-timer.C:
+			case <-abort:
+				return
+			}
+		}
+		if !dispatch(-1) {
+			return
+		}
+	}
+}
+
+// replayExecutor reenvia as requisições do log, na ordem em que são disparadas.
+type replayExecutor struct {
+	spec   RequestSpec  // Requisição base: URL de destino, cabeçalhos, autenticação e asserções
+	replay *Replay      // Log reproduzido
+	client *http.Client // Cliente HTTP compartilhado por todos os workers
+	next   atomic.Int64 // Índice da próxima entrada
+}
+
+// newReplayExecutor cria o executor do replay da configuração.
+func newReplayExecutor(config Config) *replayExecutor {
+	return &replayExecutor{spec: config.RequestSpec, replay: config.Replay, client: newClient(config)}
+}
+
+// Execute envia a próxima requisição do log para a URL base.
+func (e *replayExecutor) Execute(ctx context.Context) Result {
+	i := int(e.next.Add(1) - 1)
+	if i >= len(e.replay.Entries) {
+		return Result{Error: &requestError{fmt.Errorf("log de acesso esgotado")}}
+	}
+	entry := e.replay.Entries[i]
+
+	spec := e.spec
+	spec.Method = entry.Method
+	spec.URL = strings.TrimSuffix(e.spec.URL, "/") + entry.Path
+	result, _ := sendRequest(ctx, e.client, spec, false)
+	result.Tag = e.replay.groups[i]
+	return result
+}
+
+// Close fecha as conexões ociosas do cliente.
+func (e *replayExecutor) Close() {
+	e.client.CloseIdleConnections()
+}
@@ -0,0 +1,148 @@
+package stresstest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// logDeAcesso mistura Common Log Format, Combined Log Format, JSON e linhas inválidas, fora de ordem
+const logDeAcesso = `10.0.0.1 - ana [10/Oct/2024:13:55:36 +0000] "GET /users/42?full=1 HTTP/1.1" 200 512 "-" "Mozilla/5.0"
+10.0.0.2 - - [10/Oct/2024:13:55:35 +0000] "POST /orders HTTP/1.1" 201 12
+{"time":"2024-10-10T13:55:37.5Z","method":"get","uri":"/users/7"}
+{"@timestamp":"10/Oct/2024:13:55:38 +0000","request":"DELETE https://api.exemplo.com/orders/9f86d081884c7d65 HTTP/2.0","status":204}
+\x16\x03\x01 - - [10/Oct/2024:13:55:39 +0000] "-" 400 0
+{"ts":1728568540.25,"method":"GET"}
+
+`
+
+func TestParseAccessLog(t *testing.T) {
+	replay, err := ParseAccessLog(strings.NewReader(logDeAcesso))
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	// Entradas ordenadas pelo instante, relativas à primeira (a linha do POST)
+	esperadas := []LogEntry{
+		{Offset: 0, Method: "POST", Path: "/orders"},
+		{Offset: time.Second, Method: "GET", Path: "/users/42?full=1"},
+		{Offset: 2500 * time.Millisecond, Method: "GET", Path: "/users/7"},
+		{Offset: 3 * time.Second, Method: "DELETE", Path: "/orders/9f86d081884c7d65"},
+	}
+	if len(replay.Entries) != len(esperadas) {
+		t.Fatalf("Esperado %d entradas, obtido %+v", len(esperadas), replay.Entries)
+	}
+	for i, esperada := range esperadas {
+		if replay.Entries[i] != esperada {
+			t.Errorf("Entrada %d: esperado %+v, obtido %+v", i, esperada, replay.Entries[i])
+		}
+	}
+	// A linha inválida e o JSON sem caminho são ignorados
+	if replay.Skipped != 2 {
+		t.Errorf("Esperado 2 linhas ignoradas, obtido %d", replay.Skipped)
+	}
+
+	if _, err := ParseAccessLog(strings.NewReader("nada reconhecível\n")); err == nil {
+		t.Error("Esperado erro para log sem requisições")
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	casos := map[string]string{
+		"/users/42?full=1":                               "/users/:id",
+		"/orders/9f86d081884c7d65/items":                 "/orders/:id/items",
+		"/sessions/123e4567-e89b-12d3-a456-426614174000": "/sessions/:id",
+		"/v2/produtos":                                   "/v2/produtos",
+		"/":                                              "/",
+	}
+	for caminho, esperado := range casos {
+		if obtido := normalizePath(caminho); obtido != esperado {
+			t.Errorf("%s: esperado %s, obtido %s", caminho, esperado, obtido)
+		}
+	}
+}
+
+func TestRun_ReplayPreservaInstantesEMetricasPorCaminho(t *testing.T) {
+	var mu sync.Mutex
+	chegadas := make(map[string]time.Time)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		chegadas[r.Method+" "+r.URL.RequestURI()] = time.Now()
+		mu.Unlock()
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	replay := &Replay{Speed: 10, Entries: []LogEntry{
+		{Offset: 0, Method: "GET", Path: "/users/1"},
+		{Offset: 500 * time.Millisecond, Method: "GET", Path: "/users/2?x=1"},
+		{Offset: 2 * time.Second, Method: "POST", Path: "/missing"},
+	}}
+	config := Config{RequestSpec: RequestSpec{URL: server.URL + "/"}, Replay: replay, Concurrency: 2}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	report := Run(context.Background(), config)
+
+	if report.TotalRequests != 3 || len(chegadas) != 3 {
+		t.Fatalf("Esperado as 3 requisições do log, obtido %d (%v)", report.TotalRequests, chegadas)
+	}
+	// Com aceleração 10x, os 2s do log viram 200ms
+	intervalo := chegadas["POST /missing"].Sub(chegadas["GET /users/1"])
+	if intervalo < 180*time.Millisecond || intervalo > 400*time.Millisecond {
+		t.Errorf("Esperado cerca de 200ms entre a primeira e a última requisição, obtido %v", intervalo)
+	}
+
+	if len(report.Tags) != 2 || report.Tags[0].Tag != "GET /users/:id" || report.Tags[0].TotalRequests != 2 {
+		t.Fatalf("Métricas por caminho incorretas: %+v", report.Tags)
+	}
+	if report.Tags[1].Tag != "POST /missing" || report.Tags[1].Non2xxCount != 1 {
+		t.Errorf("Esperado não-2xx no caminho /missing, obtido %+v", report.Tags[1])
+	}
+}
+
+func TestReplay_Validacao(t *testing.T) {
+	replay := &Replay{Entries: []LogEntry{{Method: "GET", Path: "/"}}}
+	casos := map[string]Config{
+		"sem URL base":        {Replay: replay, Concurrency: 1},
+		"com requests":        {RequestSpec: RequestSpec{URL: "http://x"}, Replay: replay, Requests: 10, Concurrency: 1},
+		"com cenário":         {RequestSpec: RequestSpec{URL: "http://x"}, Replay: replay, Scenario: &Scenario{}, Concurrency: 1},
+		"sem entradas":        {RequestSpec: RequestSpec{URL: "http://x"}, Replay: &Replay{}, Concurrency: 1},
+		"velocidade negativa": {RequestSpec: RequestSpec{URL: "http://x"}, Replay: &Replay{Entries: replay.Entries, Speed: -1}, Concurrency: 1},
+	}
+	for nome, config := range casos {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: esperado erro", nome)
+		}
+	}
+}
+
+func TestSplitConfig_Replay(t *testing.T) {
+	replay := &Replay{Speed: 2}
+	for i := range 5 {
+		replay.Entries = append(replay.Entries, LogEntry{Offset: time.Duration(i) * time.Second, Method: "GET", Path: "/"})
+	}
+	config := Config{RequestSpec: RequestSpec{URL: "http://localhost"}, Replay: replay, Concurrency: 4}
+
+	partes, err := splitConfig(config, 2)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	// As entradas são alternadas entre os agentes, mantendo os instantes originais
+	if len(partes[0].Replay.Entries) != 3 || len(partes[1].Replay.Entries) != 2 ||
+		partes[1].Replay.Entries[0].Offset != time.Second || partes[1].Replay.Speed != 2 {
+		t.Errorf("Log dividido incorretamente: %+v / %+v", partes[0].Replay, partes[1].Replay)
+	}
+	for _, parte := range partes {
+		if err := parte.Validate(); err != nil {
+			t.Errorf("Parte inválida: %v", err)
+		}
+	}
+}
@@ -1,40 +1,467 @@
 package stresstest
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
 
-// PrintReport exibe o relatório final do teste de carga de forma formatada e amigável.
-// Mostra métricas importantes como tempo total, throughput, códigos de status e taxa de erro.
-// O relatório é exibido no terminal com emojis e formatação visual para facilitar leitura.
+// PrintReport exibe o relatório final no terminal (os.Stdout). Ver WriteReport.
 func PrintReport(report Report) {
+	WriteReport(os.Stdout, report)
+}
+
+// WriteReport escreve o relatório final do teste de carga de forma formatada e amigável.
+// Mostra métricas importantes como tempo total, throughput, códigos de status e taxa de erro,
+// com emojis e formatação visual para facilitar leitura. Retorna o primeiro erro de escrita.
+func WriteReport(out io.Writer, report Report) error {
+	w := &errWriter{w: out}
 	// Cabeçalho do relatório com separadores visuais
-	fmt.Println("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("📊 RELATÓRIO DO TESTE DE CARGA")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	
+	fmt.Fprintln(w, "\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	if report.Aborted != "" {
+		// Teste interrompido antes do fim: as métricas cobrem apenas o que foi executado
+		fmt.Fprintln(w, "📊 RELATÓRIO PARCIAL DO TESTE DE CARGA")
+	} else {
+		fmt.Fprintln(w, "📊 RELATÓRIO DO TESTE DE CARGA")
+	}
+	fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
 	// Métricas principais do teste
-	fmt.Printf("⏱️  Tempo total gasto: %v\n", report.TotalTime)
-	fmt.Printf("📨 Total de requests realizados: %d\n", report.TotalRequests)
-	fmt.Printf("✅ Requests com status 200: %d\n", report.SuccessCount)
-	
+	fmt.Fprintf(w, "⏱️  Tempo total gasto: %v\n", report.TotalTime)
+	fmt.Fprintf(w, "📨 Total de requests realizados: %d\n", report.TotalRequests)
+	switch report.Protocol {
+	case ProtocolGRPC:
+		fmt.Fprintf(w, "✅ Chamadas com status OK: %d\n", report.SuccessCount)
+	case ProtocolWebSocket, ProtocolSSE:
+		fmt.Fprintf(w, "✅ Conexões estabelecidas: %d\n", report.SuccessCount)
+	default:
+		fmt.Fprintf(w, "✅ Requests com status 200: %d\n", report.SuccessCount)
+	}
+
 	// Mostra contagem de erros apenas se houver algum
 	if report.ErrorCount > 0 {
-		fmt.Printf("❌ Requests com erro: %d\n", report.ErrorCount)
+		fmt.Fprintf(w, "❌ Requests com erro: %d\n", report.ErrorCount)
+	}
+
+	// Respostas não-2xx (não-OK no gRPC) são exibidas separadamente dos erros de transporte
+	if report.Non2xxCount > 0 && report.Protocol == ProtocolGRPC {
+		fmt.Fprintf(w, "⚠️  Respostas com status diferente de OK: %d\n", report.Non2xxCount)
+	} else if report.Non2xxCount > 0 {
+		fmt.Fprintf(w, "⚠️  Respostas não-2xx: %d\n", report.Non2xxCount)
+	}
+
+	// Respostas que não atenderam alguma asserção não contam como sucesso
+	if report.AssertionFailedCount > 0 {
+		fmt.Fprintf(w, "🧪 Respostas reprovadas nas asserções: %d\n", report.AssertionFailedCount)
+	}
+
+	// Indica quando o teste foi interrompido antes de disparar todos os requests
+	if report.Aborted != "" {
+		fmt.Fprintf(w, "⛔ Teste interrompido: %s\n", report.Aborted)
+	}
+	if report.SinkFailures > 0 {
+		fmt.Fprintf(w, "📡 Envios de métricas que falharam: %d\n", report.SinkFailures)
 	}
-	
+	if report.Abandoned > 0 {
+		fmt.Fprintf(w, "🕳️  Requests cancelados ao fim do prazo de tolerância: %d (fora das métricas)\n", report.Abandoned)
+	}
+
 	// Seção de distribuição de códigos de status HTTP
-	fmt.Println("\n📈 Distribuição de códigos de status:")
+	fmt.Fprintln(w, "\n📈 Distribuição de códigos de status:")
 	for statusCode, count := range report.StatusCodes {
 		// Calcula a porcentagem de cada código de status
 		percentage := float64(count) / float64(report.TotalRequests) * 100
-		fmt.Printf("   %d: %d requests (%.1f%%)\n", statusCode, count, percentage)
+		if report.Protocol == ProtocolGRPC {
+			// Códigos gRPC são exibidos com o nome (ex: 14 Unavailable)
+			fmt.Fprintf(w, "   %d %s: %d chamadas (%.1f%%)\n", statusCode, grpcCodeName(statusCode), count, percentage)
+		} else {
+			fmt.Fprintf(w, "   %d: %d requests (%.1f%%)\n", statusCode, count, percentage)
+		}
+	}
+
+	// Seção de erros de transporte por categoria
+	if len(report.Errors) > 0 {
+		printErrors(w, report.Errors, report.TotalRequests)
+	}
+
+	// Seção de falhas por asserção
+	if len(report.AssertionFailures) > 0 {
+		printAssertionFailures(w, report.AssertionFailures, report.TotalRequests)
+	}
+
+	// Seção de limitação de taxa (respostas 429 e cabeçalhos de cota)
+	if report.Throttle != nil {
+		printThrottle(w, *report.Throttle, report.TotalRequests, report.Timeline)
+	}
+
+	// Seção de tempos de resposta (apenas se alguma resposta foi recebida)
+	if report.Latency.Total > 0 {
+		if report.Stream != nil {
+			// No streaming a duração de cada sessão é o tempo até a conexão ser estabelecida
+			fmt.Fprintln(w, "\n⏳ Tempos de conexão:")
+		} else {
+			fmt.Fprintln(w, "\n⏳ Tempos de resposta:")
+		}
+		fmt.Fprintf(w, "   mín: %v | média: %v | máx: %v\n",
+			round(report.Latency.Min), round(report.Latency.Mean()), round(report.Latency.Max))
+		fmt.Fprintf(w, "   p50: %v | p90: %v | p95: %v | p99: %v\n",
+			round(report.Latency.Percentile(50)), round(report.Latency.Percentile(90)),
+			round(report.Latency.Percentile(95)), round(report.Latency.Percentile(99)))
+	}
+
+	// Seção com a duração de cada fase dos requests (apenas HTTP)
+	if report.Phases.TTFB.Total > 0 {
+		printPhases(w, report.Phases)
 	}
-	
+
+	// Seção com as mensagens trocadas nas sessões WebSocket/SSE
+	if report.Stream != nil {
+		printStream(w, *report.Stream, report.TotalTime)
+	}
+
 	// Calcula e exibe throughput (requests por segundo)
 	if report.TotalRequests > 0 {
 		requestsPerSecond := float64(report.TotalRequests) / report.TotalTime.Seconds()
-		fmt.Printf("\n🚀 Requests por segundo: %.2f req/s\n", requestsPerSecond)
+		fmt.Fprintf(w, "\n🚀 Requests por segundo: %.2f req/s\n", requestsPerSecond)
+	}
+
+	// Volume de dados recebidos e throughput em bytes
+	if report.BytesReceived > 0 {
+		fmt.Fprintf(w, "📦 Dados recebidos: %s (média de %s por resposta) | %s/s\n",
+			formatBytes(float64(report.BytesReceived)),
+			formatBytes(float64(report.BytesReceived)/float64(report.Latency.Total)),
+			formatBytes(float64(report.BytesReceived)/report.TotalTime.Seconds()))
+	}
+
+	// Detalhamento por estágio do perfil de carga
+	if len(report.Stages) > 0 {
+		printStages(w, report.Stages)
+	}
+
+	// Detalhamento por passo do fluxo
+	if len(report.Steps) > 0 {
+		printSteps(w, report.Steps, report.Iterations, report.CompletedIterations)
 	}
-	
+
+	// Detalhamento por tag (endpoint, requisição do cenário, caminho do replay ou tag própria)
+	if len(report.Tags) > 0 {
+		printTags(w, report.Tags)
+	}
+
 	// Rodapé do relatório
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-}
\ No newline at end of file
+	fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	return w.err
+}
+
+// printStages exibe uma tabela com as métricas de cada estágio do perfil de carga.
+func printStages(w io.Writer, stages []StageReport) {
+	fmt.Fprintln(w, "\n🪜 Métricas por estágio:")
+	fmt.Fprintf(w, "   %-12s %8s %8s %9s %10s %10s %10s %7s\n",
+		"estágio", "alvo", "requests", "req/s", "p50", "p95", "p99", "erros")
+
+	for i, stage := range stages {
+		// Taxa efetivamente alcançada durante o estágio
+		rps := float64(stage.TotalRequests) / stage.Duration.Seconds()
+		fmt.Fprintf(w, "   %-12s %8d %8d %9.2f %10v %10v %10v %7d\n",
+			stageLabel(stage.Stage, i), stage.Target, stage.TotalRequests, rps,
+			round(stage.Latency.Percentile(50)), round(stage.Latency.Percentile(95)),
+			round(stage.Latency.Percentile(99)), stage.ErrorCount)
+	}
+}
+
+// printSteps exibe as iterações do fluxo e uma tabela com as métricas de cada passo.
+// Passos seguintes a uma falha não são executados, por isso os requests diminuem ao longo do fluxo.
+func printSteps(w io.Writer, steps []StepReport, iterations, completed int) {
+	fmt.Fprintln(w, "\n👣 Métricas por passo do fluxo:")
+	fmt.Fprintf(w, "   iterações: %d | concluídas: %d (%.1f%%)\n",
+		iterations, completed, ratio(completed, iterations)*100)
+	fmt.Fprintf(w, "   %-16s %8s %10s %10s %10s %7s %8s %8s\n",
+		"passo", "requests", "p50", "p95", "p99", "erros", "não-2xx", "falhas")
+
+	for _, step := range steps {
+		fmt.Fprintf(w, "   %-16s %8d %10v %10v %10v %7d %8d %8d\n",
+			step.Name, step.TotalRequests,
+			round(step.Latency.Percentile(50)), round(step.Latency.Percentile(95)),
+			round(step.Latency.Percentile(99)), step.ErrorCount, step.Non2xxCount, step.AssertionFailedCount)
+	}
+}
+
+// maxPrintedTags limita quantas tags aparecem no relatório em texto; o relatório JSON contém todas.
+const maxPrintedTags = 20
+
+// printTags exibe uma tabela com as métricas e a distribuição de status das tags mais frequentes.
+func printTags(w io.Writer, tags []TagReport) {
+	fmt.Fprintln(w, "\n🏷️  Métricas por tag:")
+	fmt.Fprintf(w, "   %-32s %8s %10s %10s %10s %7s %8s  %s\n",
+		"tag", "requests", "p50", "p95", "p99", "erros", "não-2xx", "status")
+
+	for _, tag := range tags[:min(len(tags), maxPrintedTags)] {
+		fmt.Fprintf(w, "   %-32s %8d %10v %10v %10v %7d %8d  %s\n",
+			tag.Tag, tag.TotalRequests,
+			round(tag.Latency.Percentile(50)), round(tag.Latency.Percentile(95)),
+			round(tag.Latency.Percentile(99)), tag.ErrorCount, tag.Non2xxCount, formatStatusCodes(tag.StatusCodes))
+	}
+	if hidden := len(tags) - maxPrintedTags; hidden > 0 {
+		fmt.Fprintf(w, "   ... e mais %d tags (ver o relatório JSON)\n", hidden)
+	}
+}
+
+// printPhases exibe os percentis de duração de cada fase dos requests.
+// Fases de conexão aparecem apenas para os requests que abriram uma nova conexão.
+func printPhases(w io.Writer, phases PhaseStats) {
+	fmt.Fprintln(w, "\n🔬 Fases do request:")
+	fmt.Fprintf(w, "   %-10s %8s %10s %10s %10s\n", "fase", "amostras", "p50", "p95", "p99")
+
+	rows := []struct {
+		name      string
+		histogram Histogram
+	}{
+		{"dns", phases.DNS},
+		{"connect", phases.Connect},
+		{"tls", phases.TLS},
+		{"ttfb", phases.TTFB},
+		{"transfer", phases.Transfer},
+	}
+	for _, row := range rows {
+		if row.histogram.Total == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "   %-10s %8d %10v %10v %10v\n", row.name, row.histogram.Total,
+			round(row.histogram.Percentile(50)), round(row.histogram.Percentile(95)),
+			round(row.histogram.Percentile(99)))
+	}
+}
+
+// printStream exibe as mensagens trocadas, a latência das mensagens e os motivos de desconexão
+// das sessões de streaming.
+func printStream(w io.Writer, stream StreamStats, total time.Duration) {
+	fmt.Fprintln(w, "\n📡 Mensagens:")
+	fmt.Fprintf(w, "   enviadas: %d | recebidas: %d | %.2f msgs/s recebidas\n",
+		stream.MessagesSent, stream.MessagesReceived, float64(stream.MessagesReceived)/total.Seconds())
+
+	if stream.MessageLatency.Total > 0 {
+		fmt.Fprintf(w, "   latência p50: %v | p95: %v | p99: %v | máx: %v\n",
+			round(stream.MessageLatency.Percentile(50)), round(stream.MessageLatency.Percentile(95)),
+			round(stream.MessageLatency.Percentile(99)), round(stream.MessageLatency.Max))
+	}
+
+	// Motivos de desconexão, do mais frequente para o menos frequente
+	reasons := make([]string, 0, len(stream.Disconnects))
+	sessions := 0
+	for reason, count := range stream.Disconnects {
+		reasons = append(reasons, reason)
+		sessions += count
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if stream.Disconnects[reasons[i]] != stream.Disconnects[reasons[j]] {
+			return stream.Disconnects[reasons[i]] > stream.Disconnects[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+
+	fmt.Fprintln(w, "\n🔌 Desconexões:")
+	for _, reason := range reasons {
+		count := stream.Disconnects[reason]
+		fmt.Fprintf(w, "   %s: %d sessões (%.1f%%)\n", reason, count, ratio(count, sessions)*100)
+	}
+}
+
+// maxThrottleRows é o número máximo de linhas da evolução das respostas 429 ao longo do teste;
+// em testes longos os intervalos da linha do tempo são agrupados.
+const maxThrottleRows = 10
+
+// printThrottle exibe as respostas recusadas por limite de taxa, os cabeçalhos de cota anunciados
+// pelo servidor e a proporção de respostas 429 ao longo do teste.
+func printThrottle(w io.Writer, throttle ThrottleStats, total int, timeline []TimelineBucket) {
+	fmt.Fprintln(w, "\n🚦 Limitação de taxa:")
+	if throttle.Throttled > 0 {
+		fmt.Fprintf(w, "   respostas 429: %d (%.1f%%) | primeira após %v\n",
+			throttle.Throttled, ratio(throttle.Throttled, total)*100, round(throttle.FirstThrottle))
+	} else {
+		fmt.Fprintln(w, "   nenhuma resposta 429")
+	}
+
+	if throttle.RetryAfter.Total > 0 {
+		fmt.Fprintf(w, "   Retry-After: p50: %v | p95: %v | máx: %v (%d respostas)\n",
+			round(throttle.RetryAfter.Percentile(50)), round(throttle.RetryAfter.Percentile(95)),
+			round(throttle.RetryAfter.Max), throttle.RetryAfter.Total)
+	}
+	if len(throttle.Limits) > 0 {
+		limits := make([]int, 0, len(throttle.Limits))
+		for limit := range throttle.Limits {
+			limits = append(limits, limit)
+		}
+		sort.Ints(limits)
+		fmt.Fprint(w, "   X-RateLimit-Limit:")
+		for _, limit := range limits {
+			fmt.Fprintf(w, " %d (%d respostas)", limit, throttle.Limits[limit])
+		}
+		fmt.Fprintln(w)
+	}
+	if throttle.RemainingCount > 0 {
+		fmt.Fprintf(w, "   X-RateLimit-Remaining: mín: %d | média: %.1f | cota esgotada em %d respostas\n",
+			throttle.RemainingMin, float64(throttle.RemainingSum)/float64(throttle.RemainingCount), throttle.Exhausted)
+	}
+	if throttle.Reset.Total > 0 {
+		fmt.Fprintf(w, "   X-RateLimit-Reset: p50: %v | máx: %v\n",
+			round(throttle.Reset.Percentile(50)), round(throttle.Reset.Max))
+	}
+	if throttle.Backoffs > 0 {
+		fmt.Fprintf(w, "   esperas dos usuários virtuais: %d (total %v)\n", throttle.Backoffs, round(throttle.BackoffTime))
+	}
+
+	// Evolução da proporção de respostas 429, com os intervalos agrupados em até maxThrottleRows linhas
+	if throttle.Throttled == 0 || len(timeline) < 2 {
+		return
+	}
+	fmt.Fprintln(w, "   respostas 429 ao longo do teste:")
+	group := (len(timeline) + maxThrottleRows - 1) / maxThrottleRows
+	for i := 0; i < len(timeline); i += group {
+		var requests, throttled int
+		last := min(i+group, len(timeline))
+		for _, bucket := range timeline[i:last] {
+			requests += bucket.Requests
+			throttled += bucket.Throttled
+		}
+		percentage := ratio(throttled, requests) * 100
+		fmt.Fprintf(w, "   %12s %6.1f%% %s\n",
+			fmt.Sprintf("%v-%v", timeline[i].Start, timeline[last-1].Start+TimelineInterval),
+			percentage, strings.Repeat("█", int(percentage/5)))
+	}
+}
+
+// formatBytes formata uma quantidade de bytes usando a unidade mais adequada.
+func formatBytes(bytes float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	unit := 0
+	for bytes >= 1024 && unit < len(units)-1 {
+		bytes /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", bytes, units[unit])
+}
+
+// printErrors exibe os erros de transporte agrupados por categoria, com mensagens de exemplo.
+func printErrors(w io.Writer, errs map[string]ErrorStat, total int) {
+	fmt.Fprintln(w, "\n🧯 Erros por categoria:")
+
+	// Ordena as categorias da mais frequente para a menos frequente
+	kinds := make([]string, 0, len(errs))
+	for kind := range errs {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if errs[kinds[i]].Count != errs[kinds[j]].Count {
+			return errs[kinds[i]].Count > errs[kinds[j]].Count
+		}
+		return kinds[i] < kinds[j]
+	})
+
+	for _, kind := range kinds {
+		stat := errs[kind]
+		fmt.Fprintf(w, "   %s: %d requests (%.1f%%)\n", kind, stat.Count, ratio(stat.Count, total)*100)
+		for _, sample := range stat.Samples {
+			fmt.Fprintf(w, "      ↳ %s\n", sample)
+		}
+	}
+}
+
+// printAssertionFailures exibe quantas respostas falharam em cada asserção, da mais frequente
+// para a menos frequente.
+func printAssertionFailures(w io.Writer, failures map[string]int, total int) {
+	fmt.Fprintln(w, "\n🧪 Falhas por asserção:")
+
+	names := make([]string, 0, len(failures))
+	for name := range failures {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if failures[names[i]] != failures[names[j]] {
+			return failures[names[i]] > failures[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		fmt.Fprintf(w, "   %s: %d requests (%.1f%%)\n", name, failures[name], ratio(failures[name], total)*100)
+	}
+}
+
+// PrintChecks exibe o resultado dos thresholds no terminal (os.Stdout). Ver WriteChecks.
+func PrintChecks(results []CheckResult) {
+	WriteChecks(os.Stdout, results)
+}
+
+// WriteChecks escreve uma tabela com o resultado de cada threshold avaliado.
+func WriteChecks(out io.Writer, results []CheckResult) error {
+	w := &errWriter{w: out}
+	fmt.Fprintln(w, "\n🎯 Thresholds:")
+	fmt.Fprintf(w, "   %-6s %-24s %14s\n", "status", "critério", "observado")
+
+	for _, result := range results {
+		status := "✅ ok"
+		if !result.Passed {
+			status = "❌ falha"
+		}
+		fmt.Fprintf(w, "   %-6s %-24s %14s\n", status, result.Threshold.Expression,
+			formatMetric(result.Threshold.Metric, result.Actual))
+	}
+
+	failed := FailedChecks(results)
+	fmt.Fprintf(w, "\n   %d de %d thresholds atendidos\n", len(results)-failed, len(results))
+	fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	return w.err
+}
+
+// WriteJSONChecks escreve o resultado dos thresholds como uma linha JSON ("type":"checks").
+func WriteJSONChecks(w io.Writer, results []CheckResult) error {
+	return json.NewEncoder(w).Encode(struct {
+		Type   string        `json:"type"`
+		Checks []CheckResult `json:"checks"`
+	}{Type: "checks", Checks: results})
+}
+
+// round arredonda durações para exibição, mantendo precisão proporcional à grandeza.
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
+
+// errWriter guarda o primeiro erro de escrita, para que os relatórios em texto não precisem
+// verificar o retorno de cada fmt.Fprintf. Após um erro as escritas seguintes são ignoradas.
+type errWriter struct {
+	w   io.Writer // Destino das escritas
+	err error     // Primeiro erro ocorrido
+}
+
+// Write repassa os dados ao destino enquanto nenhuma escrita tiver falhado.
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.err = err
+	return n, err
+}
+
+// jsonReport é o formato do relatório na saída JSON, identificado pelo campo type.
+type jsonReport struct {
+	Type string `json:"type"` // Sempre "report"
+	Report
+}
+
+// WriteJSONReport escreve o relatório como uma única linha JSON, compatível com a saída NDJSON
+// dos snapshots de progresso.
+func WriteJSONReport(w io.Writer, report Report) error {
+	return json.NewEncoder(w).Encode(jsonReport{Type: "report", Report: report})
+}
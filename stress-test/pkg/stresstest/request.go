@@ -0,0 +1,151 @@
+package stresstest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RequestSpec descreve a requisição HTTP que será disparada contra o serviço.
+// Além da URL permite personalizar método, cabeçalhos, corpo, parâmetros de query e autenticação.
+type RequestSpec struct {
+	Method      string            `yaml:"method,omitempty"`       // Método HTTP (GET quando vazio)
+	URL         string            `yaml:"url,omitempty"`          // URL do serviço web que será testado
+	Headers     map[string]string `yaml:"headers,omitempty"`      // Cabeçalhos adicionais enviados em cada request
+	Query       map[string]string `yaml:"query,omitempty"`        // Parâmetros de query acrescentados à URL
+	Body        string            `yaml:"body,omitempty"`         // Corpo da requisição
+	ContentType string            `yaml:"content_type,omitempty"` // Valor do cabeçalho Content-Type
+	BasicAuth   *BasicAuth        `yaml:"basic_auth,omitempty"`   // Credenciais de autenticação básica (opcional)
+	BearerToken string            `yaml:"bearer_token,omitempty"` // Token enviado no cabeçalho Authorization: Bearer (opcional)
+	Assertions  *Assertions       `yaml:"assert,omitempty"`       // Validações aplicadas a cada resposta (opcional)
+	Tag         string            `yaml:"tag,omitempty"`          // Tag das métricas da requisição no relatório (opcional, ver Config.TagBy)
+}
+
+// BasicAuth contém as credenciais para autenticação HTTP básica.
+type BasicAuth struct {
+	Username string `yaml:"username,omitempty"` // Nome de usuário
+	Password string `yaml:"password,omitempty"` // Senha
+}
+
+// ParseBasicAuth interpreta credenciais no formato "usuário:senha".
+func ParseBasicAuth(value string) (*BasicAuth, error) {
+	username, password, ok := strings.Cut(value, ":")
+	if !ok || username == "" {
+		return nil, fmt.Errorf("credenciais inválidas %q: use o formato usuário:senha", value)
+	}
+	return &BasicAuth{Username: username, Password: password}, nil
+}
+
+// ParseHeader interpreta um cabeçalho no formato "Nome: valor".
+func ParseHeader(value string) (string, string, error) {
+	name, content, ok := strings.Cut(value, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("cabeçalho inválido %q: use o formato Nome: valor", value)
+	}
+	return name, strings.TrimSpace(content), nil
+}
+
+// ParseQueryParam interpreta um parâmetro de query no formato "chave=valor".
+func ParseQueryParam(value string) (string, string, error) {
+	key, content, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("parâmetro de query inválido %q: use o formato chave=valor", value)
+	}
+	return key, content, nil
+}
+
+// validate verifica se a especificação da requisição é consistente.
+func (r *RequestSpec) validate() error {
+	// Verifica se a URL foi fornecida
+	if r.URL == "" {
+		return fmt.Errorf("URL é obrigatória")
+	}
+
+	// A URL precisa ser absoluta e usar HTTP ou HTTPS
+	parsed, err := url.Parse(r.URL)
+	if err != nil {
+		return fmt.Errorf("URL inválida: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("URL deve usar o esquema http ou https")
+	}
+
+	// Apenas um mecanismo de autenticação pode ser usado por vez
+	if r.BasicAuth != nil && r.BearerToken != "" {
+		return fmt.Errorf("use autenticação básica ou bearer token, não ambos")
+	}
+
+	// Compila as expressões das asserções para detectar erros antes do teste
+	if r.Assertions != nil {
+		if err := r.Assertions.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// method retorna o método HTTP da requisição, usando GET como padrão.
+func (r *RequestSpec) method() string {
+	if r.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(r.Method)
+}
+
+// buildRequest monta um *http.Request a partir da especificação.
+// Um novo request é criado a cada chamada, pois o corpo só pode ser lido uma vez.
+func (r *RequestSpec) buildRequest() (*http.Request, error) {
+	target, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Acrescenta os parâmetros de query aos já existentes na URL
+	if len(r.Query) > 0 {
+		query := target.Query()
+		for key, value := range r.Query {
+			query.Set(key, value)
+		}
+		target.RawQuery = query.Encode()
+	}
+
+	// Corpo da requisição (nil quando vazio, para não enviar Content-Length: 0 em GETs)
+	var body io.Reader
+	if r.Body != "" {
+		body = strings.NewReader(r.Body)
+	}
+
+	req, err := http.NewRequest(r.method(), target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cabeçalhos personalizados
+	for name, value := range r.Headers {
+		// O cabeçalho Host precisa ser definido no próprio request
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+
+	// Content-Type explícito tem prioridade sobre o informado nos cabeçalhos
+	if r.ContentType != "" {
+		req.Header.Set("Content-Type", r.ContentType)
+	}
+
+	// Autenticação
+	if r.BasicAuth != nil {
+		req.SetBasicAuth(r.BasicAuth.Username, r.BasicAuth.Password)
+	}
+	if r.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+r.BearerToken)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,91 @@
+package stresstest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBuildRequest(t *testing.T) {
+	spec := RequestSpec{
+		Method:      "post",
+		URL:         "http://localhost:8000/order?origem=teste",
+		Headers:     map[string]string{"API_KEY": "abc123", "Host": "pedidos.local"},
+		Query:       map[string]string{"lote": "7"},
+		Body:        `{"id":"1","price":10,"tax":1}`,
+		ContentType: "application/json",
+		BearerToken: "token",
+	}
+
+	req, err := spec.buildRequest()
+	if err != nil {
+		t.Fatalf("Erro ao montar requisição: %v", err)
+	}
+
+	if req.Method != http.MethodPost {
+		t.Errorf("Método incorreto: %s", req.Method)
+	}
+	if req.URL.Query().Get("origem") != "teste" || req.URL.Query().Get("lote") != "7" {
+		t.Errorf("Parâmetros de query incorretos: %s", req.URL.RawQuery)
+	}
+	if req.Header.Get("API_KEY") != "abc123" || req.Host != "pedidos.local" {
+		t.Errorf("Cabeçalhos incorretos: %v (host %s)", req.Header, req.Host)
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Content-Type incorreto: %s", req.Header.Get("Content-Type"))
+	}
+	if req.Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("Authorization incorreto: %s", req.Header.Get("Authorization"))
+	}
+
+	data, _ := io.ReadAll(req.Body)
+	if string(data) != spec.Body {
+		t.Errorf("Corpo incorreto: %s", data)
+	}
+}
+
+func TestRequestSpec_Validate(t *testing.T) {
+	invalidas := []RequestSpec{
+		{},
+		{URL: "localhost:8080"},
+		{URL: "http://localhost", BasicAuth: &BasicAuth{Username: "u"}, BearerToken: "t"},
+	}
+	for _, spec := range invalidas {
+		if err := spec.validate(); err == nil {
+			t.Errorf("Esperado erro para a especificação %+v", spec)
+		}
+	}
+}
+
+func TestRun_RequisicaoPersonalizada(t *testing.T) {
+	var recebidos atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		data, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || !ok || user != "admin" || pass != "segredo" || string(data) != "payload" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		recebidos.Add(1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	report := Run(context.Background(), Config{
+		RequestSpec: RequestSpec{
+			Method:    http.MethodPost,
+			URL:       server.URL,
+			Body:      "payload",
+			BasicAuth: &BasicAuth{Username: "admin", Password: "segredo"},
+		},
+		Requests:    20,
+		Concurrency: 4,
+	})
+
+	if report.StatusCodes[http.StatusCreated] != 20 || recebidos.Load() != 20 {
+		t.Errorf("Esperado 20 requests aceitos, obtido %v", report.StatusCodes)
+	}
+}
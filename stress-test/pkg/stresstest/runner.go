@@ -1,113 +1,700 @@
 package stresstest
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptrace"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// dispatchInterval define de quanto em quanto tempo o despachante do perfil de carga
+// recalcula quantos requests já deveriam ter sido disparados.
+const dispatchInterval = 5 * time.Millisecond
+
+// DefaultGracePeriod é o tempo padrão que os requests em andamento têm para terminar depois
+// que o teste é interrompido.
+const DefaultGracePeriod = 5 * time.Second
+
+// loadTest mantém o estado compartilhado durante a execução de um teste de carga.
+type loadTest struct {
+	config Config   // Configuração do teste
+	exec   Executor // Executor dos requests (HTTP, gRPC, WebSocket, SSE ou personalizado) compartilhado por todos os workers
+
+	inFlight     atomic.Int64 // Requests em andamento no momento
+	lastSnapshot time.Time    // Momento do último snapshot de progresso
+	errors       atomic.Int64 // Erros de transporte registrados (para o limite MaxErrors)
+
+	abort       chan struct{} // Fechado para interromper o disparo de novos requests
+	abortOnce   sync.Once     // Garante que a interrupção ocorra uma única vez
+	abortReason string        // Motivo da interrupção
+
+	requests       context.Context    // Contexto dos requests, cancelado ao fim do prazo de tolerância
+	cancelRequests context.CancelFunc // Cancela os requests ainda em andamento
+	abandoned      atomic.Int64       // Requests cancelados ao fim do prazo de tolerância
+
+	sinks    *sinkPusher  // Envio das métricas aos sinks (nil sem sinks)
+	onResult func(Result) // Chamada para cada resultado contabilizado (nil sem hook)
+}
+
 // Run executa o teste de carga conforme a configuração fornecida.
-// Cria goroutines para fazer requests HTTP de forma concorrente e coleta os resultados.
-// Retorna um relatório consolidado com todas as métricas do teste.
-func Run(config Config) Report {
+// Um pool fixo de Concurrency workers consome os requests disparados e coleta os resultados,
+// todos compartilhando o mesmo cliente (HTTP ou gRPC) para reaproveitar conexões.
+//
+// Quando ctx é cancelado (ex: SIGINT), o disparo de novos requests é interrompido e os requests
+// em andamento têm até Config.GracePeriod para terminar; os que não terminam são cancelados e
+// descartados. O relatório é retornado mesmo assim, marcado como parcial (Report.Aborted).
+//
+// A configuração não é validada; para validação e opções adicionais use NewRunner. Um cenário ou
+// fluxo que não pode ser preparado (ex: feed ausente) produz um relatório vazio com o motivo em
+// Report.Aborted, sem disparar requests.
+func Run(ctx context.Context, config Config) Report {
+	// Prepara o cenário ou o fluxo caso a configuração não tenha sido validada previamente
+	var err error
+	if config.Scenario != nil {
+		err = config.Scenario.prepare()
+	}
+	if config.Flow != nil && err == nil {
+		err = config.Flow.prepare()
+	}
+	if err != nil {
+		report := newReport(config)
+		report.Aborted = fmt.Sprintf("configuração inválida: %v", err)
+		return report
+	}
+	if config.Replay != nil {
+		config.Replay.prepare()
+	}
+	return runLoadTest(ctx, config, newExecutor(config), nil)
+}
+
+// Runner executa testes de carga a partir de código Go, por exemplo em testes e benchmarks
+// (go test). Diferente de Run, valida a configuração antes de executar e aceita opções como
+// um executor próprio e um hook chamado a cada resultado. Pode ser executado várias vezes.
+//
+// Exemplo em um benchmark:
+//
+//	runner := stresstest.NewRunner(stresstest.Config{
+//		RequestSpec: stresstest.RequestSpec{URL: server.URL},
+//		Requests:    1000,
+//		Concurrency: 10,
+//	})
+//	for i := 0; i < b.N; i++ {
+//		report, err := runner.Run(ctx)
+//		...
+//	}
+type Runner struct {
+	config   Config       // Configuração do teste
+	executor Executor     // Executor próprio (nil usa o executor do protocolo configurado)
+	onResult func(Result) // Hook chamado a cada resultado (opcional)
+}
+
+// Option personaliza um Runner.
+type Option func(*Runner)
+
+// WithExecutor substitui o alvo da configuração por um executor próprio. A configuração passa a
+// definir apenas a carga (requests ou estágios, concorrência, thresholds, etc.) e não pode ter
+// URL, cenário, fluxo, gRPC, streaming ou replay. O executor é fechado ao final de cada execução.
+func WithExecutor(executor Executor) Option {
+	return func(r *Runner) {
+		r.executor = executor
+	}
+}
+
+// WithResultHook registra uma função chamada para cada resultado contabilizado no relatório
+// (em fluxos, uma vez por passo). A função é chamada concorrentemente pelos workers e deve ser
+// rápida, pois atrasa o próximo request do worker. Requests cancelados ao fim do prazo de
+// tolerância não são repassados.
+func WithResultHook(hook func(Result)) Option {
+	return func(r *Runner) {
+		r.onResult = hook
+	}
+}
+
+// NewRunner cria um Runner com a configuração e as opções informadas.
+func NewRunner(config Config, options ...Option) *Runner {
+	r := &Runner{config: config}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// Run valida a configuração e executa o teste de carga (ver a função Run). Retorna erro apenas
+// quando a configuração é inválida; o cancelamento de ctx produz um relatório parcial, marcado
+// em Report.Aborted.
+func (r *Runner) Run(ctx context.Context) (Report, error) {
+	config := r.config
+	if r.executor == nil {
+		if err := config.Validate(); err != nil {
+			return Report{}, err
+		}
+		return runLoadTest(ctx, config, newExecutor(config), r.onResult), nil
+	}
+
+	// Com executor próprio apenas os parâmetros de carga são verificados
+	if config.URL != "" || config.Scenario != nil || config.Flow != nil || config.GRPC != nil || config.Stream != nil || config.Replay != nil {
+		return Report{}, fmt.Errorf("o executor próprio não pode ser combinado com URL, cenário, fluxo, gRPC, streaming ou replay")
+	}
+	if err := config.validateLoad(); err != nil {
+		return Report{}, err
+	}
+	return runLoadTest(ctx, config, r.executor, r.onResult), nil
+}
+
+// runLoadTest executa o teste com o executor informado, chamando onResult (quando não nil)
+// para cada resultado contabilizado.
+func runLoadTest(ctx context.Context, config Config, exec Executor, onResult func(Result)) Report {
+	r := &loadTest{
+		config:   config,
+		exec:     exec,
+		onResult: onResult,
+		abort:    make(chan struct{}),
+	}
+	// Fecha as conexões ao final para não deixá-las abertas no processo
+	defer r.exec.Close()
+
+	// Os requests não herdam ctx: ao cancelá-lo, os que estão em andamento ainda podem terminar
+	r.requests, r.cancelRequests = context.WithCancel(context.Background())
+	defer r.cancelRequests()
+
+	// Interrompe o teste quando ctx é cancelado
+	stopWatching := context.AfterFunc(ctx, func() {
+		r.stop("teste cancelado: " + context.Cause(ctx).Error())
+	})
+	defer stopWatching()
+
+	return r.run()
+}
+
+// run dispara os requests, distribui entre os workers e consolida os resultados.
+// Cada worker agrega seus resultados em um acumulador próprio e os acumuladores são somados
+// ao final, de forma que a memória usada não cresce com o número de requests.
+func (r *loadTest) run() Report {
 	// Marca o tempo de início do teste para calcular duração total
 	startTime := time.Now()
-	
-	// Canal para receber os resultados de cada request individual
-	results := make(chan Result, config.Requests)
-	
-	// WaitGroup para aguardar conclusão de todas as goroutines
+
+	// Canal de trabalhos: cada item é o índice do estágio em que o request foi disparado
+	jobs := make(chan int, r.config.Concurrency)
+
+	// WaitGroup para aguardar conclusão de todos os workers
 	var wg sync.WaitGroup
-	
-	// Semáforo para controlar o número de requests simultâneos
-	// Funciona como um pool de workers limitado pela concorrência
-	semaphore := make(chan struct{}, config.Concurrency)
-	
-	// Cria uma goroutine para cada request que será feito
-	for i := 0; i < config.Requests; i++ {
+
+	// Pool fixo de workers limitado pela concorrência, cada um com seu acumulador. O conjunto de
+	// tags é único para que o limite de tags valha para o teste inteiro
+	tags := newTagSet()
+	accumulators := make([]*accumulator, r.config.Concurrency)
+	for i := range accumulators {
+		accumulators[i] = newAccumulator(r.config, startTime, tags)
+
 		wg.Add(1)
-		go func() {
+		go func(acc *accumulator) {
 			defer wg.Done()
-			
-			// Adquire um slot no semáforo (bloqueia se atingir limite)
-			semaphore <- struct{}{}
-			// Libera o slot ao terminar a função
-			defer func() { <-semaphore }()
-			
-			// Executa o request HTTP e envia resultado para o canal
-			result := makeRequest(config.URL)
-			results <- result
-		}()
-	}
-	
-	// Goroutine para fechar o canal de resultados quando todos requests terminarem
-	go func() {
-		wg.Wait()     // Aguarda todas as goroutines de request terminarem
-		close(results) // Fecha o canal para sinalizar fim da coleta
-	}()
-	
-	// Inicializa o relatório que será preenchido com os dados coletados
-	report := Report{
-		StatusCodes: make(map[int]int), // Mapa para contar códigos de status
-	}
-	
-	// Processa cada resultado recebido do canal
-	for result := range results {
-		report.TotalRequests++
-		
-		// Se houve erro de rede/timeout, conta como erro
-		if result.Error != nil {
-			report.ErrorCount++
-		} else {
-			// Conta o código de status retornado
-			report.StatusCodes[result.StatusCode]++
-			
-			// Conta requests bem-sucedidos (status 200)
-			if result.StatusCode == 200 {
-				report.SuccessCount++
+			r.worker(jobs, acc)
+		}(accumulators[i])
+	}
+
+	// Linha do tempo e acompanhamento em tempo real, quando solicitado
+	stopTimeline := startTimeline(startTime, accumulators)
+	stopProgress := r.startProgress(startTime, accumulators)
+
+	// Dispara os requests e encerra o canal de trabalhos ao final (ou ao interromper o teste)
+	dispatch := func(stage int) bool {
+		// A interrupção tem prioridade sobre o disparo quando ambos estão prontos
+		select {
+		case <-r.abort:
+			return false
+		default:
+		}
+
+		select {
+		case jobs <- stage:
+			return true
+		case <-r.abort:
+			return false
+		}
+	}
+	if r.config.Replay != nil {
+		// Replay: cada entrada do log é disparada no seu instante relativo
+		dispatchReplay(r.config.Replay, startTime, r.abort, dispatch)
+	} else if len(r.config.Stages) > 0 {
+		// Perfil de carga: a taxa de disparo segue os estágios
+		dispatchStages(r.config.Stages, startTime, r.abort, dispatch)
+	} else {
+		// Quantidade fixa: dispara todos os requests o mais rápido que os workers consumirem
+		for i := 0; i < r.config.Requests; i++ {
+			if !dispatch(-1) {
+				break
 			}
 		}
 	}
-	
+	close(jobs)
+
+	// Aguarda todos os workers terminarem
+	wg.Wait()
+
+	// Encerra o acompanhamento emitindo o snapshot final
+	stopProgress()
+	timeline := stopTimeline()
+
+	// Consolida os acumuladores de todos os workers no relatório final
+	report := newReport(r.config)
+	report.Timeline = timeline
+	var merged tagMerger
+	for _, acc := range accumulators {
+		acc.mergeInto(&report, &merged)
+	}
+	report.Tags = merged.sorted()
+
 	// Calcula o tempo total decorrido do teste
 	report.TotalTime = time.Since(startTime)
-	
+	// O motivo só é lido após o fechamento de abort, que acontece depois da escrita em stop:
+	// o cancelamento do contexto pode chamar stop a qualquer momento, inclusive agora
+	select {
+	case <-r.abort:
+		report.Aborted = r.abortReason
+	default:
+	}
+	report.Abandoned = int(r.abandoned.Load())
+	if r.sinks != nil {
+		report.SinkFailures = int(r.sinks.failures.Load())
+	}
+
+	return report
+}
+
+// stop interrompe o disparo de novos requests. Os requests em andamento têm até o prazo de
+// tolerância (Config.GracePeriod) para terminar; depois disso são cancelados.
+func (r *loadTest) stop(reason string) {
+	r.abortOnce.Do(func() {
+		r.abortReason = reason
+		close(r.abort)
+		time.AfterFunc(orDefault(r.config.GracePeriod, DefaultGracePeriod), r.cancelRequests)
+	})
+}
+
+// worker executa requests enquanto houver trabalhos no canal, agregando os resultados.
+func (r *loadTest) worker(jobs <-chan int, acc *accumulator) {
+	// Espera pendente após uma resposta 429, feita antes do próximo request (Config.RespectRetryAfter)
+	var wait time.Duration
+
+	for stage := range jobs {
+		// Cliente bem-comportado: aguarda o tempo pedido pelo servidor antes do próximo request
+		if wait > 0 {
+			r.backoff(wait, acc)
+			wait = 0
+		}
+
+		// Trabalhos ainda no buffer quando o teste é interrompido não são executados
+		select {
+		case <-r.abort:
+			continue
+		default:
+		}
+
+		r.inFlight.Add(1)
+		result := r.exec.Execute(r.requests)
+		r.record(result, stage, acc)
+		r.inFlight.Add(-1)
+
+		if r.config.RespectRetryAfter {
+			// No fluxo a iteração termina no passo recusado
+			if n := len(result.Steps); n > 0 {
+				result = result.Steps[n-1]
+			}
+			wait = min(retryWait(result), orDefault(r.config.MaxRetryAfter, DefaultMaxRetryAfter))
+		}
+	}
+}
+
+// backoff faz o worker aguardar após uma resposta 429. A espera termina antes se o teste for
+// interrompido.
+func (r *loadTest) backoff(wait time.Duration, acc *accumulator) {
+	start := time.Now()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-r.abort:
+	}
+	acc.addBackoff(time.Since(start))
+}
+
+// record contabiliza o resultado de um request e interrompe o teste ao atingir o limite de erros.
+func (r *loadTest) record(result Result, stage int, acc *accumulator) {
+	// Fluxos produzem um resultado para cada passo executado na iteração
+	if r.config.Flow != nil {
+		r.recordIteration(result.Steps, stage, acc)
+		return
+	}
+
+	// Requests cancelados ao fim do prazo de tolerância não refletem o serviço: são descartados
+	if result.Error != nil && r.requests.Err() != nil {
+		r.abandoned.Add(1)
+		return
+	}
+
+	result.Stage = stage
+	acc.add(result)
+	r.notify(result)
+	r.countError(result)
+}
+
+// recordIteration contabiliza os passos executados em uma iteração do fluxo.
+func (r *loadTest) recordIteration(steps []Result, stage int, acc *accumulator) {
+	// A iteração para no primeiro erro, então apenas o último passo pode ter sido cancelado
+	if n := len(steps); n > 0 && steps[n-1].Error != nil && r.requests.Err() != nil {
+		r.abandoned.Add(1)
+		steps = steps[:n-1]
+	}
+	if len(steps) == 0 {
+		return
+	}
+
+	for i := range steps {
+		steps[i].Stage = stage
+	}
+	completed := len(steps) == len(r.config.Flow.Steps) && stepPassed(steps[len(steps)-1])
+	acc.addIteration(steps, completed)
+
+	for _, step := range steps {
+		r.notify(step)
+		r.countError(step)
+	}
+}
+
+// notify repassa o resultado ao hook configurado. É chamada concorrentemente pelos workers.
+func (r *loadTest) notify(result Result) {
+	if r.onResult != nil {
+		r.onResult(result)
+	}
+}
+
+// countError conta os erros de transporte e interrompe o teste ao atingir o limite de erros.
+func (r *loadTest) countError(result Result) {
+	// Erros de transporte em sequência indicam que o alvo está fora do ar
+	if result.Error != nil && r.config.MaxErrors > 0 && r.errors.Add(1) >= int64(r.config.MaxErrors) {
+		r.stop(fmt.Sprintf("limite de %d erros atingido", r.config.MaxErrors))
+	}
+}
+
+// startProgress inicia a coleta periódica de snapshots, usada para o acompanhamento em tempo
+// real (Config.Progress), para o envio de métricas aos sinks (Config.Sinks) e para interromper
+// o teste quando um threshold é violado (Config.AbortOnFail).
+// Retorna uma função que interrompe a coleta e processa o snapshot final.
+func (r *loadTest) startProgress(startTime time.Time, accumulators []*accumulator) (stop func()) {
+	watchThresholds := r.config.AbortOnFail && len(r.config.Thresholds) > 0
+	if r.config.Progress == nil && !watchThresholds && len(r.config.Sinks) == 0 {
+		return func() {}
+	}
+	if len(r.config.Sinks) > 0 {
+		r.sinks = startSinks(r.config.Sinks)
+	}
+
+	interval := r.config.ProgressInterval
+	if interval == 0 {
+		interval = DefaultProgressInterval
+	}
+
+	r.lastSnapshot = startTime
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				r.onSnapshot(r.snapshot(startTime, accumulators, false))
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+		r.onSnapshot(r.snapshot(startTime, accumulators, true))
+
+		// Aguarda o envio do snapshot final aos sinks
+		if r.sinks != nil {
+			r.sinks.close()
+		}
+	}
+}
+
+// onSnapshot repassa o snapshot ao acompanhamento e verifica se algum threshold já foi violado.
+func (r *loadTest) onSnapshot(snapshot Snapshot) {
+	if r.config.Progress != nil {
+		r.config.Progress(snapshot)
+	}
+	if r.sinks != nil {
+		r.sinks.send(snapshot)
+	}
+
+	if !r.config.AbortOnFail || snapshot.Final {
+		return
+	}
+	for _, threshold := range r.config.Thresholds {
+		if threshold.breached(snapshot) {
+			r.stop("threshold violado: " + threshold.Expression)
+			return
+		}
+	}
+}
+
+// snapshot coleta as métricas parciais de todos os workers.
+func (r *loadTest) snapshot(startTime time.Time, accumulators []*accumulator, final bool) Snapshot {
+	total := newStats()
+	var window Histogram
+	windowRequests := 0
+	for _, acc := range accumulators {
+		windowRequests += acc.collect(&total, &window)
+	}
+
+	// Taxa calculada sobre o tempo real decorrido desde o último snapshot
+	now := time.Now()
+	interval := now.Sub(r.lastSnapshot)
+	r.lastSnapshot = now
+
+	snapshot := Snapshot{
+		Type:        "snapshot",
+		Elapsed:     now.Sub(startTime),
+		Completed:   total.TotalRequests,
+		Planned:     r.config.plannedRequests(),
+		InFlight:    int(r.inFlight.Load()),
+		P50:         window.Percentile(50),
+		P90:         window.Percentile(90),
+		P95:         window.Percentile(95),
+		P99:         window.Percentile(99),
+		Errors:      total.ErrorCount,
+		Non2xx:      total.Non2xxCount,
+		StatusCodes: total.StatusCodes,
+		Final:       final,
+	}
+	if interval > 0 {
+		snapshot.RPS = float64(windowRequests) / interval.Seconds()
+	}
+	if total.TotalRequests > 0 {
+		snapshot.ErrorRate = float64(total.ErrorCount) / float64(total.TotalRequests)
+	}
+	return snapshot
+}
+
+// newReport cria um relatório vazio, já com uma entrada para cada estágio do perfil.
+func newReport(config Config) Report {
+	report := Report{Stats: newStats()}
+	switch {
+	case config.Stream != nil:
+		report.Protocol = config.Stream.Protocol
+	case config.GRPC != nil:
+		report.Protocol = ProtocolGRPC
+	}
+
+	if config.Flow != nil {
+		for i, step := range config.Flow.Steps {
+			report.Steps = append(report.Steps, StepReport{Name: step.label(i), Stats: newStats()})
+		}
+	}
+
+	var start time.Duration
+	for _, stage := range config.Stages {
+		report.Stages = append(report.Stages, StageReport{
+			Stage: stage,
+			Start: start,
+			Stats: newStats(),
+		})
+		start += stage.Duration
+	}
 	return report
 }
 
-// makeRequest executa uma única requisição HTTP GET para a URL especificada.
-// Mede o tempo de resposta e captura erros ou códigos de status.
-// Retorna um Result com as informações da requisição.
-func makeRequest(url string) Result {
+// dispatchStages dispara requests seguindo o perfil de carga até o fim do último estágio.
+// A cada intervalo calcula quantos requests já deveriam ter sido enviados e dispara a diferença,
+// de forma que a taxa de chegada independe do tempo de resposta do serviço.
+// Retorna antes do fim quando o canal abort é fechado (teste interrompido).
+func dispatchStages(stages []Stage, startTime time.Time, abort <-chan struct{}, dispatch func(stage int) bool) {
+	total := profileDuration(stages)
+	issued := 0
+
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		elapsed := time.Since(startTime)
+		if elapsed > total {
+			elapsed = total
+		}
+
+		// Dispara os requests atrasados em relação à taxa esperada
+		expected := int(expectedRequests(stages, elapsed))
+		stage := stageIndexAt(stages, elapsed)
+		for ; issued < expected; issued++ {
+			if !dispatch(stage) {
+				return
+			}
+		}
+
+		if elapsed >= total {
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-abort:
+			return
+		}
+	}
+}
+
+// Executor executa os requests do teste; há uma implementação para cada protocolo e uma para
+// os fluxos de vários passos. Implementações próprias podem ser usadas com WithExecutor para
+// gerar carga em qualquer tipo de alvo (ex: uma função do próprio código, em um benchmark).
+// Execute é chamado concorrentemente pelos workers.
+type Executor interface {
+	Execute(ctx context.Context) Result // Executa um único request (cancelado junto com ctx) e retorna o seu resultado
+	Close()                             // Libera as conexões ao final do teste
+}
+
+// ExecutorFunc adapta uma função ao Executor; Close não faz nada.
+type ExecutorFunc func(ctx context.Context) Result
+
+// Execute chama a própria função.
+func (f ExecutorFunc) Execute(ctx context.Context) Result {
+	return f(ctx)
+}
+
+// Close não faz nada: a função não mantém conexões.
+func (f ExecutorFunc) Close() {}
+
+// newExecutor cria o executor adequado ao protocolo da configuração.
+func newExecutor(config Config) Executor {
+	switch {
+	case config.Replay != nil:
+		return newReplayExecutor(config)
+	case config.Flow != nil:
+		return newFlowExecutor(config)
+	case config.Stream != nil:
+		return newStreamExecutor(config)
+	case config.GRPC != nil:
+		return newGRPCExecutor(config)
+	}
+	return &httpExecutor{config: config, client: newClient(config)}
+}
+
+// httpExecutor dispara requisições HTTP usando um cliente compartilhado.
+type httpExecutor struct {
+	config Config       // Configuração com a requisição fixa ou o cenário
+	client *http.Client // Cliente HTTP compartilhado por todos os workers
+}
+
+// Execute dispara a próxima requisição da configuração (fixa ou sorteada do cenário).
+func (e *httpExecutor) Execute(ctx context.Context) Result {
+	tag, spec, err := e.config.nextRequest()
+	if err != nil {
+		return Result{Error: &requestError{err}, Tag: tag}
+	}
+	result := e.makeRequest(ctx, spec)
+	result.Tag = tag
+	return result
+}
+
+// Close fecha as conexões ociosas do cliente.
+func (e *httpExecutor) Close() {
+	e.client.CloseIdleConnections()
+}
+
+// makeRequest executa uma única requisição HTTP conforme a especificação informada.
+func (e *httpExecutor) makeRequest(ctx context.Context, spec RequestSpec) Result {
+	result, _ := sendRequest(ctx, e.client, spec, false)
+	return result
+}
+
+// response contém o que foi recebido em um request e pode ser usado após a medição:
+// cabeçalhos e o início do corpo (até maxAssertionBody), usados na extração de variáveis.
+type response struct {
+	header http.Header // Cabeçalhos da resposta
+	body   []byte      // Início do corpo (apenas quando solicitado ou exigido pelas asserções)
+}
+
+// sendRequest executa uma única requisição HTTP com o cliente informado.
+// Mede o tempo de resposta (até a leitura completa do corpo), a duração de cada fase via
+// httptrace e o tamanho do corpo, captura erros ou códigos de status e aplica as asserções.
+// Com keepBody o início do corpo é mantido em memória mesmo sem asserções que dependam dele.
+// Retorna um Result com as informações da requisição e a resposta recebida (nil em caso de erro).
+func sendRequest(ctx context.Context, client *http.Client, spec RequestSpec, keepBody bool) (Result, *response) {
+	// Monta a requisição antes de iniciar a medição de tempo
+	req, err := spec.buildRequest()
+	if err != nil {
+		return Result{Error: &requestError{err}}, nil
+	}
+
+	// Rastreia as fases do request (DNS, conexão, TLS e primeiro byte)
+	tracer := &phaseTracer{}
+	req = req.WithContext(httptrace.WithClientTrace(ctx, tracer.clientTrace()))
+
 	// Marca o tempo de início da requisição individual
 	start := time.Now()
-	
-	// Cria cliente HTTP com timeout de 30 segundos
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-	
-	// Executa a requisição GET
-	resp, err := client.Get(url)
-	
-	// Calcula quanto tempo a requisição levou
-	duration := time.Since(start)
-	
+
+	// Executa a requisição usando o cliente compartilhado
+	resp, err := client.Do(req)
+
 	// Se houve erro (timeout, DNS, conexão, etc.), retorna resultado com erro
 	if err != nil {
 		return Result{
-			Duration: duration,
+			Duration: time.Since(start),
 			Error:    err,
-		}
+		}, nil
+	}
+
+	// Lê o corpo até o fim antes de fechá-lo: só assim a conexão volta ao pool para reuso.
+	// Quando alguma asserção (ou extração) depende do corpo, o início dele é mantido em memória.
+	var body []byte
+	var size int64
+	if keepBody || (spec.Assertions != nil && spec.Assertions.needsBody()) {
+		body, err = io.ReadAll(io.LimitReader(resp.Body, maxAssertionBody))
+		size = int64(len(body))
 	}
-	
-	// Fecha o body da resposta para evitar vazamento de recursos
-	defer resp.Body.Close()
-	
+	if err == nil {
+		var rest int64
+		rest, err = io.Copy(io.Discard, resp.Body)
+		size += rest
+	}
+	resp.Body.Close()
+
+	// Calcula quanto tempo a requisição levou
+	end := time.Now()
+	duration := end.Sub(start)
+
+	// Falha na leitura do corpo também é um erro de transporte
+	if err != nil {
+		return Result{
+			Duration: duration,
+			Error:    &bodyReadError{err},
+		}, nil
+	}
+
 	// Retorna resultado bem-sucedido com código de status
-	return Result{
+	result := Result{
 		StatusCode: resp.StatusCode,
 		Duration:   duration,
 		Error:      nil,
+		Timings:    tracer.finish(start, end),
+		BodySize:   size,
+		RateLimit:  parseRateLimit(resp.Header, end),
 	}
-}
\ No newline at end of file
+
+	// Aplica as asserções depois da medição, para não somar o custo da validação à latência
+	if spec.Assertions != nil {
+		result.Failed = spec.Assertions.check(resp, body, duration)
+	}
+	return result, &response{header: resp.Header, body: body}
+}
@@ -0,0 +1,313 @@
+package stresstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// amostrador acompanha o pico de goroutines e de memória em uso durante uma execução
+type amostrador struct {
+	parar         chan struct{}
+	concluido     chan struct{}
+	maxGoroutines atomic.Int64
+	maxHeap       atomic.Uint64
+}
+
+// iniciarAmostrador coleta amostras a cada milissegundo até que parar seja chamado
+func iniciarAmostrador() *amostrador {
+	a := &amostrador{parar: make(chan struct{}), concluido: make(chan struct{})}
+	go func() {
+		defer close(a.concluido)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+
+		var mem runtime.MemStats
+		for {
+			if g := int64(runtime.NumGoroutine()); g > a.maxGoroutines.Load() {
+				a.maxGoroutines.Store(g)
+			}
+			runtime.ReadMemStats(&mem)
+			if mem.HeapInuse > a.maxHeap.Load() {
+				a.maxHeap.Store(mem.HeapInuse)
+			}
+
+			select {
+			case <-a.parar:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return a
+}
+
+// finalizar encerra a coleta de amostras
+func (a *amostrador) finalizar() {
+	close(a.parar)
+	<-a.concluido
+}
+
+func novoServidorVazio(tb testing.TB) *httptest.Server {
+	tb.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tb.Cleanup(server.Close)
+	return server
+}
+
+func TestRun_GoroutinesLimitadasPelaConcorrencia(t *testing.T) {
+	server := novoServidorVazio(t)
+	antes := runtime.NumGoroutine()
+
+	a := iniciarAmostrador()
+	report := Run(context.Background(), Config{RequestSpec: RequestSpec{URL: server.URL}, Requests: 5000, Concurrency: 4})
+	a.finalizar()
+
+	if report.TotalRequests != 5000 || report.SuccessCount != 5000 {
+		t.Fatalf("Esperado 5000 requests bem-sucedidos, obtido %d/%d", report.SuccessCount, report.TotalRequests)
+	}
+
+	// Workers, conexões do cliente e do servidor e o amostrador: nada proporcional aos requests
+	if extra := a.maxGoroutines.Load() - int64(antes); extra > 40 {
+		t.Errorf("Número de goroutines cresceu demais durante o teste: +%d", extra)
+	}
+}
+
+// BenchmarkRun_Memoria mostra que o pico de memória e de goroutines é constante em relação
+// ao número de requests: as métricas max-goroutines e max-heap-KB devem se manter estáveis
+// entre os sub-benchmarks mesmo com 100 vezes mais requests.
+//
+//	go test -run=^$ -bench=BenchmarkRun_Memoria ./pkg/stresstest
+func BenchmarkRun_Memoria(b *testing.B) {
+	server := novoServidorVazio(b)
+
+	for _, requests := range []int{1_000, 10_000, 100_000} {
+		b.Run(fmt.Sprintf("requests=%d", requests), func(b *testing.B) {
+			config := Config{RequestSpec: RequestSpec{URL: server.URL}, Requests: requests, Concurrency: 8}
+			runtime.GC()
+
+			a := iniciarAmostrador()
+			for i := 0; i < b.N; i++ {
+				Run(context.Background(), config)
+			}
+			a.finalizar()
+
+			b.ReportMetric(float64(a.maxGoroutines.Load()), "max-goroutines")
+			b.ReportMetric(float64(a.maxHeap.Load())/1024, "max-heap-KB")
+			b.ReportMetric(float64(requests*b.N)/b.Elapsed().Seconds(), "req/s")
+		})
+	}
+}
+
+func TestRun_CanceladoGeraRelatorioParcial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	report := Run(ctx, Config{RequestSpec: RequestSpec{URL: server.URL}, Requests: 100_000, Concurrency: 4})
+
+	if report.Aborted == "" {
+		t.Fatal("Esperado relatório marcado como interrompido")
+	}
+	if report.TotalRequests == 0 || report.TotalRequests >= 100_000 {
+		t.Errorf("Esperado relatório parcial, obtido %d requests", report.TotalRequests)
+	}
+	// Os requests em andamento terminam dentro do prazo de tolerância e entram nas métricas
+	if report.Abandoned != 0 || report.ErrorCount != 0 || report.SuccessCount != report.TotalRequests {
+		t.Errorf("Esperado apenas requests concluídos, obtido %d sucessos, %d erros e %d cancelados",
+			report.SuccessCount, report.ErrorCount, report.Abandoned)
+	}
+}
+
+func TestRun_PrazoDeToleranciaCancelaRequestsLentos(t *testing.T) {
+	liberar := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-liberar:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(liberar)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	inicio := time.Now()
+	report := Run(ctx, Config{
+		RequestSpec: RequestSpec{URL: server.URL},
+		Requests:    10,
+		Concurrency: 2,
+		GracePeriod: 50 * time.Millisecond,
+	})
+
+	if decorrido := time.Since(inicio); decorrido > time.Second {
+		t.Errorf("Esperado fim logo após o prazo de tolerância, levou %v", decorrido)
+	}
+	// Os 2 requests presos no servidor são cancelados e ficam fora das métricas
+	if report.Abandoned != 2 || report.TotalRequests != 0 {
+		t.Errorf("Esperado 2 requests cancelados e nenhum contabilizado, obtido %d e %d",
+			report.Abandoned, report.TotalRequests)
+	}
+}
+
+func TestRun_MaxErrors(t *testing.T) {
+	// Porta sem servidor: todos os requests falham com conexão recusada
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	report := Run(context.Background(), Config{RequestSpec: RequestSpec{URL: url}, Requests: 10_000, Concurrency: 2, MaxErrors: 20})
+
+	if report.Aborted != "limite de 20 erros atingido" {
+		t.Errorf("Esperado interrupção pelo limite de erros, obtido %q", report.Aborted)
+	}
+	// Os workers em andamento podem registrar alguns erros além do limite
+	if report.ErrorCount < 20 || report.ErrorCount > 22 {
+		t.Errorf("Esperado cerca de 20 erros, obtido %d", report.ErrorCount)
+	}
+}
+
+// executorDeTeste conta as execuções e registra se foi fechado ao final
+type executorDeTeste struct {
+	execucoes atomic.Int64
+	fechado   atomic.Bool
+}
+
+func (e *executorDeTeste) Execute(ctx context.Context) Result {
+	// A cada 10 execuções uma falha com status 500
+	if e.execucoes.Add(1)%10 == 0 {
+		return Result{StatusCode: http.StatusInternalServerError, Duration: time.Millisecond}
+	}
+	return Result{StatusCode: http.StatusOK, Duration: time.Millisecond}
+}
+
+func (e *executorDeTeste) Close() {
+	e.fechado.Store(true)
+}
+
+func TestRunner_ExecutorProprio(t *testing.T) {
+	executor := &executorDeTeste{}
+	runner := NewRunner(Config{Requests: 100, Concurrency: 4}, WithExecutor(executor))
+
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if executor.execucoes.Load() != 100 || report.TotalRequests != 100 || report.Non2xxCount != 10 {
+		t.Errorf("Esperado 100 requests com 10 falhas, obtido %d execuções, %d requests e %d falhas",
+			executor.execucoes.Load(), report.TotalRequests, report.Non2xxCount)
+	}
+	if !executor.fechado.Load() {
+		t.Error("Esperado executor fechado ao final")
+	}
+}
+
+func TestRunner_ResultHook(t *testing.T) {
+	server := novoServidorVazio(t)
+	var resultados, sucessos atomic.Int64
+	hook := func(result Result) {
+		resultados.Add(1)
+		if result.Error == nil && result.StatusCode == http.StatusOK {
+			sucessos.Add(1)
+		}
+	}
+
+	runner := NewRunner(Config{RequestSpec: RequestSpec{URL: server.URL}, Requests: 50, Concurrency: 5}, WithResultHook(hook))
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if resultados.Load() != 50 || sucessos.Load() != int64(report.StatusCodes[http.StatusOK]) {
+		t.Errorf("Esperado hook chamado para os 50 resultados, obtido %d (%d sucessos)", resultados.Load(), sucessos.Load())
+	}
+}
+
+func TestRunner_Validacao(t *testing.T) {
+	executor := ExecutorFunc(func(ctx context.Context) Result { return Result{StatusCode: http.StatusOK} })
+
+	casos := map[string]*Runner{
+		"sem URL":                   NewRunner(Config{Requests: 1, Concurrency: 1}),
+		"executor com URL":          NewRunner(Config{RequestSpec: RequestSpec{URL: "http://x/"}, Requests: 1, Concurrency: 1}, WithExecutor(executor)),
+		"executor sem requests":     NewRunner(Config{Concurrency: 1}, WithExecutor(executor)),
+		"executor sem concorrência": NewRunner(Config{Requests: 1}, WithExecutor(executor)),
+	}
+	for nome, runner := range casos {
+		if _, err := runner.Run(context.Background()); err == nil {
+			t.Errorf("%s: esperado erro", nome)
+		}
+	}
+}
+
+func TestRunner_CanceladoPeloContexto(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var execucoes atomic.Int64
+	executor := ExecutorFunc(func(ctx context.Context) Result {
+		// Cancela o teste no meio da execução
+		if execucoes.Add(1) == 100 {
+			cancel()
+		}
+		return Result{StatusCode: http.StatusOK}
+	})
+
+	report, err := NewRunner(Config{Requests: 1_000_000, Concurrency: 2}, WithExecutor(executor)).Run(ctx)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if report.Aborted == "" || report.TotalRequests >= 1_000_000 {
+		t.Errorf("Esperado relatório parcial, obtido %d requests (%q)", report.TotalRequests, report.Aborted)
+	}
+}
+
+// escritorComFalha falha em todas as escritas
+type escritorComFalha struct{}
+
+func (escritorComFalha) Write(p []byte) (int, error) {
+	return 0, errors.New("disco cheio")
+}
+
+func TestWriteReport(t *testing.T) {
+	report, _ := NewRunner(Config{Requests: 10, Concurrency: 1},
+		WithExecutor(ExecutorFunc(func(ctx context.Context) Result { return Result{StatusCode: http.StatusOK} }))).Run(context.Background())
+
+	var saida bytes.Buffer
+	if err := WriteReport(&saida, report); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if !strings.Contains(saida.String(), "RELATÓRIO DO TESTE DE CARGA") {
+		t.Errorf("Relatório não escrito no writer:\n%s", saida.String())
+	}
+	if err := WriteReport(escritorComFalha{}, report); err == nil || err.Error() != "disco cheio" {
+		t.Errorf("Esperado erro de escrita, obtido %v", err)
+	}
+}
+
+// BenchmarkRunner mostra o uso do pacote em um benchmark do go test: cada iteração executa um
+// teste de carga contra o servidor e os percentis são reportados como métricas do benchmark.
+func BenchmarkRunner(b *testing.B) {
+	server := novoServidorVazio(b)
+	runner := NewRunner(Config{RequestSpec: RequestSpec{URL: server.URL}, Requests: 1_000, Concurrency: 8})
+
+	for i := 0; i < b.N; i++ {
+		report, err := runner.Run(context.Background())
+		if err != nil {
+			b.Fatal(err)
+		}
+		if report.ErrorCount > 0 {
+			b.Fatalf("%d erros no teste de carga", report.ErrorCount)
+		}
+		b.ReportMetric(float64(report.Latency.Percentile(99).Microseconds()), "p99-µs")
+	}
+}
@@ -0,0 +1,287 @@
+package stresstest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario descreve um tráfego composto por várias requisições ponderadas.
+// A cada disparo uma requisição é sorteada proporcionalmente ao seu peso e os templates
+// da URL, cabeçalhos, parâmetros de query e corpo são renderizados com dados únicos
+// (UUIDs, números de sequência e valores de feeds CSV).
+type Scenario struct {
+	Feeds    map[string]string `yaml:"feeds,omitempty"`    // Feeds de dados: nome -> caminho do arquivo CSV
+	FeedRows FeedRows          `yaml:"-"`                  // Feeds já carregados (enviados aos agentes no teste distribuído)
+	Requests []ScenarioRequest `yaml:"requests,omitempty"` // Requisições que compõem o cenário
+
+	once     sync.Once          // Garante que a preparação ocorra uma única vez
+	err      error              // Erro encontrado durante a preparação
+	compiled []*compiledRequest // Requisições com templates já compilados
+	weights  int                // Soma dos pesos de todas as requisições
+	feeds    map[string]*feed   // Dados carregados de cada feed
+	seq      sequence           // Contador global de sequência dos requests
+}
+
+// ScenarioRequest é uma requisição do cenário com seu nome e peso relativo.
+type ScenarioRequest struct {
+	Name        string `yaml:"name,omitempty"`      // Nome da requisição (usado em mensagens de erro)
+	Weight      int    `yaml:"weight,omitempty"`    // Peso relativo no sorteio (1 quando omitido)
+	BodyFile    string `yaml:"body_file,omitempty"` // Arquivo com o corpo da requisição (alternativa a body)
+	RequestSpec `yaml:",inline"`
+}
+
+// FeedRows contém as linhas de feeds já carregados: nome do feed -> linhas indexadas pelo nome
+// da coluna. Permite enviar os dados dos feeds junto com a configuração, sem os arquivos CSV.
+type FeedRows map[string][]map[string]string
+
+// feed contém as linhas de um arquivo CSV usado como fonte de dados dos templates.
+// As linhas são consumidas em ordem, recomeçando do início ao chegar no fim.
+type feed struct {
+	rows   []map[string]string // Linhas do CSV indexadas pelo nome da coluna (primeira linha)
+	cursor sequence            // Posição (a partir de 1) da próxima linha a ser consumida
+}
+
+// sequence é um contador concorrente que produz 1, 2, 3... No teste distribuído cada agente
+// conta intercalado com os demais (ver interleave), mantendo os valores únicos na execução.
+type sequence struct {
+	count  atomic.Int64 // Quantidade de valores já produzidos
+	offset int64        // Deslocamento do primeiro valor
+	stride int64        // Distância entre valores consecutivos (1 quando zero)
+}
+
+// LoadScenario lê um arquivo de cenário em YAML ou JSON.
+// Caminhos de feeds e de body_file são resolvidos relativamente ao diretório do arquivo.
+//
+// Exemplo de arquivo:
+//
+//	feeds:
+//	  ceps: ceps.csv
+//	requests:
+//	  - name: criar-pedido
+//	    weight: 3
+//	    method: POST
+//	    url: http://localhost:8000/order
+//	    content_type: application/json
+//	    body: '{"id":"{{uuid}}","price":{{randInt 1 100}},"tax":0.5}'
+//	  - name: clima
+//	    url: http://localhost:8080/weather
+//	    query: {cep: '{{.Feed "ceps" "cep"}}'}
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler cenário: %w", err)
+	}
+
+	// JSON é um subconjunto de YAML, então o mesmo decodificador atende os dois formatos
+	scenario := &Scenario{}
+	if err := yaml.Unmarshal(data, scenario); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar cenário: %w", err)
+	}
+
+	// Resolve caminhos relativos ao diretório do cenário
+	dir := filepath.Dir(path)
+	for name, file := range scenario.Feeds {
+		scenario.Feeds[name] = resolvePath(dir, file)
+	}
+	for i := range scenario.Requests {
+		if scenario.Requests[i].BodyFile != "" {
+			scenario.Requests[i].BodyFile = resolvePath(dir, scenario.Requests[i].BodyFile)
+		}
+	}
+
+	return scenario, nil
+}
+
+// resolvePath resolve um caminho relativo a partir do diretório informado.
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// validate prepara o cenário e verifica se todas as requisições são válidas.
+func (s *Scenario) validate() error {
+	if len(s.Requests) == 0 {
+		return fmt.Errorf("cenário não possui requisições")
+	}
+	return s.prepare()
+}
+
+// prepare carrega os feeds, lê os arquivos de corpo e compila os templates do cenário.
+// É executado uma única vez; chamadas seguintes retornam o mesmo resultado.
+func (s *Scenario) prepare() error {
+	s.once.Do(func() {
+		s.err = s.compile()
+	})
+	return s.err
+}
+
+// compile realiza a preparação efetiva do cenário (ver prepare).
+func (s *Scenario) compile() error {
+	// Carrega os feeds de dados
+	var err error
+	if s.feeds, err = loadFeeds(s.Feeds, s.FeedRows, &s.seq); err != nil {
+		return err
+	}
+
+	for i, request := range s.Requests {
+		label := request.label(i)
+
+		// Peso padrão é 1; pesos negativos não fazem sentido
+		if request.Weight < 0 {
+			return fmt.Errorf("%s: peso não pode ser negativo", label)
+		}
+		if request.Weight == 0 {
+			request.Weight = 1
+		}
+
+		// Corpo lido de arquivo
+		if request.Body, err = readBody(request.Body, request.BodyFile); err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+
+		compiled, err := compileRequest(request.RequestSpec)
+		if err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		compiled.name = request.Name
+		compiled.weight = request.Weight
+
+		// Renderiza uma vez para validar os templates e a requisição resultante
+		spec, err := compiled.render(s.newTemplateData(0))
+		if err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		if err := spec.validate(); err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+
+		s.compiled = append(s.compiled, compiled)
+		s.weights += compiled.weight
+	}
+
+	return nil
+}
+
+// next sorteia uma requisição do cenário conforme os pesos e renderiza seus templates.
+// Retorna o nome da requisição sorteada junto com a especificação pronta para envio.
+func (s *Scenario) next() (string, RequestSpec, error) {
+	// Sorteio ponderado: escolhe um ponto em [0, soma dos pesos) e encontra a requisição
+	point := rand.IntN(s.weights)
+	request := s.compiled[len(s.compiled)-1]
+	for _, candidate := range s.compiled {
+		if point < candidate.weight {
+			request = candidate
+			break
+		}
+		point -= candidate.weight
+	}
+
+	spec, err := request.render(s.newTemplateData(s.seq.next()))
+	return request.name, spec, err
+}
+
+// label retorna o nome da requisição ou um rótulo gerado a partir da sua posição.
+func (r *ScenarioRequest) label(index int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("requisição %d", index+1)
+}
+
+// readBody retorna o corpo da requisição, lido de bodyFile quando informado.
+func readBody(body, bodyFile string) (string, error) {
+	if bodyFile == "" {
+		return body, nil
+	}
+	if body != "" {
+		return "", fmt.Errorf("use body ou body_file, não ambos")
+	}
+	data, err := os.ReadFile(bodyFile)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler corpo da requisição: %w", err)
+	}
+	return string(data), nil
+}
+
+// loadFeeds carrega os feeds declarados (nome -> caminho do arquivo CSV) e os já carregados.
+// As linhas são consumidas na mesma ordem intercalada da sequência informada.
+func loadFeeds(paths map[string]string, rows FeedRows, seq *sequence) (map[string]*feed, error) {
+	feeds := make(map[string]*feed, len(paths)+len(rows))
+	for name, path := range paths {
+		loaded, err := loadFeed(path)
+		if err != nil {
+			return nil, fmt.Errorf("feed %s: %w", name, err)
+		}
+		feeds[name] = loaded
+	}
+	for name, data := range rows {
+		if _, ok := feeds[name]; ok {
+			return nil, fmt.Errorf("feed %s declarado mais de uma vez", name)
+		}
+		if len(data) == 0 {
+			return nil, fmt.Errorf("feed %s: nenhuma linha de dados", name)
+		}
+		feeds[name] = &feed{rows: data}
+	}
+	for _, loaded := range feeds {
+		loaded.cursor.interleave(seq.offset, seq.stride)
+	}
+	return feeds, nil
+}
+
+// loadFeed lê um arquivo CSV cuja primeira linha contém os nomes das colunas.
+func loadFeed(path string) (*feed, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler CSV: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("CSV deve conter cabeçalho e ao menos uma linha de dados")
+	}
+
+	// Converte cada linha em um mapa coluna -> valor
+	header := records[0]
+	loaded := &feed{}
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+		loaded.rows = append(loaded.rows, row)
+	}
+	return loaded, nil
+}
+
+// take retorna a próxima linha do feed, recomeçando do início ao chegar no fim.
+func (f *feed) take() map[string]string {
+	index := (f.cursor.next() - 1) % int64(len(f.rows))
+	return f.rows[index]
+}
+
+// next retorna o próximo valor da sequência.
+func (s *sequence) next() int64 {
+	return s.offset + (s.count.Add(1)-1)*max(s.stride, 1) + 1
+}
+
+// interleave faz a sequência produzir offset+1, offset+1+stride, offset+1+2*stride... Com
+// offset i e stride n, as sequências de n participantes não se repetem entre si.
+func (s *sequence) interleave(offset, stride int64) {
+	s.offset, s.stride = offset, stride
+}
@@ -0,0 +1,133 @@
+package stresstest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// escreverArquivo cria um arquivo temporário com o conteúdo informado
+func escreverArquivo(t *testing.T, dir, nome, conteudo string) string {
+	t.Helper()
+	path := filepath.Join(dir, nome)
+	if err := os.WriteFile(path, []byte(conteudo), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScenario_PesosETemplates(t *testing.T) {
+	var mu sync.Mutex
+	pedidos := make(map[string]bool)
+	ceps := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/order":
+			var pedido struct {
+				ID string `json:"id"`
+			}
+			data, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(data, &pedido); err != nil || pedidos[pedido.ID] {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			pedidos[pedido.ID] = true
+			w.WriteHeader(http.StatusCreated)
+		case "/weather":
+			ceps[r.URL.Query().Get("cep")]++
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	escreverArquivo(t, dir, "ceps.csv", "cep,cidade\n01001000,São Paulo\n20040002,Rio de Janeiro\n")
+	escreverArquivo(t, dir, "pedido.json", `{"id":"{{uuid}}-{{.Seq}}","price":{{randInt 1 100}},"tax":0.5}`)
+	path := escreverArquivo(t, dir, "cenario.yaml", strings.ReplaceAll(`
+feeds:
+  ceps: ceps.csv
+requests:
+  - name: criar-pedido
+    weight: 3
+    method: POST
+    url: BASE/order
+    content_type: application/json
+    body_file: pedido.json
+  - name: clima
+    weight: 1
+    url: BASE/weather
+    query: {cep: '{{.Feed "ceps" "cep"}}'}
+`, "BASE", server.URL))
+
+	scenario, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("Erro ao carregar cenário: %v", err)
+	}
+
+	config := Config{Scenario: scenario, Requests: 400, Concurrency: 10}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Configuração inválida: %v", err)
+	}
+
+	report := Run(context.Background(), config)
+
+	// Todos os pedidos devem ter IDs únicos (nenhum conflito)
+	if report.StatusCodes[http.StatusConflict] > 0 {
+		t.Errorf("IDs de pedido repetidos: %d conflitos", report.StatusCodes[http.StatusConflict])
+	}
+
+	// Proporção 3:1 entre pedidos e consultas de clima (com margem para o sorteio)
+	criados := report.StatusCodes[http.StatusCreated]
+	if criados < 250 || criados > 350 {
+		t.Errorf("Distribuição de pesos inesperada: %d pedidos de 400", criados)
+	}
+
+	// Os CEPs devem vir do feed, alternando entre as linhas
+	if len(ceps) != 2 || ceps["01001000"] == 0 || ceps["20040002"] == 0 {
+		t.Errorf("CEPs inesperados: %v", ceps)
+	}
+}
+
+func TestScenario_Validacao(t *testing.T) {
+	casos := map[string]*Scenario{
+		"sem requisições":   {},
+		"template inválido": {Requests: []ScenarioRequest{{RequestSpec: RequestSpec{URL: "http://x/{{.Seq"}}}},
+		"feed inexistente":  {Requests: []ScenarioRequest{{RequestSpec: RequestSpec{URL: `http://x/{{.Feed "a" "b"}}`}}}},
+		"URL inválida":      {Requests: []ScenarioRequest{{RequestSpec: RequestSpec{URL: "x/{{.Seq}}"}}}},
+	}
+	for nome, scenario := range casos {
+		config := Config{Scenario: scenario, Requests: 1, Concurrency: 1}
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: esperado erro de validação", nome)
+		}
+	}
+}
+
+func TestRun_CenarioComFeedAusente(t *testing.T) {
+	// Sem validação prévia, Run não pode entrar em pânico com um cenário ou fluxo que não prepara
+	feeds := map[string]string{"ceps": filepath.Join(t.TempDir(), "inexistente.csv")}
+	configs := map[string]Config{
+		"cenário": {Scenario: &Scenario{Feeds: feeds, Requests: []ScenarioRequest{{RequestSpec: RequestSpec{URL: "http://x"}}}},
+			Requests: 5, Concurrency: 2},
+		"fluxo": {Flow: &Flow{Feeds: feeds, Steps: []FlowStep{{RequestSpec: RequestSpec{URL: "http://x"}}}},
+			Requests: 5, Concurrency: 2},
+	}
+	for nome, config := range configs {
+		report := Run(context.Background(), config)
+		if report.TotalRequests != 0 || !strings.Contains(report.Aborted, "configuração inválida") {
+			t.Errorf("%s: esperado relatório vazio com o erro de configuração, obtido %d requests (%q)",
+				nome, report.TotalRequests, report.Aborted)
+		}
+	}
+}
@@ -0,0 +1,431 @@
+package stresstest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Formatos suportados pelos sinks HTTP
+const (
+	SinkPrometheus  = "prometheus"   // Formato texto do Prometheus, enviado a um Pushgateway
+	SinkRemoteWrite = "remote-write" // Prometheus remote-write (protobuf comprimido com snappy)
+	SinkOTLP        = "otlp"         // Métricas OTLP/HTTP codificadas em JSON
+	SinkInfluxDB    = "influxdb"     // Line protocol do InfluxDB
+)
+
+// sinkTimeout limita cada envio de métricas para que um destino lento não acumule envios.
+const sinkTimeout = 5 * time.Second
+
+// sinkClient é o cliente HTTP compartilhado pelos sinks.
+var sinkClient = &http.Client{Timeout: sinkTimeout}
+
+// sinkQueue é quantos snapshots aguardam envio antes de os mais novos serem descartados.
+const sinkQueue = 8
+
+// Sink recebe as métricas periódicas do teste (a cada Config.ProgressInterval) e as envia para
+// um sistema de monitoramento, permitindo acompanhar o teste nos mesmos painéis do serviço.
+type Sink interface {
+	Push(ctx context.Context, snapshot Snapshot) error
+}
+
+// HTTPSink envia as métricas de cada snapshot via HTTP em um dos formatos suportados.
+//
+// Métricas enviadas (rótulos de Labels em todas):
+//   - stresstest_requests_total, stresstest_errors_total e stresstest_non_2xx_total (contadores)
+//   - stresstest_status_total{code="..."} (contador por código de status)
+//   - stresstest_rps, stresstest_in_flight e stresstest_error_rate (medidores)
+//   - stresstest_latency_seconds{quantile="0.5|0.9|0.95|0.99"} (medidor, último intervalo)
+type HTTPSink struct {
+	Format  string            // SinkPrometheus, SinkRemoteWrite, SinkOTLP ou SinkInfluxDB
+	URL     string            // Endereço que recebe as métricas
+	Labels  map[string]string // Rótulos adicionados a todas as métricas (ex: test=checkout)
+	Headers map[string]string // Cabeçalhos enviados em cada envio (ex: autenticação)
+}
+
+// ParseSink interpreta um destino no formato "formato=url", ex: "influxdb=http://localhost:8086/write?db=carga".
+func ParseSink(value string) (*HTTPSink, error) {
+	format, address, ok := strings.Cut(value, "=")
+	if !ok {
+		return nil, fmt.Errorf("sink inválido %q: use o formato formato=url", value)
+	}
+
+	sink := &HTTPSink{Format: strings.TrimSpace(format), URL: strings.TrimSpace(address)}
+	if err := sink.validate(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// validate verifica o formato e o endereço do sink.
+func (s *HTTPSink) validate() error {
+	switch s.Format {
+	case SinkPrometheus, SinkRemoteWrite, SinkOTLP, SinkInfluxDB:
+	default:
+		return fmt.Errorf("formato de sink desconhecido %q: use prometheus, remote-write, otlp ou influxdb", s.Format)
+	}
+
+	parsed, err := url.Parse(s.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("sink %s: URL inválida %q", s.Format, s.URL)
+	}
+	return nil
+}
+
+// Push envia as métricas do snapshot ao destino.
+func (s *HTTPSink) Push(ctx context.Context, snapshot Snapshot) error {
+	metrics := snapshotMetrics(snapshot, s.Labels)
+	now := time.Now()
+
+	// Pushgateway substitui o grupo inteiro com PUT; os demais destinos recebem POST
+	method := http.MethodPost
+	var body []byte
+	var contentType string
+	switch s.Format {
+	case SinkPrometheus:
+		method = http.MethodPut
+		body, contentType = encodePrometheus(metrics), "text/plain; version=0.0.4"
+	case SinkRemoteWrite:
+		body, contentType = encodeRemoteWrite(metrics, now), "application/x-protobuf"
+	case SinkOTLP:
+		body, contentType = encodeOTLP(metrics, now, now.Add(-snapshot.Elapsed)), "application/json"
+	case SinkInfluxDB:
+		body, contentType = encodeInflux(metrics, now), "text/plain; charset=utf-8"
+	default:
+		return fmt.Errorf("formato de sink desconhecido %q", s.Format)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.Format == SinkRemoteWrite {
+		req.Header.Set("Content-Encoding", "snappy")
+		req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	}
+	for name, value := range s.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := sinkClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sink %s: %w", s.Format, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sink %s: status %d: %s", s.Format, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// sinkMetric é uma amostra de métrica independente do formato de envio.
+type sinkMetric struct {
+	Name    string            // Nome da métrica (ex: stresstest_rps)
+	Labels  map[string]string // Rótulos da amostra, incluindo os rótulos do sink
+	Value   float64           // Valor da amostra
+	Counter bool              // Indica um contador acumulado desde o início (senão, um medidor)
+}
+
+// snapshotMetrics converte o snapshot nas métricas enviadas pelos sinks, agrupadas por nome.
+func snapshotMetrics(s Snapshot, labels map[string]string) []sinkMetric {
+	with := func(extra ...string) map[string]string {
+		merged := make(map[string]string, len(labels)+len(extra)/2)
+		for name, value := range labels {
+			merged[name] = value
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			merged[extra[i]] = extra[i+1]
+		}
+		return merged
+	}
+
+	metrics := []sinkMetric{
+		{Name: "stresstest_requests_total", Labels: with(), Value: float64(s.Completed), Counter: true},
+		{Name: "stresstest_errors_total", Labels: with(), Value: float64(s.Errors), Counter: true},
+		{Name: "stresstest_non_2xx_total", Labels: with(), Value: float64(s.Non2xx), Counter: true},
+	}
+
+	codes := make([]int, 0, len(s.StatusCodes))
+	for code := range s.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		metrics = append(metrics, sinkMetric{
+			Name: "stresstest_status_total", Labels: with("code", strconv.Itoa(code)),
+			Value: float64(s.StatusCodes[code]), Counter: true,
+		})
+	}
+
+	metrics = append(metrics,
+		sinkMetric{Name: "stresstest_rps", Labels: with(), Value: s.RPS},
+		sinkMetric{Name: "stresstest_in_flight", Labels: with(), Value: float64(s.InFlight)},
+		sinkMetric{Name: "stresstest_error_rate", Labels: with(), Value: s.ErrorRate},
+	)
+
+	quantiles := []struct {
+		label string
+		value time.Duration
+	}{{"0.5", s.P50}, {"0.9", s.P90}, {"0.95", s.P95}, {"0.99", s.P99}}
+	for _, q := range quantiles {
+		metrics = append(metrics, sinkMetric{
+			Name: "stresstest_latency_seconds", Labels: with("quantile", q.label), Value: q.value.Seconds(),
+		})
+	}
+	return metrics
+}
+
+// sortedLabels retorna os nomes dos rótulos em ordem alfabética, para uma saída estável.
+func sortedLabels(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// encodePrometheus gera o formato texto de exposição do Prometheus (sem timestamps, como
+// exigido pelo Pushgateway).
+func encodePrometheus(metrics []sinkMetric) []byte {
+	var b bytes.Buffer
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+	for i, m := range metrics {
+		// Declara o tipo uma vez por métrica (as amostras de mesmo nome estão agrupadas)
+		if i == 0 || metrics[i-1].Name != m.Name {
+			kind := "gauge"
+			if m.Counter {
+				kind = "counter"
+			}
+			fmt.Fprintf(&b, "# TYPE %s %s\n", m.Name, kind)
+		}
+
+		b.WriteString(m.Name)
+		if len(m.Labels) > 0 {
+			pairs := make([]string, 0, len(m.Labels))
+			for _, name := range sortedLabels(m.Labels) {
+				pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, escape.Replace(m.Labels[name])))
+			}
+			b.WriteString("{" + strings.Join(pairs, ",") + "}")
+		}
+		fmt.Fprintf(&b, " %s\n", strconv.FormatFloat(m.Value, 'g', -1, 64))
+	}
+	return b.Bytes()
+}
+
+// encodeRemoteWrite gera uma WriteRequest do Prometheus remote-write (protobuf) comprimida com snappy.
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func encodeRemoteWrite(metrics []sinkMetric, now time.Time) []byte {
+	var request []byte
+	for _, m := range metrics {
+		labels := make(map[string]string, len(m.Labels)+1)
+		for name, value := range m.Labels {
+			labels[name] = value
+		}
+		labels["__name__"] = m.Name
+
+		var series []byte
+		for _, name := range sortedLabels(labels) {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, name)
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, labels[name])
+
+			series = protowire.AppendTag(series, 1, protowire.BytesType)
+			series = protowire.AppendBytes(series, label)
+		}
+
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(m.Value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(now.UnixMilli()))
+
+		series = protowire.AppendTag(series, 2, protowire.BytesType)
+		series = protowire.AppendBytes(series, sample)
+
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, series)
+	}
+	return snappy.Encode(nil, request)
+}
+
+// Estruturas da codificação JSON de ExportMetricsServiceRequest (OTLP/HTTP)
+type (
+	otlpRequest struct {
+		ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+	}
+	otlpResourceMetrics struct {
+		Resource     otlpResource       `json:"resource"`
+		ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeMetrics struct {
+		Scope   otlpScope    `json:"scope"`
+		Metrics []otlpMetric `json:"metrics"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpMetric struct {
+		Name  string     `json:"name"`
+		Gauge *otlpGauge `json:"gauge,omitempty"`
+		Sum   *otlpSum   `json:"sum,omitempty"`
+	}
+	otlpGauge struct {
+		DataPoints []otlpDataPoint `json:"dataPoints"`
+	}
+	otlpSum struct {
+		DataPoints             []otlpDataPoint `json:"dataPoints"`
+		AggregationTemporality int             `json:"aggregationTemporality"` // 2 = cumulativa
+		IsMonotonic            bool            `json:"isMonotonic"`
+	}
+	otlpDataPoint struct {
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano,omitempty"`
+		TimeUnixNano      string          `json:"timeUnixNano"`
+		AsDouble          float64         `json:"asDouble"`
+	}
+	otlpAttribute struct {
+		Key   string         `json:"key"`
+		Value otlpAttrString `json:"value"`
+	}
+	otlpAttrString struct {
+		StringValue string `json:"stringValue"`
+	}
+)
+
+// encodeOTLP gera as métricas no formato OTLP/HTTP JSON. Contadores são somas cumulativas desde
+// o início do teste (start) e os demais valores são medidores.
+func encodeOTLP(metrics []sinkMetric, now, start time.Time) []byte {
+	attributes := func(labels map[string]string) []otlpAttribute {
+		var result []otlpAttribute
+		for _, name := range sortedLabels(labels) {
+			result = append(result, otlpAttribute{Key: name, Value: otlpAttrString{labels[name]}})
+		}
+		return result
+	}
+
+	var converted []otlpMetric
+	for i, m := range metrics {
+		point := otlpDataPoint{
+			Attributes:   attributes(m.Labels),
+			TimeUnixNano: strconv.FormatInt(now.UnixNano(), 10),
+			AsDouble:     m.Value,
+		}
+
+		// Amostras de mesmo nome são pontos da mesma métrica
+		if i > 0 && metrics[i-1].Name == m.Name {
+			last := &converted[len(converted)-1]
+			if last.Sum != nil {
+				point.StartTimeUnixNano = strconv.FormatInt(start.UnixNano(), 10)
+				last.Sum.DataPoints = append(last.Sum.DataPoints, point)
+			} else {
+				last.Gauge.DataPoints = append(last.Gauge.DataPoints, point)
+			}
+			continue
+		}
+
+		metric := otlpMetric{Name: m.Name}
+		if m.Counter {
+			point.StartTimeUnixNano = strconv.FormatInt(start.UnixNano(), 10)
+			metric.Sum = &otlpSum{DataPoints: []otlpDataPoint{point}, AggregationTemporality: 2, IsMonotonic: true}
+		} else {
+			metric.Gauge = &otlpGauge{DataPoints: []otlpDataPoint{point}}
+		}
+		converted = append(converted, metric)
+	}
+
+	request := otlpRequest{ResourceMetrics: []otlpResourceMetrics{{
+		Resource:     otlpResource{Attributes: []otlpAttribute{{Key: "service.name", Value: otlpAttrString{"stress-test"}}}},
+		ScopeMetrics: []otlpScopeMetrics{{Scope: otlpScope{Name: "stress-test"}, Metrics: converted}},
+	}}}
+	body, _ := json.Marshal(request)
+	return body
+}
+
+// encodeInflux gera uma linha do line protocol do InfluxDB por amostra, com o nome da métrica
+// como measurement, os rótulos como tags e o valor no campo "value".
+func encodeInflux(metrics []sinkMetric, now time.Time) []byte {
+	var b bytes.Buffer
+	escape := strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+
+	for _, m := range metrics {
+		b.WriteString(escape.Replace(m.Name))
+		for _, name := range sortedLabels(m.Labels) {
+			fmt.Fprintf(&b, ",%s=%s", escape.Replace(name), escape.Replace(m.Labels[name]))
+		}
+		fmt.Fprintf(&b, " value=%s %d\n", strconv.FormatFloat(m.Value, 'g', -1, 64), now.UnixNano())
+	}
+	return b.Bytes()
+}
+
+// sinkPusher envia os snapshots aos sinks em segundo plano, para que um destino lento não atrase
+// a coleta de snapshots. Quando a fila enche, os snapshots intermediários são descartados.
+type sinkPusher struct {
+	sinks    []Sink        // Destinos das métricas
+	queue    chan Snapshot // Snapshots aguardando envio
+	done     chan struct{} // Fechado quando todos os envios terminaram
+	failures atomic.Int64  // Envios que falharam
+}
+
+// startSinks inicia o envio em segundo plano para os sinks informados.
+func startSinks(sinks []Sink) *sinkPusher {
+	p := &sinkPusher{sinks: sinks, queue: make(chan Snapshot, sinkQueue), done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+		for snapshot := range p.queue {
+			for _, sink := range p.sinks {
+				ctx, cancel := context.WithTimeout(context.Background(), sinkTimeout)
+				if err := sink.Push(ctx, snapshot); err != nil {
+					p.failures.Add(1)
+				}
+				cancel()
+			}
+		}
+	}()
+	return p
+}
+
+// send enfileira o snapshot para envio. O snapshot final nunca é descartado.
+func (p *sinkPusher) send(snapshot Snapshot) {
+	if snapshot.Final {
+		p.queue <- snapshot
+		return
+	}
+	select {
+	case p.queue <- snapshot:
+	default:
+	}
+}
+
+// close aguarda o envio dos snapshots pendentes.
+func (p *sinkPusher) close() {
+	close(p.queue)
+	<-p.done
+}
@@ -0,0 +1,273 @@
+package stresstest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// envio é uma requisição recebida pelo receptor de métricas
+type envio struct {
+	metodo    string
+	cabecalho http.Header
+	corpo     []byte
+}
+
+// novoReceptor sobe um servidor HTTP que registra as métricas recebidas
+func novoReceptor(t *testing.T, status int) (*httptest.Server, func() []envio) {
+	t.Helper()
+	var mu sync.Mutex
+	var envios []envio
+
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		corpo, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		envios = append(envios, envio{metodo: r.Method, cabecalho: r.Header.Clone(), corpo: corpo})
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(servidor.Close)
+
+	return servidor, func() []envio {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]envio(nil), envios...)
+	}
+}
+
+// snapshotExemplo é o snapshot usado nos testes de codificação
+var snapshotExemplo = Snapshot{
+	Elapsed:     10 * time.Second,
+	Completed:   1000,
+	Errors:      5,
+	RPS:         98.5,
+	P50:         20 * time.Millisecond,
+	P90:         40 * time.Millisecond,
+	P95:         50 * time.Millisecond,
+	P99:         120 * time.Millisecond,
+	ErrorRate:   0.005,
+	StatusCodes: map[int]int{200: 990, 503: 5},
+}
+
+func TestHTTPSink_Prometheus(t *testing.T) {
+	servidor, envios := novoReceptor(t, http.StatusOK)
+	sink := &HTTPSink{Format: SinkPrometheus, URL: servidor.URL + "/metrics/job/carga", Labels: map[string]string{"test": "checkout"}}
+
+	if err := sink.Push(context.Background(), snapshotExemplo); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	recebido := envios()[0]
+	corpo := string(recebido.corpo)
+	if recebido.metodo != http.MethodPut {
+		t.Errorf("Esperado PUT no Pushgateway, obtido %s", recebido.metodo)
+	}
+	esperados := []string{
+		"# TYPE stresstest_requests_total counter\nstresstest_requests_total{test=\"checkout\"} 1000\n",
+		"stresstest_status_total{code=\"503\",test=\"checkout\"} 5\n",
+		"# TYPE stresstest_rps gauge\nstresstest_rps{test=\"checkout\"} 98.5\n",
+		"stresstest_latency_seconds{quantile=\"0.95\",test=\"checkout\"} 0.05\n",
+	}
+	for _, esperado := range esperados {
+		if !strings.Contains(corpo, esperado) {
+			t.Errorf("Esperado %q em:\n%s", esperado, corpo)
+		}
+	}
+	if strings.Count(corpo, "# TYPE stresstest_latency_seconds") != 1 {
+		t.Error("Esperado um único TYPE para as amostras de mesmo nome")
+	}
+}
+
+func TestHTTPSink_RemoteWrite(t *testing.T) {
+	servidor, envios := novoReceptor(t, http.StatusNoContent)
+	sink := &HTTPSink{Format: SinkRemoteWrite, URL: servidor.URL, Labels: map[string]string{"test": "checkout"}}
+
+	if err := sink.Push(context.Background(), snapshotExemplo); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	recebido := envios()[0]
+	if recebido.cabecalho.Get("Content-Encoding") != "snappy" || recebido.cabecalho.Get("X-Prometheus-Remote-Write-Version") == "" {
+		t.Errorf("Cabeçalhos do remote-write ausentes: %v", recebido.cabecalho)
+	}
+	dados, err := snappy.Decode(nil, recebido.corpo)
+	if err != nil {
+		t.Fatalf("Corpo não está comprimido com snappy: %v", err)
+	}
+
+	// Decodifica a WriteRequest e procura a série do rps
+	series := decodificarRemoteWrite(t, dados)
+	if len(series) != len(snapshotMetrics(snapshotExemplo, nil)) {
+		t.Errorf("Esperado uma série por métrica, obtido %d", len(series))
+	}
+	rps, ok := series["__name__=stresstest_rps,test=checkout"]
+	if !ok || rps != 98.5 {
+		t.Errorf("Esperado série stresstest_rps com valor 98.5, obtido %v (%v)", rps, series)
+	}
+}
+
+// decodificarRemoteWrite converte a WriteRequest em um mapa "rótulos" -> valor da amostra
+func decodificarRemoteWrite(t *testing.T, dados []byte) map[string]float64 {
+	t.Helper()
+	campos := func(b []byte, f func(num protowire.Number, valor []byte, fixo uint64, varint uint64)) {
+		for len(b) > 0 {
+			num, tipo, n := protowire.ConsumeTag(b)
+			b = b[n:]
+			switch tipo {
+			case protowire.BytesType:
+				v, n := protowire.ConsumeBytes(b)
+				f(num, v, 0, 0)
+				b = b[n:]
+			case protowire.Fixed64Type:
+				v, n := protowire.ConsumeFixed64(b)
+				f(num, nil, v, 0)
+				b = b[n:]
+			case protowire.VarintType:
+				v, n := protowire.ConsumeVarint(b)
+				f(num, nil, 0, v)
+				b = b[n:]
+			default:
+				t.Fatalf("Tipo protobuf inesperado %v", tipo)
+			}
+		}
+	}
+
+	resultado := make(map[string]float64)
+	campos(dados, func(_ protowire.Number, serie []byte, _, _ uint64) {
+		var rotulos []string
+		var valor float64
+		campos(serie, func(num protowire.Number, conteudo []byte, _, _ uint64) {
+			if num == 1 {
+				var nome, valorRotulo string
+				campos(conteudo, func(num protowire.Number, texto []byte, _, _ uint64) {
+					if num == 1 {
+						nome = string(texto)
+					} else {
+						valorRotulo = string(texto)
+					}
+				})
+				rotulos = append(rotulos, nome+"="+valorRotulo)
+				return
+			}
+			campos(conteudo, func(num protowire.Number, _ []byte, fixo, _ uint64) {
+				if num == 1 {
+					valor = math.Float64frombits(fixo)
+				}
+			})
+		})
+		resultado[strings.Join(rotulos, ",")] = valor
+	})
+	return resultado
+}
+
+func TestHTTPSink_OTLP(t *testing.T) {
+	servidor, envios := novoReceptor(t, http.StatusOK)
+	sink := &HTTPSink{Format: SinkOTLP, URL: servidor.URL + "/v1/metrics"}
+
+	if err := sink.Push(context.Background(), snapshotExemplo); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	var requisicao otlpRequest
+	if err := json.Unmarshal(envios()[0].corpo, &requisicao); err != nil {
+		t.Fatalf("JSON OTLP inválido: %v", err)
+	}
+	metricas := map[string]otlpMetric{}
+	for _, m := range requisicao.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		metricas[m.Name] = m
+	}
+
+	total := metricas["stresstest_requests_total"]
+	if total.Sum == nil || !total.Sum.IsMonotonic || total.Sum.DataPoints[0].AsDouble != 1000 || total.Sum.DataPoints[0].StartTimeUnixNano == "" {
+		t.Errorf("Esperado contador cumulativo com 1000 requests, obtido %+v", total)
+	}
+	latencia := metricas["stresstest_latency_seconds"]
+	if latencia.Gauge == nil || len(latencia.Gauge.DataPoints) != 4 {
+		t.Errorf("Esperado medidor com 4 quantis, obtido %+v", latencia)
+	}
+}
+
+func TestHTTPSink_InfluxDB(t *testing.T) {
+	servidor, envios := novoReceptor(t, http.StatusNoContent)
+	sink := &HTTPSink{
+		Format:  SinkInfluxDB,
+		URL:     servidor.URL + "/api/v2/write?org=o&bucket=carga",
+		Labels:  map[string]string{"test": "check out"},
+		Headers: map[string]string{"Authorization": "Token segredo"},
+	}
+
+	if err := sink.Push(context.Background(), snapshotExemplo); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	recebido := envios()[0]
+	if recebido.cabecalho.Get("Authorization") != "Token segredo" {
+		t.Error("Esperado cabeçalho de autenticação no envio")
+	}
+	linhas := strings.Split(strings.TrimSpace(string(recebido.corpo)), "\n")
+	if !strings.HasPrefix(linhas[0], `stresstest_requests_total,test=check\ out value=1000 `) {
+		t.Errorf("Linha inesperada: %q", linhas[0])
+	}
+}
+
+func TestHTTPSink_StatusDeErro(t *testing.T) {
+	servidor, _ := novoReceptor(t, http.StatusBadRequest)
+	sink := &HTTPSink{Format: SinkInfluxDB, URL: servidor.URL}
+
+	if err := sink.Push(context.Background(), snapshotExemplo); err == nil {
+		t.Error("Esperado erro quando o destino rejeita as métricas")
+	}
+}
+
+func TestParseSink(t *testing.T) {
+	sink, err := ParseSink("otlp=http://localhost:4318/v1/metrics")
+	if err != nil || sink.Format != SinkOTLP || sink.URL != "http://localhost:4318/v1/metrics" {
+		t.Errorf("Sink interpretado incorretamente: %+v (%v)", sink, err)
+	}
+
+	for _, invalido := range []string{"otlp", "graphite=http://localhost", "influxdb=localhost:8086", "prometheus=ftp://x"} {
+		if _, err := ParseSink(invalido); err == nil {
+			t.Errorf("%q: esperado erro", invalido)
+		}
+	}
+}
+
+func TestRun_Sinks(t *testing.T) {
+	server := novoServidorVazio(t)
+	receptor, envios := novoReceptor(t, http.StatusOK)
+	rejeita, _ := novoReceptor(t, http.StatusInternalServerError)
+
+	report := Run(context.Background(), Config{
+		RequestSpec:      RequestSpec{URL: server.URL},
+		Requests:         200,
+		Concurrency:      2,
+		ProgressInterval: 10 * time.Millisecond,
+		Sinks: []Sink{
+			&HTTPSink{Format: SinkPrometheus, URL: receptor.URL},
+			&HTTPSink{Format: SinkPrometheus, URL: rejeita.URL},
+		},
+	})
+
+	recebidos := envios()
+	if len(recebidos) == 0 {
+		t.Fatal("Esperado envio de métricas durante o teste")
+	}
+	// O snapshot final é sempre enviado e contém o total de requests
+	ultimo := string(recebidos[len(recebidos)-1].corpo)
+	if !strings.Contains(ultimo, "stresstest_requests_total 200\n") {
+		t.Errorf("Esperado total de 200 requests no último envio:\n%s", ultimo)
+	}
+	if report.SinkFailures != len(recebidos) {
+		t.Errorf("Esperado %d falhas do destino que rejeita, obtido %d", len(recebidos), report.SinkFailures)
+	}
+}
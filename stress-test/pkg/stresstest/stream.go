@@ -0,0 +1,357 @@
+package stresstest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Protocolos de streaming suportados
+const (
+	ProtocolWebSocket = "websocket" // Conexões WebSocket com mensagens opcionais
+	ProtocolSSE       = "sse"       // Server-Sent Events (text/event-stream)
+)
+
+// DefaultStreamDuration é o tempo padrão que cada conexão de streaming permanece aberta.
+const DefaultStreamDuration = 10 * time.Second
+
+// defaultMessageInterval é o intervalo padrão entre as mensagens enviadas no WebSocket.
+const defaultMessageInterval = time.Second
+
+// Motivos de desconexão das sessões de streaming. Além destes, fechamentos WebSocket com outros
+// códigos aparecem como "close_NNNN" e falhas de rede usam as categorias de ClassifyError.
+const (
+	DisconnectCompleted    = "completed"     // Cliente encerrou a sessão ao fim da duração
+	DisconnectServerClosed = "server_closed" // Servidor encerrou normalmente (close 1000 ou fim do stream SSE)
+)
+
+// StreamConfig descreve as sessões WebSocket ou SSE abertas no modo de streaming.
+// Cada request do teste é uma sessão: Concurrency define quantas conexões ficam abertas ao
+// mesmo tempo e Requests (ou o perfil de carga) quantas sessões são abertas no total.
+type StreamConfig struct {
+	Protocol        string            // ProtocolWebSocket ou ProtocolSSE
+	URL             string            // URL do endpoint (ws/wss no WebSocket, http/https no SSE)
+	Headers         map[string]string // Cabeçalhos enviados na abertura da conexão
+	Messages        []string          // Mensagens enviadas em ordem após conectar (apenas WebSocket)
+	MessageInterval time.Duration     // Intervalo entre as mensagens enviadas (padrão 1s)
+	Duration        time.Duration     // Tempo que cada conexão permanece aberta (padrão DefaultStreamDuration)
+}
+
+// validate verifica se a configuração de streaming é consistente.
+func (s *StreamConfig) validate() error {
+	parsed, err := url.Parse(s.URL)
+	if err != nil || s.URL == "" {
+		return fmt.Errorf("URL inválida %q", s.URL)
+	}
+
+	switch s.Protocol {
+	case ProtocolWebSocket:
+		if parsed.Scheme != "ws" && parsed.Scheme != "wss" {
+			return fmt.Errorf("URL WebSocket deve usar o esquema ws ou wss")
+		}
+	case ProtocolSSE:
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return fmt.Errorf("URL SSE deve usar o esquema http ou https")
+		}
+		if len(s.Messages) > 0 {
+			return fmt.Errorf("mensagens só podem ser enviadas em conexões WebSocket")
+		}
+	default:
+		return fmt.Errorf("protocolo de streaming desconhecido %q: use websocket ou sse", s.Protocol)
+	}
+
+	if s.Duration < 0 || s.MessageInterval < 0 {
+		return fmt.Errorf("duração e intervalo entre mensagens não podem ser negativos")
+	}
+	return nil
+}
+
+// StreamResult contém as métricas de uma única sessão de streaming.
+type StreamResult struct {
+	Sent       int64     // Mensagens enviadas
+	Received   int64     // Mensagens (ou eventos SSE) recebidas
+	Latency    Histogram // Latência das mensagens (ver StreamStats.MessageLatency)
+	Disconnect string    // Motivo do encerramento da sessão
+}
+
+// StreamStats agrega as métricas das sessões WebSocket/SSE.
+type StreamStats struct {
+	MessagesSent     int64 `json:"messages_sent"`     // Total de mensagens enviadas
+	MessagesReceived int64 `json:"messages_received"` // Total de mensagens (ou eventos SSE) recebidas
+
+	// Latência das mensagens: no WebSocket, o tempo entre o envio de uma mensagem e a próxima
+	// mensagem recebida; no SSE, o intervalo entre eventos consecutivos (o primeiro é medido
+	// a partir da abertura do stream).
+	MessageLatency Histogram `json:"message_latency"`
+
+	Disconnects map[string]int `json:"disconnects"` // Sessões encerradas por motivo
+}
+
+// newStreamStats cria um StreamStats vazio pronto para receber sessões.
+func newStreamStats() *StreamStats {
+	return &StreamStats{Disconnects: make(map[string]int)}
+}
+
+// add contabiliza uma sessão nas métricas.
+func (s *StreamStats) add(result *StreamResult) {
+	s.MessagesSent += result.Sent
+	s.MessagesReceived += result.Received
+	s.MessageLatency.Merge(result.Latency)
+	s.Disconnects[result.Disconnect]++
+}
+
+// merge soma as métricas de outro StreamStats neste.
+func (s *StreamStats) merge(other StreamStats) {
+	s.MessagesSent += other.MessagesSent
+	s.MessagesReceived += other.MessagesReceived
+	s.MessageLatency.Merge(other.MessageLatency)
+	if s.Disconnects == nil {
+		s.Disconnects = make(map[string]int, len(other.Disconnects))
+	}
+	for reason, count := range other.Disconnects {
+		s.Disconnects[reason] += count
+	}
+}
+
+// streamExecutor abre as sessões WebSocket ou SSE do teste.
+type streamExecutor struct {
+	config *StreamConfig     // Sessões a serem abertas
+	dialer *websocket.Dialer // Usado nas conexões WebSocket
+	client *http.Client      // Usado nas conexões SSE (sem timeout total, a sessão é longa)
+	err    error             // Erro nos ajustes de TLS ou proxy, registrado em cada sessão WebSocket
+}
+
+// newStreamExecutor cria o executor reaproveitando os ajustes de transporte do teste.
+func newStreamExecutor(config Config) *streamExecutor {
+	client := newClient(config)
+	client.Timeout = 0
+
+	t := config.Transport
+	dialer := &net.Dialer{Timeout: orDefault(t.DialTimeout, 10*time.Second), KeepAlive: 30 * time.Second}
+
+	e := &streamExecutor{config: config.Stream, client: client}
+	tlsConfig, err := t.tlsConfig()
+	if err != nil {
+		e.err = err
+		return e
+	}
+	proxy, err := t.proxy()
+	if err != nil {
+		e.err = err
+		return e
+	}
+	e.dialer = &websocket.Dialer{
+		Proxy:            proxy,
+		NetDialContext:   t.dialContext(dialer),
+		HandshakeTimeout: orDefault(t.Timeout, DefaultTimeout),
+		TLSClientConfig:  tlsConfig,
+	}
+	return e
+}
+
+// Execute abre uma sessão, mantendo-a aberta pela duração configurada ou até ctx ser cancelado.
+// A duração do Result é o tempo de conexão (handshake WebSocket ou cabeçalhos do SSE).
+func (e *streamExecutor) Execute(ctx context.Context) Result {
+	if e.config.Protocol == ProtocolSSE {
+		return e.sse(ctx)
+	}
+	return e.websocket(ctx)
+}
+
+// Close fecha as conexões ociosas do cliente SSE.
+func (e *streamExecutor) Close() {
+	e.client.CloseIdleConnections()
+}
+
+// websocket abre uma conexão WebSocket, envia as mensagens programadas e lê as mensagens
+// recebidas até o fim da sessão.
+func (e *streamExecutor) websocket(ctx context.Context) Result {
+	if e.err != nil {
+		return Result{Error: &requestError{e.err}, Protocol: ProtocolWebSocket}
+	}
+
+	header := make(http.Header, len(e.config.Headers))
+	for name, value := range e.config.Headers {
+		header.Set(name, value)
+	}
+
+	start := time.Now()
+	conn, resp, err := e.dialer.DialContext(ctx, e.config.URL, header)
+	connect := time.Since(start)
+
+	if err != nil {
+		// Handshake recusado pelo servidor: registra o status HTTP retornado
+		if resp != nil {
+			resp.Body.Close()
+			return Result{StatusCode: resp.StatusCode, Duration: connect, Protocol: ProtocolWebSocket}
+		}
+		return Result{Duration: connect, Error: err, Protocol: ProtocolWebSocket}
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(orDefault(e.config.Duration, DefaultStreamDuration)))
+
+	// Cancelar ctx antecipa o fim da sessão, que termina como completa
+	stopWatching := context.AfterFunc(ctx, func() {
+		conn.SetReadDeadline(time.Now())
+	})
+	defer stopWatching()
+
+	stream := &StreamResult{}
+
+	// Instantes de envio das mensagens ainda sem resposta, em ordem
+	var mu sync.Mutex
+	var pending []time.Time
+
+	// Envia as mensagens programadas em paralelo à leitura
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		interval := orDefault(e.config.MessageInterval, defaultMessageInterval)
+		for i, message := range e.config.Messages {
+			if i > 0 {
+				select {
+				case <-time.After(interval):
+				case <-done:
+					return
+				}
+			}
+
+			mu.Lock()
+			pending = append(pending, time.Now())
+			mu.Unlock()
+
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+				return
+			}
+			stream.Sent++
+		}
+	}()
+
+	// Lê as mensagens até o fim da sessão ou até a conexão ser encerrada
+	for {
+		_, _, err := conn.ReadMessage()
+		if err != nil {
+			stream.Disconnect = disconnectReason(err)
+			break
+		}
+		stream.Received++
+
+		// A mensagem recebida responde à mensagem enviada mais antiga ainda pendente
+		mu.Lock()
+		if len(pending) > 0 {
+			stream.Latency.Record(time.Since(pending[0]))
+			pending = pending[1:]
+		}
+		mu.Unlock()
+	}
+	close(done)
+	wg.Wait()
+
+	// Encerramento educado quando a sessão termina por iniciativa do cliente
+	if stream.Disconnect == DisconnectCompleted {
+		message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+	}
+
+	return Result{
+		StatusCode: resp.StatusCode,
+		Duration:   connect,
+		Protocol:   ProtocolWebSocket,
+		Stream:     stream,
+	}
+}
+
+// disconnectReason identifica o motivo do encerramento de uma sessão WebSocket.
+// O único prazo de leitura é o fim da sessão, então um timeout indica sessão completa.
+func disconnectReason(err error) string {
+	var closeErr *websocket.CloseError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return DisconnectCompleted
+	case errors.As(err, &closeErr) && closeErr.Code == websocket.CloseNormalClosure:
+		return DisconnectServerClosed
+	case errors.As(err, &closeErr):
+		return fmt.Sprintf("close_%d", closeErr.Code)
+	}
+	return ClassifyError(err)
+}
+
+// sse abre um stream de Server-Sent Events e conta os eventos recebidos até o fim da sessão.
+func (e *streamExecutor) sse(parent context.Context) Result {
+	ctx, cancel := context.WithTimeout(parent, orDefault(e.config.Duration, DefaultStreamDuration))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.config.URL, nil)
+	if err != nil {
+		return Result{Error: &requestError{err}, Protocol: ProtocolSSE}
+	}
+	for name, value := range e.config.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	start := time.Now()
+	resp, err := e.client.Do(req)
+	connect := time.Since(start)
+	if err != nil {
+		return Result{Duration: connect, Error: err, Protocol: ProtocolSSE}
+	}
+	defer resp.Body.Close()
+
+	// Sem stream: registra apenas o status retornado
+	if resp.StatusCode != http.StatusOK {
+		return Result{StatusCode: resp.StatusCode, Duration: connect, Protocol: ProtocolSSE}
+	}
+
+	// Um evento termina em uma linha vazia e só conta quando possui dados
+	stream := &StreamResult{}
+	last := time.Now()
+	hasData := false
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxAssertionBody)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			hasData = hasData || strings.HasPrefix(line, "data:")
+			continue
+		}
+		if hasData {
+			now := time.Now()
+			stream.Received++
+			stream.Latency.Record(now.Sub(last))
+			last = now
+			hasData = false
+		}
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		stream.Disconnect = DisconnectCompleted
+	case scanner.Err() == nil:
+		stream.Disconnect = DisconnectServerClosed
+	default:
+		stream.Disconnect = ClassifyError(scanner.Err())
+	}
+
+	return Result{
+		StatusCode: resp.StatusCode,
+		Duration:   connect,
+		Protocol:   ProtocolSSE,
+		Stream:     stream,
+	}
+}
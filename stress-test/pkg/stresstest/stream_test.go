@@ -0,0 +1,218 @@
+package stresstest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// novoServidorWebSocket sobe um servidor WebSocket que devolve cada mensagem recebida.
+// Mensagens "fechar" fazem o servidor encerrar a conexão com o código 1001.
+func novoServidorWebSocket(t *testing.T) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "não autorizado", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			tipo, mensagem, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if string(mensagem) == "fechar" {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			conn.WriteMessage(tipo, mensagem)
+		}
+	}))
+	t.Cleanup(servidor.Close)
+
+	return "ws" + strings.TrimPrefix(servidor.URL, "http")
+}
+
+func TestRun_WebSocket(t *testing.T) {
+	url := novoServidorWebSocket(t)
+
+	config := Config{
+		Stream: &StreamConfig{
+			Protocol:        ProtocolWebSocket,
+			URL:             url,
+			Headers:         map[string]string{"Authorization": "Bearer token"},
+			Messages:        []string{"a", "b", "c"},
+			MessageInterval: 10 * time.Millisecond,
+			Duration:        200 * time.Millisecond,
+		},
+		Requests:    4,
+		Concurrency: 4,
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Erro inesperado na validação: %v", err)
+	}
+
+	report := Run(context.Background(), config)
+	if report.Protocol != ProtocolWebSocket {
+		t.Errorf("Esperado protocolo websocket, obtido %q", report.Protocol)
+	}
+	if report.SuccessCount != 4 || report.StatusCodes[http.StatusSwitchingProtocols] != 4 {
+		t.Fatalf("Esperado 4 conexões estabelecidas, obtido %d (%v)", report.SuccessCount, report.StatusCodes)
+	}
+
+	stream := report.Stream
+	if stream == nil {
+		t.Fatal("Esperado métricas de streaming no relatório")
+	}
+	if stream.MessagesSent != 12 || stream.MessagesReceived != 12 || stream.MessageLatency.Total != 12 {
+		t.Errorf("Esperado 12 mensagens enviadas e recebidas, obtido %d/%d (%d latências)",
+			stream.MessagesSent, stream.MessagesReceived, stream.MessageLatency.Total)
+	}
+	if stream.Disconnects[DisconnectCompleted] != 4 {
+		t.Errorf("Esperado 4 sessões completas, obtido %v", stream.Disconnects)
+	}
+}
+
+func TestRun_WebSocketDesconexoes(t *testing.T) {
+	url := novoServidorWebSocket(t)
+
+	// Servidor encerra a conexão com o código 1001
+	config := Config{
+		Stream: &StreamConfig{
+			Protocol: ProtocolWebSocket,
+			URL:      url,
+			Headers:  map[string]string{"Authorization": "Bearer token"},
+			Messages: []string{"fechar"},
+			Duration: time.Second,
+		},
+		Requests:    2,
+		Concurrency: 2,
+	}
+	report := Run(context.Background(), config)
+	if report.Stream == nil || report.Stream.Disconnects["close_1001"] != 2 {
+		t.Errorf("Esperado 2 desconexões close_1001, obtido %+v", report.Stream)
+	}
+
+	// Handshake recusado conta como status fora do esperado, sem métricas de streaming
+	config.Stream.Headers = nil
+	report = Run(context.Background(), config)
+	if report.StatusCodes[http.StatusUnauthorized] != 2 || report.Non2xxCount != 2 || report.SuccessCount != 0 {
+		t.Errorf("Esperado 2 handshakes recusados, obtido %v", report.StatusCodes)
+	}
+	if report.Stream != nil {
+		t.Errorf("Esperado relatório sem métricas de streaming, obtido %+v", report.Stream)
+	}
+}
+
+func TestRun_SSE(t *testing.T) {
+	// Servidor envia 3 eventos com dados (mais um comentário) e encerra o stream
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			http.Error(w, "esperado text/event-stream", http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": comentário\n\n")
+		for i := range 3 {
+			fmt.Fprintf(w, "event: preco\ndata: {\"valor\":%d}\n\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}))
+	defer servidor.Close()
+
+	config := Config{
+		Stream:      &StreamConfig{Protocol: ProtocolSSE, URL: servidor.URL, Duration: time.Second},
+		Requests:    3,
+		Concurrency: 3,
+	}
+	report := Run(context.Background(), config)
+
+	if report.Protocol != ProtocolSSE || report.SuccessCount != 3 {
+		t.Fatalf("Esperado 3 conexões SSE, obtido %d (%v)", report.SuccessCount, report.StatusCodes)
+	}
+	if report.Stream == nil || report.Stream.MessagesReceived != 9 || report.Stream.MessageLatency.Total != 9 {
+		t.Fatalf("Esperado 9 eventos recebidos, obtido %+v", report.Stream)
+	}
+	if report.Stream.Disconnects[DisconnectServerClosed] != 3 {
+		t.Errorf("Esperado 3 streams encerrados pelo servidor, obtido %v", report.Stream.Disconnects)
+	}
+}
+
+func TestRun_SSEDuracao(t *testing.T) {
+	// Servidor mantém o stream aberto indefinidamente
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for {
+			if _, err := fmt.Fprint(w, "data: ping\n\n"); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-time.After(20 * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}))
+	defer servidor.Close()
+
+	config := Config{
+		Stream:      &StreamConfig{Protocol: ProtocolSSE, URL: servidor.URL, Duration: 100 * time.Millisecond},
+		Requests:    2,
+		Concurrency: 2,
+	}
+	report := Run(context.Background(), config)
+
+	if report.Stream == nil || report.Stream.Disconnects[DisconnectCompleted] != 2 {
+		t.Fatalf("Esperado 2 sessões completas, obtido %+v", report.Stream)
+	}
+	if report.Stream.MessagesReceived == 0 {
+		t.Error("Esperado eventos recebidos durante a sessão")
+	}
+}
+
+func TestStreamConfig_Validacao(t *testing.T) {
+	invalidas := map[string]Config{
+		"sem URL":           {Stream: &StreamConfig{Protocol: ProtocolWebSocket}, Requests: 1, Concurrency: 1},
+		"esquema WebSocket": {Stream: &StreamConfig{Protocol: ProtocolWebSocket, URL: "http://localhost"}, Requests: 1, Concurrency: 1},
+		"esquema SSE":       {Stream: &StreamConfig{Protocol: ProtocolSSE, URL: "ws://localhost"}, Requests: 1, Concurrency: 1},
+		"mensagens no SSE":  {Stream: &StreamConfig{Protocol: ProtocolSSE, URL: "http://localhost", Messages: []string{"a"}}, Requests: 1, Concurrency: 1},
+		"protocolo":         {Stream: &StreamConfig{Protocol: "mqtt", URL: "ws://localhost"}, Requests: 1, Concurrency: 1},
+		"duração negativa":  {Stream: &StreamConfig{Protocol: ProtocolSSE, URL: "http://localhost", Duration: -time.Second}, Requests: 1, Concurrency: 1},
+		"com URL HTTP":      {RequestSpec: RequestSpec{URL: "http://localhost"}, Stream: &StreamConfig{Protocol: ProtocolSSE, URL: "http://localhost"}, Requests: 1, Concurrency: 1},
+	}
+	for nome, config := range invalidas {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: esperado erro de validação", nome)
+		}
+	}
+}
+
+func TestStats_MergeStream(t *testing.T) {
+	a := newStats()
+	a.add(Result{StatusCode: 101, Protocol: ProtocolWebSocket, Stream: &StreamResult{Sent: 2, Received: 2, Disconnect: DisconnectCompleted}})
+	b := newStats()
+	b.add(Result{StatusCode: 101, Protocol: ProtocolWebSocket, Stream: &StreamResult{Sent: 1, Received: 0, Disconnect: "close_1006"}})
+
+	a.merge(b)
+	if a.Stream.MessagesSent != 3 || a.Stream.MessagesReceived != 2 {
+		t.Errorf("Esperado 3 enviadas e 2 recebidas, obtido %d/%d", a.Stream.MessagesSent, a.Stream.MessagesReceived)
+	}
+	if a.Stream.Disconnects[DisconnectCompleted] != 1 || a.Stream.Disconnects["close_1006"] != 1 {
+		t.Errorf("Esperado desconexões somadas, obtido %v", a.Stream.Disconnects)
+	}
+}
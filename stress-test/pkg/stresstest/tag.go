@@ -0,0 +1,129 @@
+package stresstest
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Agrupamentos automáticos das requisições em tags (Config.TagBy).
+const (
+	TagByName = "name" // Nome da requisição do cenário ou do passo do fluxo
+	TagByURL  = "url"  // Método, host e caminho normalizado (ex: GET api.exemplo.com/users/:id)
+)
+
+// maxTags limita quantas tags distintas têm métricas próprias no teste; as demais são
+// agrupadas em otherTags para que a memória não cresça com a variedade de tags.
+const maxTags = 100
+
+// otherTags agrupa as requisições das tags que excedem o limite.
+const otherTags = "(outros)"
+
+// TagReport contém as métricas das requisições de uma tag.
+// Permite identificar qual endpoint (ou grupo de requisições) é o responsável pela degradação.
+type TagReport struct {
+	Tag   string `json:"tag"` // Tag das requisições (ex: checkout, GET /users/:id)
+	Stats        // Métricas das requisições com a tag
+}
+
+// validateTagBy verifica o agrupamento automático das requisições em tags.
+func validateTagBy(tagBy string) error {
+	switch tagBy {
+	case "", TagByName, TagByURL:
+		return nil
+	}
+	return fmt.Errorf("agrupamento de tags inválido %q: use %s ou %s", tagBy, TagByName, TagByURL)
+}
+
+// requestTag retorna a tag de uma requisição: a tag informada na requisição ou, na ausência dela,
+// a gerada pelo agrupamento tagBy. Sem nome, o agrupamento por nome usa a URL.
+func requestTag(tagBy, name string, spec RequestSpec) string {
+	if spec.Tag != "" {
+		return spec.Tag
+	}
+	switch {
+	case tagBy == TagByName && name != "":
+		return name
+	case tagBy == TagByName || tagBy == TagByURL:
+		return urlTag(spec)
+	}
+	return ""
+}
+
+// urlTag identifica a requisição pelo método, host e caminho normalizado, de modo que
+// /users/1 e /users/2 (inclusive quando gerados por templates) fiquem na mesma tag.
+func urlTag(spec RequestSpec) string {
+	parsed, err := url.Parse(spec.URL)
+	if err != nil || spec.URL == "" {
+		return ""
+	}
+	return spec.method() + " " + parsed.Host + normalizePath(cmp.Or(parsed.EscapedPath(), "/"))
+}
+
+// tagSet decide quais tags têm métricas próprias, compartilhado por todos os workers do teste.
+// As primeiras maxTags tags distintas são aceitas e as seguintes vão para otherTags em todos os
+// workers, de modo que uma tag nunca fica dividida entre a sua linha e otherTags.
+type tagSet struct {
+	mu   sync.Mutex          // Protege o conjunto, consultado por todos os workers
+	tags map[string]struct{} // Tags aceitas até o momento
+}
+
+// newTagSet cria um conjunto de tags vazio.
+func newTagSet() *tagSet {
+	return &tagSet{tags: make(map[string]struct{})}
+}
+
+// resolve retorna a tag em que o resultado é contabilizado: a própria tag ou, acima do limite,
+// otherTags.
+func (s *tagSet) resolve(tag string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tags[tag]; !ok {
+		if len(s.tags) >= maxTags {
+			return otherTags
+		}
+		s.tags[tag] = struct{}{}
+	}
+	return tag
+}
+
+// tagMerger soma as métricas por tag de vários workers (ou agentes), indexando as tags pelo
+// nome para que cada soma não percorra a lista.
+type tagMerger struct {
+	tags  []TagReport    // Tags na ordem em que apareceram
+	index map[string]int // Posição de cada tag em tags
+}
+
+// merge soma as métricas de uma tag.
+func (m *tagMerger) merge(tag string, stats Stats) {
+	i, ok := m.index[tag]
+	if !ok {
+		if m.index == nil {
+			m.index = make(map[string]int)
+		}
+		i = len(m.tags)
+		m.index[tag] = i
+		m.tags = append(m.tags, TagReport{Tag: tag, Stats: newStats()})
+	}
+	m.tags[i].Stats.merge(stats)
+}
+
+// sorted retorna as tags somadas, da mais à menos frequente (nil sem tags).
+func (m *tagMerger) sorted() []TagReport {
+	sortTags(m.tags)
+	return m.tags
+}
+
+// sortTags ordena as tags pelo número de requisições, da mais frequente à menos frequente.
+func sortTags(tags []TagReport) {
+	slices.SortStableFunc(tags, func(a, b TagReport) int {
+		if a.TotalRequests != b.TotalRequests {
+			return b.TotalRequests - a.TotalRequests
+		}
+		return strings.Compare(a.Tag, b.Tag)
+	})
+}
@@ -0,0 +1,179 @@
+package stresstest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestTag(t *testing.T) {
+	pedido := RequestSpec{Method: "post", URL: "http://api.local:8080/orders/42/items?full=1"}
+
+	casos := []struct {
+		nome       string
+		tagBy      string
+		requisicao string
+		spec       RequestSpec
+		esperado   string
+	}{
+		{"sem agrupamento", "", "criar-pedido", pedido, ""},
+		{"tag informada", TagByURL, "criar-pedido", RequestSpec{URL: pedido.URL, Tag: "checkout"}, "checkout"},
+		{"por nome", TagByName, "criar-pedido", pedido, "criar-pedido"},
+		{"por nome sem nome usa a URL", TagByName, "", pedido, "POST api.local:8080/orders/:id/items"},
+		{"por URL", TagByURL, "criar-pedido", pedido, "POST api.local:8080/orders/:id/items"},
+		{"URL sem caminho", TagByURL, "", RequestSpec{URL: "http://api.local"}, "GET api.local/"},
+	}
+	for _, caso := range casos {
+		if obtido := requestTag(caso.tagBy, caso.requisicao, caso.spec); obtido != caso.esperado {
+			t.Errorf("%s: esperado %q, obtido %q", caso.nome, caso.esperado, obtido)
+		}
+	}
+}
+
+func TestRun_MetricasPorTagNoCenario(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/checkout" {
+			time.Sleep(5 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	scenario := &Scenario{Requests: []ScenarioRequest{
+		{Name: "busca", RequestSpec: RequestSpec{URL: server.URL + "/search?q={{randInt 1 100}}"}},
+		{Name: "pagar", RequestSpec: RequestSpec{Method: "POST", URL: server.URL + "/checkout", Tag: "checkout"}},
+	}}
+	config := Config{Scenario: scenario, TagBy: TagByName, Requests: 200, Concurrency: 4}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	report := Run(context.Background(), config)
+
+	if len(report.Tags) != 2 || report.Tags[0].TotalRequests+report.Tags[1].TotalRequests != 200 {
+		t.Fatalf("Esperado 2 tags somando 200 requests, obtido %+v", report.Tags)
+	}
+	tags := make(map[string]TagReport)
+	for _, tag := range report.Tags {
+		tags[tag.Tag] = tag
+	}
+	// A tag informada na requisição prevalece sobre o nome
+	checkout, busca := tags["checkout"], tags["busca"]
+	if checkout.StatusCodes[http.StatusServiceUnavailable] != checkout.TotalRequests || checkout.Non2xxCount == 0 {
+		t.Errorf("Esperado apenas 503 na tag checkout, obtido %+v", checkout.StatusCodes)
+	}
+	if busca.StatusCodes[http.StatusOK] != busca.TotalRequests || busca.TotalRequests == 0 {
+		t.Errorf("Esperado apenas 200 na tag busca, obtido %+v", busca.StatusCodes)
+	}
+	if checkout.Latency.Percentile(50) < 5*time.Millisecond || busca.Latency.Percentile(50) >= 5*time.Millisecond {
+		t.Errorf("Latências por tag incorretas: checkout p50 %v, busca p50 %v",
+			checkout.Latency.Percentile(50), busca.Latency.Percentile(50))
+	}
+
+	var saida bytes.Buffer
+	if err := WriteReport(&saida, report); err != nil || !strings.Contains(saida.String(), "Métricas por tag") ||
+		!strings.Contains(saida.String(), fmt.Sprintf("503: %d", checkout.TotalRequests)) {
+		t.Errorf("Tabela de tags ausente (%v):\n%s", err, saida.String())
+	}
+}
+
+func TestRun_TagPorURLNoFluxo(t *testing.T) {
+	server := novoServidorVazio(t)
+
+	// Os dois passos chamam o mesmo endpoint com identificadores diferentes
+	flow := &Flow{Steps: []FlowStep{
+		{Name: "primeiro", RequestSpec: RequestSpec{URL: server.URL + "/users/1"}},
+		{Name: "segundo", RequestSpec: RequestSpec{URL: server.URL + "/users/{{randInt 2 9}}"}},
+	}}
+	config := Config{Flow: flow, TagBy: TagByURL, Requests: 10, Concurrency: 2}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	report := Run(context.Background(), config)
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	if len(report.Tags) != 1 || report.Tags[0].Tag != "GET "+host+"/users/:id" || report.Tags[0].TotalRequests != 20 {
+		t.Errorf("Esperado uma tag com os 20 requests, obtido %+v", report.Tags)
+	}
+}
+
+// executorComTags marca cada resultado com uma tag diferente
+type executorComTags struct {
+	execucoes atomic.Int64
+}
+
+func (e *executorComTags) Execute(ctx context.Context) Result {
+	n := e.execucoes.Add(1)
+	return Result{StatusCode: http.StatusOK, Duration: time.Millisecond, Tag: fmt.Sprintf("tag-%d", n)}
+}
+
+func (e *executorComTags) Close() {}
+
+func TestRunner_TagsDoExecutorLimitadas(t *testing.T) {
+	// Os workers recebem juntos mais tags distintas que o limite: o limite vale para o teste
+	// inteiro e as excedentes são agrupadas
+	report, err := NewRunner(Config{Requests: maxTags + 20, Concurrency: 4}, WithExecutor(&executorComTags{})).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(report.Tags) != maxTags+1 || report.Tags[0].Tag != otherTags || report.Tags[0].TotalRequests != 20 {
+		t.Fatalf("Esperado %d tags com %q agrupando 20 requests, obtido %d tags (primeira %+v)",
+			maxTags+1, otherTags, len(report.Tags), report.Tags[0].Tag)
+	}
+	for _, tag := range report.Tags[1:] {
+		if tag.TotalRequests != 1 {
+			t.Errorf("Esperado 1 request na tag %s, obtido %d", tag.Tag, tag.TotalRequests)
+		}
+	}
+}
+
+func TestTagSet_TagAceitaPermaneceAposLimite(t *testing.T) {
+	tags := newTagSet()
+	for i := range maxTags {
+		tags.resolve(fmt.Sprintf("tag-%d", i))
+	}
+	// Após o limite as tags já aceitas continuam com métricas próprias e as novas são agrupadas
+	if obtida := tags.resolve("tag-0"); obtida != "tag-0" {
+		t.Errorf("Esperado tag-0, obtido %q", obtida)
+	}
+	if obtida := tags.resolve("nova"); obtida != otherTags {
+		t.Errorf("Esperado %q, obtido %q", otherTags, obtida)
+	}
+}
+
+func TestTagMerger(t *testing.T) {
+	var merger tagMerger
+	for _, tag := range []string{"a", "b", "a", "c", "a", "b"} {
+		merger.merge(tag, Stats{TotalRequests: 1})
+	}
+	tags := merger.sorted()
+	if len(tags) != 3 || tags[0].Tag != "a" || tags[0].TotalRequests != 3 || tags[1].Tag != "b" || tags[2].TotalRequests != 1 {
+		t.Errorf("Tags somadas incorretamente: %+v", tags)
+	}
+}
+
+func TestConfig_ValidacaoTagBy(t *testing.T) {
+	casos := map[string]Config{
+		"agrupamento desconhecido": {RequestSpec: RequestSpec{URL: "http://x"}, TagBy: "host", Requests: 1, Concurrency: 1},
+		"com streaming":            {Stream: &StreamConfig{Protocol: ProtocolSSE, URL: "http://x"}, TagBy: TagByURL, Requests: 1, Concurrency: 1},
+		"com replay": {RequestSpec: RequestSpec{URL: "http://x"}, Replay: &Replay{Entries: []LogEntry{{Method: "GET", Path: "/"}}},
+			TagBy: TagByName, Concurrency: 1},
+	}
+	for nome, config := range casos {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: esperado erro", nome)
+		}
+		// Sem o agrupamento a configuração é válida
+		config.TagBy = ""
+		if err := config.Validate(); err != nil {
+			t.Errorf("%s: erro inesperado sem agrupamento: %v", nome, err)
+		}
+	}
+}
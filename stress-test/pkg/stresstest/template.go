@@ -0,0 +1,209 @@
+package stresstest
+
+import (
+	"crypto/rand"
+	"fmt"
+	mathrand "math/rand/v2"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs são as funções disponíveis nos templates das requisições do cenário.
+var templateFuncs = template.FuncMap{
+	"uuid":    newUUID,   // UUID v4 aleatório
+	"randInt": randomInt, // Inteiro aleatório em [min, max]
+}
+
+// compiledRequest é uma requisição do cenário com os templates já compilados.
+// Campos sem marcações de template são mantidos como texto e copiados sem custo.
+type compiledRequest struct {
+	name    string                        // Nome da requisição no cenário
+	weight  int                           // Peso relativo no sorteio
+	spec    RequestSpec                   // Especificação original (campos fixos)
+	url     *template.Template            // Template da URL
+	body    *template.Template            // Template do corpo
+	headers map[string]*template.Template // Templates dos valores dos cabeçalhos
+	query   map[string]*template.Template // Templates dos valores dos parâmetros de query
+}
+
+// templateData é o valor disponível como "." nos templates de uma requisição.
+// Cada request renderizado recebe sua própria instância, de forma que valores de feed
+// referenciados mais de uma vez no mesmo request vêm da mesma linha do CSV.
+type templateData struct {
+	feeds    map[string]*feed             // Feeds declarados no cenário ou no fluxo
+	seq      int64                        // Número de sequência do request
+	rows     map[string]map[string]string // Linhas de feed já consumidas por este request
+	vars     map[string]string            // Variáveis extraídas pelos passos anteriores do fluxo
+	declared map[string]bool              // Variáveis que os passos anteriores extraem (usado na validação)
+}
+
+// newTemplateData cria os dados de template para o request de sequência informada.
+// A sequência 0 é usada para validar os templates sem consumir linhas dos feeds.
+func (s *Scenario) newTemplateData(seq int64) *templateData {
+	return &templateData{feeds: s.feeds, seq: seq}
+}
+
+// Seq retorna o número de sequência do request (1, 2, 3...), único em toda a execução.
+func (d *templateData) Seq() int64 {
+	return d.seq
+}
+
+// Feed retorna o valor da coluna informada na linha do feed associada a este request.
+func (d *templateData) Feed(name, column string) (string, error) {
+	source, ok := d.feeds[name]
+	if !ok {
+		return "", fmt.Errorf("feed %q não declarado", name)
+	}
+
+	// Consome uma linha por feed por request (a validação usa sempre a primeira)
+	row, ok := d.rows[name]
+	if !ok {
+		if d.seq == 0 {
+			row = source.rows[0]
+		} else {
+			row = source.take()
+		}
+		if d.rows == nil {
+			d.rows = make(map[string]map[string]string)
+		}
+		d.rows[name] = row
+	}
+
+	value, ok := row[column]
+	if !ok {
+		return "", fmt.Errorf("coluna %q não existe no feed %q", column, name)
+	}
+	return value, nil
+}
+
+// Var retorna o valor de uma variável extraída por um passo anterior do fluxo.
+// Na validação (sequência 0) os valores ainda não existem: basta que algum passo anterior a extraia.
+func (d *templateData) Var(name string) (string, error) {
+	if d.seq == 0 {
+		if !d.declared[name] {
+			return "", fmt.Errorf("variável %q não é extraída por nenhum passo anterior", name)
+		}
+		return "", nil
+	}
+
+	value, ok := d.vars[name]
+	if !ok {
+		return "", fmt.Errorf("variável %q não foi extraída", name)
+	}
+	return value, nil
+}
+
+// compileRequest compila os templates presentes na especificação da requisição.
+func compileRequest(spec RequestSpec) (*compiledRequest, error) {
+	compiled := &compiledRequest{spec: spec}
+
+	var err error
+	if compiled.url, err = compileTemplate("url", spec.URL); err != nil {
+		return nil, err
+	}
+	if compiled.body, err = compileTemplate("body", spec.Body); err != nil {
+		return nil, err
+	}
+	if compiled.headers, err = compileTemplates("header", spec.Headers); err != nil {
+		return nil, err
+	}
+	if compiled.query, err = compileTemplates("query", spec.Query); err != nil {
+		return nil, err
+	}
+
+	return compiled, nil
+}
+
+// compileTemplate compila um texto como template, retornando nil quando não há marcações.
+func compileTemplate(name, text string) (*template.Template, error) {
+	if !strings.Contains(text, "{{") {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template inválido em %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// compileTemplates compila os valores de um mapa (cabeçalhos ou parâmetros de query).
+func compileTemplates(kind string, values map[string]string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template)
+	for key, value := range values {
+		tmpl, err := compileTemplate(kind+" "+key, value)
+		if err != nil {
+			return nil, err
+		}
+		if tmpl != nil {
+			templates[key] = tmpl
+		}
+	}
+	return templates, nil
+}
+
+// render produz a especificação final da requisição executando os templates.
+func (c *compiledRequest) render(data *templateData) (RequestSpec, error) {
+	spec := c.spec
+
+	var err error
+	if spec.URL, err = execute(c.url, spec.URL, data); err != nil {
+		return spec, err
+	}
+	if spec.Body, err = execute(c.body, spec.Body, data); err != nil {
+		return spec, err
+	}
+	if spec.Headers, err = executeAll(c.headers, spec.Headers, data); err != nil {
+		return spec, err
+	}
+	if spec.Query, err = executeAll(c.query, spec.Query, data); err != nil {
+		return spec, err
+	}
+
+	return spec, nil
+}
+
+// execute renderiza um template, ou retorna o texto original quando não há template.
+func execute(tmpl *template.Template, text string, data *templateData) (string, error) {
+	if tmpl == nil {
+		return text, nil
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("erro ao renderizar %s: %w", tmpl.Name(), err)
+	}
+	return out.String(), nil
+}
+
+// executeAll renderiza os valores de um mapa, copiando-o apenas quando há templates.
+func executeAll(templates map[string]*template.Template, values map[string]string, data *templateData) (map[string]string, error) {
+	if len(templates) == 0 {
+		return values, nil
+	}
+
+	rendered := make(map[string]string, len(values))
+	for key, value := range values {
+		result, err := execute(templates[key], value, data)
+		if err != nil {
+			return nil, err
+		}
+		rendered[key] = result
+	}
+	return rendered, nil
+}
+
+// randomInt retorna um inteiro aleatório no intervalo fechado [min, max].
+func randomInt(min, max int) (int, error) {
+	if max < min {
+		return 0, fmt.Errorf("randInt: máximo (%d) menor que mínimo (%d)", max, min)
+	}
+	return min + mathrand.IntN(max-min+1), nil
+}
+
+// newUUID gera um UUID versão 4 (aleatório) no formato textual padrão.
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40 // versão 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variante RFC 4122
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
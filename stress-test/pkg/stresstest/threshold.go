@@ -0,0 +1,241 @@
+package stresstest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Threshold é um critério de aprovação avaliado contra o relatório final, como "p95<300ms",
+// "error_rate<1%", "rps>500" ou "status_2xx>99%". Usado para reprovar builds de CI em regressões.
+//
+// Métricas suportadas:
+//   - pNN (ex: p50, p95, p99.9), avg, min, max: latência, comparada com durações (300ms, 1s)
+//   - error_rate: proporção de requests com erro de rede/timeout (1% ou 0.01)
+//   - assertion_fail_rate: proporção de respostas reprovadas nas asserções (1% ou 0.01)
+//   - status_NNN ou status_Nxx: proporção de respostas com o código ou a classe (99% ou 0.99)
+//   - rps: requests por segundo
+//   - requests: total de requests realizados
+type Threshold struct {
+	Expression string  `json:"expression"` // Expressão original, usada na exibição
+	Metric     string  `json:"metric"`     // Nome da métrica (p95, error_rate, status_2xx...)
+	Operator   string  `json:"operator"`   // Operador de comparação (<, <=, >, >=)
+	Value      float64 `json:"value"`      // Valor limite (durações em nanossegundos, proporções entre 0 e 1)
+}
+
+// CheckResult é o resultado da avaliação de um threshold.
+type CheckResult struct {
+	Threshold Threshold `json:"threshold"` // Threshold avaliado
+	Actual    float64   `json:"actual"`    // Valor observado da métrica (mesma unidade de Threshold.Value)
+	Passed    bool      `json:"passed"`    // Indica se o critério foi atendido
+}
+
+// thresholdPattern separa uma expressão em métrica, operador e valor.
+var thresholdPattern = regexp.MustCompile(`^\s*([a-z0-9_.]+)\s*(<=|>=|<|>)\s*(\S+)\s*$`)
+
+// statusPattern reconhece métricas de código (status_200) ou classe (status_2xx) de status.
+var statusPattern = regexp.MustCompile(`^status_([1-5])(xx|[0-9]{2})$`)
+
+// ParseThreshold interpreta uma expressão de threshold como "p95<300ms".
+func ParseThreshold(expression string) (Threshold, error) {
+	match := thresholdPattern.FindStringSubmatch(strings.ToLower(expression))
+	if match == nil {
+		return Threshold{}, fmt.Errorf("threshold inválido %q: use o formato métrica<valor (ex: p95<300ms)", expression)
+	}
+
+	threshold := Threshold{Expression: strings.TrimSpace(expression), Metric: match[1], Operator: match[2]}
+
+	var err error
+	switch kind := metricKind(threshold.Metric); kind {
+	case metricDuration:
+		// Percentis fora de (0, 100] não correspondem a nenhuma latência observada
+		if p, ok := strings.CutPrefix(threshold.Metric, "p"); ok {
+			if percentile, _ := strconv.ParseFloat(p, 64); !(percentile > 0 && percentile <= 100) {
+				return Threshold{}, fmt.Errorf("threshold %q: percentil deve estar entre 0 (exclusive) e 100", expression)
+			}
+		}
+		var d time.Duration
+		d, err = time.ParseDuration(match[3])
+		threshold.Value = float64(d)
+	case metricRatio:
+		threshold.Value, err = parseRatio(match[3])
+	case metricNumber:
+		threshold.Value, err = strconv.ParseFloat(match[3], 64)
+	default:
+		return Threshold{}, fmt.Errorf("threshold %q: métrica desconhecida %q", expression, threshold.Metric)
+	}
+	if err != nil {
+		return Threshold{}, fmt.Errorf("threshold %q: valor inválido: %w", expression, err)
+	}
+
+	return threshold, nil
+}
+
+// Tipos de métrica, que determinam como o valor é interpretado e exibido
+const (
+	metricUnknown  = iota
+	metricDuration // Latência (valor em nanossegundos)
+	metricRatio    // Proporção entre 0 e 1 (aceita porcentagem)
+	metricNumber   // Número simples
+)
+
+// metricKind identifica o tipo de uma métrica pelo nome.
+func metricKind(metric string) int {
+	switch {
+	case metric == "avg" || metric == "min" || metric == "max":
+		return metricDuration
+	case strings.HasPrefix(metric, "p"):
+		if _, err := strconv.ParseFloat(metric[1:], 64); err == nil {
+			return metricDuration
+		}
+	case metric == "error_rate" || metric == "assertion_fail_rate" || statusPattern.MatchString(metric):
+		return metricRatio
+	case metric == "rps" || metric == "requests":
+		return metricNumber
+	}
+	return metricUnknown
+}
+
+// parseRatio interpreta proporções como "1%" (0.01) ou "0.01".
+func parseRatio(value string) (float64, error) {
+	if number, ok := strings.CutSuffix(value, "%"); ok {
+		ratio, err := strconv.ParseFloat(number, 64)
+		return ratio / 100, err
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// EvaluateThresholds avalia todos os thresholds contra o relatório.
+func EvaluateThresholds(thresholds []Threshold, report Report) []CheckResult {
+	results := make([]CheckResult, 0, len(thresholds))
+	for _, threshold := range thresholds {
+		actual := metricValue(threshold.Metric, report)
+		results = append(results, CheckResult{
+			Threshold: threshold,
+			Actual:    actual,
+			Passed:    compare(actual, threshold.Operator, threshold.Value),
+		})
+	}
+	return results
+}
+
+// FailedChecks retorna quantos checks não foram atendidos.
+func FailedChecks(results []CheckResult) int {
+	failed := 0
+	for _, result := range results {
+		if !result.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// metricValue obtém o valor de uma métrica a partir do relatório.
+func metricValue(metric string, report Report) float64 {
+	switch metric {
+	case "avg":
+		return float64(report.Latency.Mean())
+	case "min":
+		return float64(report.Latency.Min)
+	case "max":
+		return float64(report.Latency.Max)
+	case "error_rate":
+		return ratio(report.ErrorCount, report.TotalRequests)
+	case "assertion_fail_rate":
+		return ratio(report.AssertionFailedCount, report.TotalRequests)
+	case "rps":
+		if report.TotalTime <= 0 {
+			return 0
+		}
+		return float64(report.TotalRequests) / report.TotalTime.Seconds()
+	case "requests":
+		return float64(report.TotalRequests)
+	}
+
+	if match := statusPattern.FindStringSubmatch(metric); match != nil {
+		return ratio(countStatus(report.StatusCodes, match), report.TotalRequests)
+	}
+
+	// Percentil de latência (pNN)
+	p, _ := strconv.ParseFloat(metric[1:], 64)
+	return float64(report.Latency.Percentile(p))
+}
+
+// countStatus soma as respostas que correspondem ao código ou à classe de status.
+func countStatus(codes map[int]int, match []string) int {
+	class, _ := strconv.Atoi(match[1])
+	count := 0
+	for code, n := range codes {
+		if match[2] == "xx" && code/100 == class {
+			count += n
+		} else if match[2] != "xx" && strconv.Itoa(code) == match[1]+match[2] {
+			count += n
+		}
+	}
+	return count
+}
+
+// ratio calcula a proporção part/total, retornando 0 quando total é zero.
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// compare aplica o operador de comparação do threshold.
+func compare(actual float64, operator string, limit float64) bool {
+	switch operator {
+	case "<":
+		return actual < limit
+	case "<=":
+		return actual <= limit
+	case ">":
+		return actual > limit
+	case ">=":
+		return actual >= limit
+	}
+	return false
+}
+
+// breached indica se o threshold já está irremediavelmente violado durante o teste,
+// considerando o melhor caso possível para os requests que ainda faltam.
+// Apenas métricas de contagem (error_rate e status) podem ser decididas antes do fim.
+func (t Threshold) breached(s Snapshot) bool {
+	if s.Planned <= 0 {
+		return false
+	}
+	remaining := max(0, s.Planned-s.Completed)
+
+	var count int
+	if t.Metric == "error_rate" {
+		count = s.Errors
+	} else if match := statusPattern.FindStringSubmatch(t.Metric); match != nil {
+		count = countStatus(s.StatusCodes, match)
+	} else {
+		return false
+	}
+
+	switch t.Operator {
+	case "<", "<=":
+		// A contagem só pode crescer: mesmo sem novas ocorrências o limite já foi ultrapassado
+		return !compare(ratio(count, s.Planned), t.Operator, t.Value)
+	default:
+		// Mesmo que todos os requests restantes contem, o mínimo não será alcançado
+		return !compare(ratio(count+remaining, s.Planned), t.Operator, t.Value)
+	}
+}
+
+// formatMetric formata o valor de uma métrica para exibição conforme o seu tipo.
+func formatMetric(metric string, value float64) string {
+	switch metricKind(metric) {
+	case metricDuration:
+		return round(time.Duration(value)).String()
+	case metricRatio:
+		return fmt.Sprintf("%.2f%%", value*100)
+	default:
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}
+}
@@ -0,0 +1,120 @@
+package stresstest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseThreshold(t *testing.T) {
+	casos := map[string]Threshold{
+		"p95<300ms":       {Metric: "p95", Operator: "<", Value: float64(300 * time.Millisecond)},
+		"p99.9 <= 1s":     {Metric: "p99.9", Operator: "<=", Value: float64(time.Second)},
+		"error_rate<1%":   {Metric: "error_rate", Operator: "<", Value: 0.01},
+		"rps>500":         {Metric: "rps", Operator: ">", Value: 500},
+		"status_2xx>99%":  {Metric: "status_2xx", Operator: ">", Value: 0.99},
+		"status_429<0.05": {Metric: "status_429", Operator: "<", Value: 0.05},
+
+		"assertion_fail_rate<2%": {Metric: "assertion_fail_rate", Operator: "<", Value: 0.02},
+	}
+	for expressao, esperado := range casos {
+		obtido, err := ParseThreshold(expressao)
+		if err != nil {
+			t.Errorf("%s: erro inesperado: %v", expressao, err)
+			continue
+		}
+		if obtido.Metric != esperado.Metric || obtido.Operator != esperado.Operator || obtido.Value != esperado.Value {
+			t.Errorf("%s: esperado %+v, obtido %+v", expressao, esperado, obtido)
+		}
+	}
+
+	for _, invalido := range []string{"p95", "latencia<1s", "p95<abc", "status_6xx>1%", "rps>muito",
+		"p150<1s", "p0<1s", "p-5<1s", "pinf<1s", "pnan<1s"} {
+		if _, err := ParseThreshold(invalido); err == nil {
+			t.Errorf("Esperado erro para o threshold %q", invalido)
+		}
+	}
+}
+
+func TestEvaluateThresholds(t *testing.T) {
+	report := Report{
+		Stats: Stats{
+			TotalRequests: 100,
+			SuccessCount:  95,
+			ErrorCount:    2,
+			StatusCodes:   map[int]int{200: 95, 503: 3},
+
+			AssertionFailedCount: 1,
+		},
+		TotalTime: 2 * time.Second,
+	}
+	for i := 0; i < 98; i++ {
+		report.Latency.Record(100 * time.Millisecond)
+	}
+
+	casos := map[string]bool{
+		"p95<300ms":      true,
+		"avg>200ms":      false,
+		"error_rate<1%":  false,
+		"error_rate<=2%": true,
+		"rps>=50":        true,
+		"status_2xx>99%": false,
+		"status_5xx<5%":  true,
+		"requests>=100":  true,
+
+		"assertion_fail_rate<1%":  false,
+		"assertion_fail_rate<=1%": true,
+	}
+	for expressao, esperado := range casos {
+		threshold, _ := ParseThreshold(expressao)
+		resultado := EvaluateThresholds([]Threshold{threshold}, report)[0]
+		if resultado.Passed != esperado {
+			t.Errorf("%s: esperado aprovado=%v (observado %v)", expressao, esperado, resultado.Actual)
+		}
+	}
+}
+
+func TestThreshold_Breached(t *testing.T) {
+	snapshot := Snapshot{Completed: 50, Planned: 100, Errors: 2, StatusCodes: map[int]int{200: 40, 500: 8}}
+
+	casos := map[string]bool{
+		"error_rate<1%":  true,  // 2 erros em 100 já ultrapassam 1%
+		"error_rate<5%":  false, // ainda é possível terminar abaixo de 5%
+		"status_2xx>95%": true,  // mesmo com 50 sucessos restantes o máximo é 90%
+		"status_2xx>85%": false,
+		"p95<1ms":        false, // latência não é decidida antes do fim
+	}
+	for expressao, esperado := range casos {
+		threshold, _ := ParseThreshold(expressao)
+		if obtido := threshold.breached(snapshot); obtido != esperado {
+			t.Errorf("%s: esperado violado=%v, obtido %v", expressao, esperado, obtido)
+		}
+	}
+}
+
+func TestRun_AbortOnFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	threshold, _ := ParseThreshold("status_5xx<1%")
+	report := Run(context.Background(), Config{
+		RequestSpec:      RequestSpec{URL: server.URL},
+		Requests:         10000,
+		Concurrency:      4,
+		ProgressInterval: 10 * time.Millisecond,
+		Thresholds:       []Threshold{threshold},
+		AbortOnFail:      true,
+	})
+
+	if report.Aborted == "" {
+		t.Fatal("Esperado teste interrompido pelo threshold")
+	}
+	if report.TotalRequests >= 10000 {
+		t.Errorf("Teste deveria ter sido interrompido antes do fim: %d requests", report.TotalRequests)
+	}
+}
@@ -0,0 +1,115 @@
+package stresstest
+
+import "time"
+
+// TimelineInterval é a largura de cada intervalo da linha do tempo do relatório.
+const TimelineInterval = time.Second
+
+// TimelineBucket contém as métricas dos requests concluídos em um intervalo da linha do tempo.
+// A sequência de intervalos permite acompanhar como latência e throughput evoluíram durante o teste.
+type TimelineBucket struct {
+	Start     time.Duration `json:"start"`               // Início do intervalo relativo ao início do teste
+	Requests  int           `json:"requests"`            // Requests concluídos no intervalo
+	Errors    int           `json:"errors"`              // Requests concluídos com erro de transporte
+	Non2xx    int           `json:"non_2xx"`             // Respostas com status fora da faixa 2xx (diferente de OK no gRPC)
+	Throttled int           `json:"throttled,omitempty"` // Respostas 429 (recusadas por limite de taxa)
+	Latency   Histogram     `json:"latency"`             // Latência das respostas recebidas no intervalo
+}
+
+// recordTimeline contabiliza um resultado concluído após elapsed no intervalo correspondente,
+// criando os intervalos que ainda não existem.
+func recordTimeline(timeline []TimelineBucket, elapsed time.Duration, result Result) []TimelineBucket {
+	timeline = growTimeline(timeline, int(elapsed/TimelineInterval)+1)
+	timeline[elapsed/TimelineInterval].record(result)
+	return timeline
+}
+
+// startTimeline inicia a montagem da linha do tempo do teste. A cada TimelineInterval as métricas
+// registradas pelos workers desde a coleta anterior formam um novo intervalo, de forma que existe
+// uma única linha do tempo no teste e os workers guardam apenas o intervalo em andamento.
+// Retorna uma função que interrompe a coleta e devolve a linha do tempo completa.
+func startTimeline(startTime time.Time, accumulators []*accumulator) (stop func() []TimelineBucket) {
+	var timeline []TimelineBucket
+	collect := func(index int) {
+		timeline = growTimeline(timeline, index+1)
+		for _, acc := range accumulators {
+			acc.collectTimeline(&timeline[index])
+		}
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(TimelineInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				// Cada tick encerra o intervalo que acabou de terminar
+				elapsed := now.Sub(startTime) + TimelineInterval/2
+				collect(max(0, int(elapsed/TimelineInterval)-1))
+			}
+		}
+	}()
+
+	return func() []TimelineBucket {
+		close(done)
+		<-finished
+
+		// Os resultados posteriores ao último tick pertencem ao intervalo em andamento
+		collect(int(time.Since(startTime) / TimelineInterval))
+
+		// Intervalos finais sem requests não fazem parte do teste (ex: espera do período de graça)
+		for len(timeline) > 0 && timeline[len(timeline)-1].Requests == 0 {
+			timeline = timeline[:len(timeline)-1]
+		}
+		return timeline
+	}
+}
+
+// record contabiliza um resultado concluído no intervalo.
+func (b *TimelineBucket) record(result Result) {
+	b.Requests++
+	switch {
+	case result.Error != nil:
+		b.Errors++
+		return
+	case !result.ok():
+		b.Non2xx++
+	}
+	if result.throttled() {
+		b.Throttled++
+	}
+	b.Latency.Record(result.Duration)
+}
+
+// mergeTimeline soma os intervalos de other nos intervalos de mesmo índice de timeline.
+func mergeTimeline(timeline, other []TimelineBucket) []TimelineBucket {
+	timeline = growTimeline(timeline, len(other))
+	for i, bucket := range other {
+		timeline[i].merge(bucket)
+	}
+	return timeline
+}
+
+// merge soma as métricas de outro intervalo neste.
+func (b *TimelineBucket) merge(other TimelineBucket) {
+	b.Requests += other.Requests
+	b.Errors += other.Errors
+	b.Non2xx += other.Non2xx
+	b.Throttled += other.Throttled
+	b.Latency.Merge(other.Latency)
+}
+
+// growTimeline garante que a linha do tempo tenha pelo menos n intervalos.
+func growTimeline(timeline []TimelineBucket, n int) []TimelineBucket {
+	for i := len(timeline); i < n; i++ {
+		timeline = append(timeline, TimelineBucket{Start: time.Duration(i) * TimelineInterval})
+	}
+	return timeline
+}
@@ -0,0 +1,126 @@
+package stresstest
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings contém a duração de cada fase de um request, obtida via net/http/httptrace.
+// DNS, Connect e TLS ficam zerados quando a conexão foi reaproveitada do pool.
+type Timings struct {
+	DNS      time.Duration // Resolução do nome do host
+	Connect  time.Duration // Estabelecimento da conexão TCP
+	TLS      time.Duration // Handshake TLS
+	TTFB     time.Duration // Do início do request até o primeiro byte da resposta
+	Transfer time.Duration // Do primeiro byte da resposta até o fim da leitura do corpo
+}
+
+// PhaseStats agrupa a distribuição de duração de cada fase dos requests.
+// As fases de conexão só são registradas quando uma nova conexão foi aberta.
+type PhaseStats struct {
+	DNS      Histogram `json:"dns"`      // Resolução do nome do host
+	Connect  Histogram `json:"connect"`  // Estabelecimento da conexão TCP
+	TLS      Histogram `json:"tls"`      // Handshake TLS
+	TTFB     Histogram `json:"ttfb"`     // Tempo até o primeiro byte da resposta
+	Transfer Histogram `json:"transfer"` // Transferência do corpo da resposta
+}
+
+// record registra as fases de um request nos histogramas correspondentes.
+func (p *PhaseStats) record(t Timings) {
+	if t.DNS > 0 {
+		p.DNS.Record(t.DNS)
+	}
+	if t.Connect > 0 {
+		p.Connect.Record(t.Connect)
+	}
+	if t.TLS > 0 {
+		p.TLS.Record(t.TLS)
+	}
+	p.TTFB.Record(t.TTFB)
+	p.Transfer.Record(t.Transfer)
+}
+
+// merge soma as distribuições de outro PhaseStats neste.
+func (p *PhaseStats) merge(other PhaseStats) {
+	p.DNS.Merge(other.DNS)
+	p.Connect.Merge(other.Connect)
+	p.TLS.Merge(other.TLS)
+	p.TTFB.Merge(other.TTFB)
+	p.Transfer.Merge(other.Transfer)
+}
+
+// phaseTracer coleta os instantes de cada fase de um request.
+// Os callbacks do httptrace podem ser chamados em outras goroutines (ex: na discagem da conexão),
+// por isso o acesso aos campos é protegido por uma trava.
+type phaseTracer struct {
+	mu           sync.Mutex
+	timings      Timings
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	firstByte    time.Time
+}
+
+// clientTrace cria os callbacks do httptrace que alimentam o rastreador.
+func (p *phaseTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.timings.DNS = time.Since(p.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			// Com múltiplos endereços considera a primeira tentativa
+			if p.connectStart.IsZero() {
+				p.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if err == nil && p.timings.Connect == 0 {
+				p.timings.Connect = time.Since(p.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.timings.TLS = time.Since(p.tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.firstByte = time.Now()
+		},
+	}
+}
+
+// finish calcula o tempo até o primeiro byte e o de transferência, retornando as fases.
+func (p *phaseTracer) finish(start, end time.Time) Timings {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	timings := p.timings
+	if p.firstByte.IsZero() {
+		// Sem o evento de primeiro byte (ex: resposta vazia) todo o tempo conta como espera
+		timings.TTFB = end.Sub(start)
+		return timings
+	}
+	timings.TTFB = p.firstByte.Sub(start)
+	timings.Transfer = end.Sub(p.firstByte)
+	return timings
+}
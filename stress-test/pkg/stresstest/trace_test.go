@@ -0,0 +1,49 @@
+package stresstest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun_FasesDoRequest(t *testing.T) {
+	corpo := strings.Repeat("x", 4096)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte(corpo))
+	}))
+	defer server.Close()
+
+	report := Run(context.Background(), Config{
+		RequestSpec: RequestSpec{URL: server.URL},
+		Requests:    40,
+		Concurrency: 2,
+		Transport:   TransportConfig{InsecureSkipVerify: true},
+	})
+
+	if report.SuccessCount != 40 {
+		t.Fatalf("Esperado 40 requests bem-sucedidos, obtido %d (%v)", report.SuccessCount, report.Errors)
+	}
+
+	// Conexão e handshake só acontecem nas conexões novas (no máximo uma por worker)
+	fases := report.Phases
+	if fases.Connect.Total == 0 || fases.Connect.Total > 2 || fases.TLS.Total != fases.Connect.Total {
+		t.Errorf("Fases de conexão inesperadas: connect %d, tls %d", fases.Connect.Total, fases.TLS.Total)
+	}
+
+	// Tempo até o primeiro byte inclui o processamento do servidor
+	if fases.TTFB.Total != 40 || fases.TTFB.Percentile(50) < 5*time.Millisecond {
+		t.Errorf("TTFB inesperado: %d amostras, p50 %v", fases.TTFB.Total, fases.TTFB.Percentile(50))
+	}
+	if fases.TTFB.Percentile(50) > report.Latency.Percentile(50) {
+		t.Errorf("TTFB (%v) não pode ser maior que a latência total (%v)",
+			fases.TTFB.Percentile(50), report.Latency.Percentile(50))
+	}
+
+	if report.BytesReceived != 40*4096 {
+		t.Errorf("Bytes recebidos incorretos: esperado %d, obtido %d", 40*4096, report.BytesReceived)
+	}
+}
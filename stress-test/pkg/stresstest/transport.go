@@ -0,0 +1,214 @@
+package stresstest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout é o tempo máximo padrão de cada request (conexão, envio e leitura da resposta).
+const DefaultTimeout = 30 * time.Second
+
+// TransportConfig contém os ajustes do cliente HTTP compartilhado por todos os workers.
+// Valores zero usam os padrões indicados em cada campo.
+type TransportConfig struct {
+	Timeout               time.Duration // Tempo máximo de cada request (padrão DefaultTimeout)
+	DialTimeout           time.Duration // Tempo máximo para estabelecer a conexão TCP (padrão 10s)
+	TLSHandshakeTimeout   time.Duration // Tempo máximo do handshake TLS (padrão 10s)
+	ResponseHeaderTimeout time.Duration // Tempo máximo aguardando os cabeçalhos da resposta (sem limite quando zero)
+	IdleConnTimeout       time.Duration // Tempo que conexões ociosas ficam abertas no pool (padrão 90s)
+	MaxIdleConnsPerHost   int           // Conexões ociosas mantidas por host (padrão igual à concorrência)
+	DisableKeepAlives     bool          // Desativa o reuso de conexões (uma conexão nova por request)
+	DisableHTTP2          bool          // Impede a negociação de HTTP/2 em conexões TLS
+	InsecureSkipVerify    bool          // Não valida o certificado TLS do servidor
+
+	// TLS e rede (opcional)
+	ClientCert string            // Arquivo PEM com o certificado do cliente (mTLS)
+	ClientKey  string            // Arquivo PEM com a chave privada do certificado do cliente
+	CACert     string            // Arquivo PEM com as CAs aceitas no certificado do servidor (substitui as do sistema)
+	ServerName string            // Nome enviado no SNI e validado no certificado (padrão: host da URL)
+	Proxy      string            // URL do proxy (http, https ou socks5); vazio usa HTTP_PROXY/HTTPS_PROXY
+	Resolve    map[string]string // Endereço usado para cada host no lugar do DNS (host -> IP)
+}
+
+// validate verifica se os ajustes do transporte são consistentes.
+func (t *TransportConfig) validate() error {
+	if t.Timeout < 0 || t.DialTimeout < 0 || t.TLSHandshakeTimeout < 0 ||
+		t.ResponseHeaderTimeout < 0 || t.IdleConnTimeout < 0 {
+		return fmt.Errorf("timeouts não podem ser negativos")
+	}
+	if t.MaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("número de conexões ociosas por host não pode ser negativo")
+	}
+	if _, err := t.proxy(); err != nil {
+		return err
+	}
+	for host, ip := range t.Resolve {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("resolve %s: endereço IP inválido %q", host, ip)
+		}
+	}
+
+	// Carrega os certificados para detectar arquivos ausentes ou inválidos antes do teste
+	_, err := t.tlsConfig()
+	return err
+}
+
+// tlsConfig monta a configuração TLS do cliente: validação do servidor, CAs, SNI e o
+// certificado do cliente para mTLS.
+func (t *TransportConfig) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: t.InsecureSkipVerify, ServerName: t.ServerName}
+
+	if (t.ClientCert == "") != (t.ClientKey == "") {
+		return nil, fmt.Errorf("informe o certificado e a chave do cliente juntos")
+	}
+	if t.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao carregar o certificado do cliente: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if t.CACert != "" {
+		data, err := os.ReadFile(t.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler o arquivo de CAs: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("nenhum certificado PEM encontrado em %s", t.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// proxy retorna a função que escolhe o proxy de cada request: o proxy informado ou,
+// sem ele, o definido nas variáveis de ambiente.
+func (t *TransportConfig) proxy() (func(*http.Request) (*url.URL, error), error) {
+	if t.Proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	proxyURL, err := url.Parse(t.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("proxy inválido: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy deve usar o esquema http, https ou socks5")
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+// dialContext retorna a função de conexão do dialer, trocando o host pelo endereço definido em
+// Resolve quando houver (equivalente ao --resolve do curl). O nome original continua sendo
+// usado no SNI e no cabeçalho Host. Os nomes são comparados sem diferenciar maiúsculas.
+func (t *TransportConfig) dialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if len(t.Resolve) == 0 {
+		return dialer.DialContext
+	}
+	// As chaves informadas pela API não passam por ParseResolve: são normalizadas uma única vez aqui
+	resolve := make(map[string]string, len(t.Resolve))
+	for host, ip := range t.Resolve {
+		resolve[strings.ToLower(host)] = ip
+	}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(address); err == nil {
+			if ip, ok := resolve[strings.ToLower(host)]; ok {
+				address = net.JoinHostPort(ip, port)
+			}
+		}
+		return dialer.DialContext(ctx, network, address)
+	}
+}
+
+// ParseResolve interpreta uma substituição de DNS no formato "host:ip", como em
+// "api.exemplo.com:10.0.0.5" (IPv6 também é aceito: "api.exemplo.com:::1").
+func ParseResolve(value string) (host, ip string, err error) {
+	host, ip, ok := strings.Cut(value, ":")
+	if !ok || host == "" || net.ParseIP(strings.Trim(ip, "[]")) == nil {
+		return "", "", fmt.Errorf("resolve inválido %q: use o formato host:ip", value)
+	}
+	return strings.ToLower(host), strings.Trim(ip, "[]"), nil
+}
+
+// newClient cria o cliente HTTP compartilhado pelos workers do teste.
+// Um único transporte garante que as conexões sejam reaproveitadas (keep-alive) entre requests,
+// de modo que o teste mede o serviço e não o custo de abrir uma conexão TCP/TLS a cada request.
+func newClient(config Config) *http.Client {
+	t := config.Transport
+
+	// Por padrão mantém uma conexão ociosa por worker
+	maxIdle := t.MaxIdleConnsPerHost
+	if maxIdle == 0 {
+		maxIdle = config.Concurrency
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   orDefault(t.DialTimeout, 10*time.Second),
+		KeepAlive: 30 * time.Second,
+	}
+
+	// Configuração não validada previamente: todos os requests falham com o erro encontrado
+	tlsConfig, err := t.tlsConfig()
+	if err != nil {
+		return &http.Client{Transport: failingTransport{err}}
+	}
+	proxy, err := t.proxy()
+	if err != nil {
+		return &http.Client{Transport: failingTransport{err}}
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           t.dialContext(dialer),
+		MaxIdleConns:          0, // Sem limite global; o limite relevante é por host
+		MaxIdleConnsPerHost:   maxIdle,
+		IdleConnTimeout:       orDefault(t.IdleConnTimeout, 90*time.Second),
+		TLSHandshakeTimeout:   orDefault(t.TLSHandshakeTimeout, 10*time.Second),
+		ResponseHeaderTimeout: t.ResponseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+		DisableKeepAlives:     t.DisableKeepAlives,
+		ForceAttemptHTTP2:     !t.DisableHTTP2,
+		TLSClientConfig:       tlsConfig,
+	}
+
+	// Um mapa vazio (não nil) em TLSNextProto desativa a negociação de HTTP/2
+	if t.DisableHTTP2 {
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   orDefault(t.Timeout, DefaultTimeout),
+	}
+}
+
+// failingTransport faz todos os requests falharem com o mesmo erro. Usado quando os ajustes do
+// transporte são inválidos e a configuração não foi validada antes do teste.
+type failingTransport struct {
+	err error // Erro retornado em cada request
+}
+
+// RoundTrip retorna o erro registrado.
+func (f failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, f.err
+}
+
+// orDefault retorna o valor informado ou o padrão quando ele é zero.
+func orDefault(value, fallback time.Duration) time.Duration {
+	if value == 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,329 @@
+package stresstest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// novoServidorContandoConexoes cria um servidor que conta quantas conexões TCP foram abertas
+func novoServidorContandoConexoes(t *testing.T, conexoes *atomic.Int64) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("corpo que precisa ser lido para a conexão voltar ao pool"))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conexoes.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun_ReutilizaConexoes(t *testing.T) {
+	var conexoes atomic.Int64
+	server := novoServidorContandoConexoes(t, &conexoes)
+
+	report := Run(context.Background(), Config{
+		RequestSpec: RequestSpec{URL: server.URL},
+		Requests:    200,
+		Concurrency: 5,
+	})
+
+	if report.SuccessCount != 200 {
+		t.Fatalf("Esperado 200 requests bem-sucedidos, obtido %d", report.SuccessCount)
+	}
+	// Cada worker deve manter sua conexão aberta durante todo o teste
+	if conexoes.Load() > 5 {
+		t.Errorf("Esperado no máximo 5 conexões (uma por worker), obtido %d", conexoes.Load())
+	}
+}
+
+func TestRun_SemKeepAlive(t *testing.T) {
+	var conexoes atomic.Int64
+	server := novoServidorContandoConexoes(t, &conexoes)
+
+	Run(context.Background(), Config{
+		RequestSpec: RequestSpec{URL: server.URL},
+		Requests:    50,
+		Concurrency: 5,
+		Transport:   TransportConfig{DisableKeepAlives: true},
+	})
+
+	if conexoes.Load() != 50 {
+		t.Errorf("Esperado uma conexão por request (50), obtido %d", conexoes.Load())
+	}
+}
+
+func TestRun_InsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	config := Config{RequestSpec: RequestSpec{URL: server.URL}, Requests: 5, Concurrency: 1}
+
+	// Sem desativar a verificação o certificado autoassinado é rejeitado
+	if report := Run(context.Background(), config); report.ErrorCount != 5 {
+		t.Errorf("Esperado 5 erros de certificado, obtido %d", report.ErrorCount)
+	}
+
+	config.Transport.InsecureSkipVerify = true
+	if report := Run(context.Background(), config); report.SuccessCount != 5 {
+		t.Errorf("Esperado 5 requests bem-sucedidos, obtido %d", report.SuccessCount)
+	}
+}
+
+// certificadoDeTeste contém um certificado gerado para os testes e os arquivos PEM gravados
+type certificadoDeTeste struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	tls      tls.Certificate
+	certFile string
+	keyFile  string
+}
+
+// novoCertificado gera um certificado assinado por ca (ou autoassinado, quando ca é nil) e grava
+// o certificado e a chave em arquivos PEM no diretório temporário do teste
+func novoCertificado(t *testing.T, nome string, ca *certificadoDeTeste, template *x509.Certificate) *certificadoDeTeste {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.Subject = pkix.Name{CommonName: nome}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parent, signer := template, key
+	if ca != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	dir := t.TempDir()
+	gerado := &certificadoDeTeste{key: key, certFile: filepath.Join(dir, nome+".pem"), keyFile: filepath.Join(dir, nome+"-key.pem")}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(gerado.certFile, certPEM, 0o600); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if err := os.WriteFile(gerado.keyFile, keyPEM, 0o600); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if gerado.cert, err = x509.ParseCertificate(der); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if gerado.tls, err = tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	return gerado
+}
+
+// novoServidorMTLS cria um servidor TLS com certificado para api.staging.local, assinado pela CA
+// informada, que exige certificado de cliente assinado pela mesma CA
+func novoServidorMTLS(t *testing.T, ca *certificadoDeTeste) *httptest.Server {
+	t.Helper()
+	servidor := novoCertificado(t, "servidor", ca, &x509.Certificate{
+		DNSNames:    []string{"api.staging.local"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{servidor.tls},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// novaCA gera uma autoridade certificadora para os testes
+func novaCA(t *testing.T) *certificadoDeTeste {
+	t.Helper()
+	return novoCertificado(t, "ca", nil, &x509.Certificate{
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	})
+}
+
+// novoCertificadoCliente gera um certificado de cliente assinado pela CA
+func novoCertificadoCliente(t *testing.T, ca *certificadoDeTeste) *certificadoDeTeste {
+	t.Helper()
+	return novoCertificado(t, "cliente", ca, &x509.Certificate{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+}
+
+func TestRun_MTLSComCAPropria(t *testing.T) {
+	ca := novaCA(t)
+	cliente := novoCertificadoCliente(t, ca)
+	server := novoServidorMTLS(t, ca)
+
+	// O certificado do servidor vale apenas para api.staging.local: o SNI substitui o IP da URL
+	transport := TransportConfig{CACert: ca.certFile, ServerName: "api.staging.local"}
+	config := Config{RequestSpec: RequestSpec{URL: server.URL}, Requests: 5, Concurrency: 1, Transport: transport}
+
+	// Sem o certificado do cliente o servidor recusa o handshake
+	if report := Run(context.Background(), config); report.ErrorCount != 5 {
+		t.Errorf("Esperado 5 erros sem certificado do cliente, obtido %d", report.ErrorCount)
+	}
+
+	config.Transport.ClientCert, config.Transport.ClientKey = cliente.certFile, cliente.keyFile
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if report := Run(context.Background(), config); report.SuccessCount != 5 {
+		t.Errorf("Esperado 5 requests bem-sucedidos com mTLS, obtido %+v", report.Stats)
+	}
+
+	// Sem a CA própria o certificado do servidor não é reconhecido
+	config.Transport.CACert = ""
+	if report := Run(context.Background(), config); report.ErrorCount != 5 {
+		t.Errorf("Esperado 5 erros de certificado sem a CA, obtido %d", report.ErrorCount)
+	}
+}
+
+func TestRun_ResolveSubstituiDNS(t *testing.T) {
+	ca := novaCA(t)
+	cliente := novoCertificadoCliente(t, ca)
+	server := novoServidorMTLS(t, ca)
+	_, porta, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	// O host da URL não existe no DNS: a conexão vai para o IP informado e o nome é usado no SNI.
+	// A chave definida pela API, sem passar por ParseResolve, não diferencia maiúsculas
+	config := Config{
+		RequestSpec: RequestSpec{URL: "https://api.staging.local:" + porta + "/"},
+		Requests:    3,
+		Concurrency: 1,
+		Transport: TransportConfig{
+			CACert:     ca.certFile,
+			ClientCert: cliente.certFile,
+			ClientKey:  cliente.keyFile,
+			Resolve:    map[string]string{"API.Staging.local": "127.0.0.1"},
+		},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if report := Run(context.Background(), config); report.SuccessCount != 3 {
+		t.Errorf("Esperado 3 requests bem-sucedidos, obtido %+v", report.Stats)
+	}
+}
+
+func TestRun_Proxy(t *testing.T) {
+	server := novoServidorVazio(t)
+
+	// Proxy HTTP simples: recebe a URL absoluta e repassa o request ao destino
+	var encaminhados atomic.Int64
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !r.URL.IsAbs() {
+			http.Error(w, "esperado URL absoluta", http.StatusBadRequest)
+			return
+		}
+		encaminhados.Add(1)
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+	}))
+	defer proxy.Close()
+
+	config := Config{
+		RequestSpec: RequestSpec{URL: server.URL},
+		Requests:    4,
+		Concurrency: 2,
+		Transport:   TransportConfig{Proxy: proxy.URL},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	report := Run(context.Background(), config)
+
+	if report.SuccessCount != 4 || encaminhados.Load() != 4 {
+		t.Errorf("Esperado 4 requests pelo proxy, obtido %d sucessos e %d encaminhados", report.SuccessCount, encaminhados.Load())
+	}
+}
+
+func TestTransportConfig_ValidacaoTLSEProxy(t *testing.T) {
+	ca := novaCA(t)
+	vazio := filepath.Join(t.TempDir(), "vazio.pem")
+	os.WriteFile(vazio, []byte("sem certificados"), 0o600)
+
+	casos := map[string]TransportConfig{
+		"certificado sem chave":   {ClientCert: ca.certFile},
+		"chave sem certificado":   {ClientKey: ca.keyFile},
+		"certificado inexistente": {ClientCert: "nao-existe.pem", ClientKey: ca.keyFile},
+		"CA inexistente":          {CACert: "nao-existe.pem"},
+		"CA sem certificados":     {CACert: vazio},
+		"proxy sem esquema":       {Proxy: "proxy.local:3128"},
+		"proxy com esquema ftp":   {Proxy: "ftp://proxy.local"},
+		"resolve com IP inválido": {Resolve: map[string]string{"api": "999.1.1.1"}},
+	}
+	for nome, transport := range casos {
+		if err := transport.validate(); err == nil {
+			t.Errorf("%s: esperado erro", nome)
+		}
+	}
+
+	valido := TransportConfig{ClientCert: ca.certFile, ClientKey: ca.keyFile, CACert: ca.certFile, Proxy: "socks5://127.0.0.1:1080"}
+	if err := valido.validate(); err != nil {
+		t.Errorf("Erro inesperado: %v", err)
+	}
+
+	// Sem validação prévia, a configuração inválida faz cada request falhar
+	report := Run(context.Background(), Config{RequestSpec: RequestSpec{URL: "http://127.0.0.1:1"}, Requests: 2, Concurrency: 1,
+		Transport: TransportConfig{CACert: "nao-existe.pem"}})
+	if report.ErrorCount != 2 {
+		t.Errorf("Esperado 2 erros, obtido %d", report.ErrorCount)
+	}
+}
+
+func TestParseResolve(t *testing.T) {
+	casos := map[string][2]string{
+		"api.exemplo.com:10.0.0.5": {"api.exemplo.com", "10.0.0.5"},
+		"API.Exemplo.com:::1":      {"api.exemplo.com", "::1"},
+		"api:[2001:db8::1]":        {"api", "2001:db8::1"},
+	}
+	for valor, esperado := range casos {
+		host, ip, err := ParseResolve(valor)
+		if err != nil || host != esperado[0] || ip != esperado[1] {
+			t.Errorf("%s: esperado %v, obtido %s %s (%v)", valor, esperado, host, ip, err)
+		}
+	}
+	for _, invalido := range []string{"api.exemplo.com", ":10.0.0.5", "api:nao-e-ip"} {
+		if _, _, err := ParseResolve(invalido); err == nil {
+			t.Errorf("%s: esperado erro", invalido)
+		}
+	}
+}
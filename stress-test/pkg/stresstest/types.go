@@ -5,17 +5,217 @@ import "time"
 // Result representa o resultado de uma única requisição HTTP durante o teste de carga.
 // Contém informações sobre o status, tempo de resposta e possíveis erros.
 type Result struct {
-	StatusCode int           // Código de status HTTP retornado (200, 404, 500, etc.)
+	StatusCode int           // Código de status HTTP retornado (200, 404, 500, etc.) ou código gRPC (0, 14, etc.)
 	Duration   time.Duration // Tempo que a requisição levou para ser concluída
 	Error      error         // Erro ocorrido durante a requisição, se houver
+	Stage      int           // Índice do estágio do perfil de carga em que o request foi disparado (-1 sem perfil)
+	Timings    Timings       // Duração de cada fase do request (DNS, conexão, TLS, primeiro byte e transferência)
+	BodySize   int64         // Tamanho do corpo da resposta em bytes
+	Failed     []string      // Asserções que a resposta não atendeu (vazio quando todas passaram)
+	Protocol   string        // Protocolo do request: vazio para HTTP, ProtocolGRPC, ProtocolWebSocket ou ProtocolSSE
+	Stream     *StreamResult // Métricas da sessão de streaming (apenas WebSocket e SSE)
+	Steps      []Result      // Resultado de cada passo executado na iteração (apenas fluxos)
+	Tag        string        // Tag da requisição, com métricas próprias no relatório (vazio sem tag)
+	RateLimit  *RateLimit    // Cabeçalhos de limite de taxa da resposta (nil quando ausentes; apenas HTTP)
+}
+
+// ok indica se a resposta foi bem-sucedida: status 2xx no HTTP, OK no gRPC ou
+// 101 (troca de protocolo) no WebSocket.
+func (r Result) ok() bool {
+	switch r.Protocol {
+	case ProtocolGRPC:
+		return r.StatusCode == 0
+	case ProtocolWebSocket:
+		return r.StatusCode == 101
+	}
+	return r.StatusCode >= 200 && r.StatusCode <= 299
+}
+
+// success indica se a resposta conta como sucesso: status 200 (OK no gRPC, 101 no WebSocket)
+// e todas as asserções atendidas.
+func (r Result) success() bool {
+	if len(r.Failed) > 0 {
+		return false
+	}
+	if r.Protocol == "" {
+		return r.StatusCode == 200
+	}
+	return r.ok()
+}
+
+// Stats agrupa as métricas acumuladas de um conjunto de requisições.
+// É usado tanto no relatório geral quanto no detalhamento de cada estágio do perfil de carga.
+type Stats struct {
+	TotalRequests int         `json:"total_requests"` // Número total de requisições que foram executadas
+	SuccessCount  int         `json:"success_count"`  // Quantidade de requisições que retornaram status 200 (OK no gRPC) e passaram nas asserções
+	StatusCodes   map[int]int `json:"status_codes"`   // Mapa com a distribuição de códigos de status HTTP ou gRPC (código -> quantidade)
+	ErrorCount    int         `json:"error_count"`    // Número de requisições que falharam com erro de rede/timeout
+	Latency       Histogram   `json:"latency"`        // Distribuição dos tempos de resposta
+
+	Errors      map[string]ErrorStat `json:"errors,omitempty"` // Erros de transporte por categoria (timeout, dns, tls...)
+	Non2xxCount int                  `json:"non_2xx_count"`    // Respostas recebidas com status fora da faixa 2xx (diferente de OK no gRPC)
+
+	Phases        PhaseStats `json:"phases"`         // Distribuição da duração de cada fase dos requests
+	BytesReceived int64      `json:"bytes_received"` // Total de bytes recebidos nos corpos das respostas
+
+	AssertionFailedCount int            `json:"assertion_failed_count"`       // Respostas que não atenderam alguma asserção
+	AssertionFailures    map[string]int `json:"assertion_failures,omitempty"` // Falhas por asserção (uma resposta pode falhar em várias)
+
+	Stream *StreamStats `json:"stream,omitempty"` // Métricas das sessões WebSocket/SSE (vazio nos demais protocolos)
+
+	Throttle *ThrottleStats `json:"throttle,omitempty"` // Respostas 429 e cabeçalhos de limite de taxa (vazio quando não houve)
 }
 
 // Report contém o relatório consolidado de todo o teste de carga executado.
 // Inclui métricas gerais, estatísticas de sucesso/erro e distribuição de status codes.
 type Report struct {
-	TotalTime      time.Duration // Tempo total gasto na execução de todo o teste
-	TotalRequests  int           // Número total de requisições que foram executadas
-	SuccessCount   int           // Quantidade de requisições que retornaram status 200
-	StatusCodes    map[int]int   // Mapa com a distribuição de códigos de status (código -> quantidade)
-	ErrorCount     int           // Número de requisições que falharam com erro de rede/timeout
-}
\ No newline at end of file
+	Stats                   // Métricas consolidadas de todas as requisições
+	Protocol  string        `json:"protocol,omitempty"`  // Protocolo testado: vazio para HTTP, ProtocolGRPC, ProtocolWebSocket ou ProtocolSSE
+	TotalTime time.Duration `json:"total_time"`          // Tempo total gasto na execução de todo o teste
+	Stages    []StageReport `json:"stages,omitempty"`    // Métricas de cada estágio do perfil de carga (vazio sem perfil)
+	Steps     []StepReport  `json:"steps,omitempty"`     // Métricas de cada passo do fluxo (vazio sem fluxo)
+	Tags      []TagReport   `json:"tags,omitempty"`      // Métricas por tag, da mais à menos frequente (vazio sem tags)
+	Aborted   string        `json:"aborted,omitempty"`   // Motivo da interrupção antecipada do teste (vazio se concluído)
+	Abandoned int           `json:"abandoned,omitempty"` // Requests cancelados ao fim do prazo de tolerância (fora das métricas)
+
+	SinkFailures int `json:"sink_failures,omitempty"` // Envios de métricas aos sinks que falharam
+
+	Iterations          int `json:"iterations,omitempty"`           // Iterações do fluxo executadas (sessões de usuário virtual)
+	CompletedIterations int `json:"completed_iterations,omitempty"` // Iterações em que todos os passos foram concluídos com sucesso
+
+	Timeline []TimelineBucket `json:"timeline,omitempty"` // Métricas a cada TimelineInterval, na ordem do teste
+}
+
+// StageReport contém as métricas de um estágio específico do perfil de carga.
+// Permite identificar em qual fase do teste a latência começou a degradar.
+type StageReport struct {
+	Stage               // Definição do estágio (nome, duração e taxa alvo)
+	Start time.Duration `json:"start"` // Instante de início do estágio relativo ao início do teste
+	Stats               // Métricas das requisições disparadas durante o estágio
+}
+
+// StepReport contém as métricas de um passo do fluxo.
+// Permite identificar qual etapa da jornada do usuário (login, busca, compra...) é o gargalo.
+type StepReport struct {
+	Name  string `json:"name"` // Nome do passo
+	Stats        // Métricas das requisições do passo
+}
+
+// newStats cria um Stats vazio pronto para receber resultados.
+func newStats() Stats {
+	return Stats{
+		StatusCodes:       make(map[int]int),
+		Errors:            make(map[string]ErrorStat),
+		AssertionFailures: make(map[string]int),
+	}
+}
+
+// add contabiliza um resultado individual nas métricas.
+func (s *Stats) add(result Result) {
+	s.TotalRequests++
+
+	// Se houve erro de rede/timeout, conta como erro na sua categoria
+	if result.Error != nil {
+		s.ErrorCount++
+		recordError(s.Errors, result.Error)
+		return
+	}
+
+	// Conta o código de status retornado
+	s.StatusCodes[result.StatusCode]++
+
+	// Respostas fora da faixa 2xx são contadas à parte dos erros de transporte
+	if !result.ok() {
+		s.Non2xxCount++
+	}
+
+	// Respostas que falharam em alguma asserção não contam como sucesso
+	if len(result.Failed) > 0 {
+		s.AssertionFailedCount++
+		for _, name := range result.Failed {
+			s.AssertionFailures[name]++
+		}
+	}
+
+	// Conta requests bem-sucedidos (status 200)
+	if result.success() {
+		s.SuccessCount++
+	}
+
+	// Apenas respostas recebidas entram na distribuição de latência
+	s.Latency.Record(result.Duration)
+	if result.Protocol == "" {
+		// Apenas requests HTTP passam pelo httptrace; nos demais protocolos não há fases a registrar
+		s.Phases.record(result.Timings)
+	}
+
+	// Mensagens trocadas nas sessões de streaming
+	if result.Stream != nil {
+		if s.Stream == nil {
+			s.Stream = newStreamStats()
+		}
+		s.Stream.add(result.Stream)
+	}
+
+	// Recusas por limite de taxa e cabeçalhos de cota anunciados pelo servidor
+	if result.throttled() || result.RateLimit != nil {
+		if s.Throttle == nil {
+			s.Throttle = newThrottleStats()
+		}
+		s.Throttle.add(result)
+	}
+	s.BytesReceived += result.BodySize
+}
+
+// merge soma as métricas de outro Stats neste.
+func (s *Stats) merge(other Stats) {
+	s.TotalRequests += other.TotalRequests
+	s.SuccessCount += other.SuccessCount
+	s.ErrorCount += other.ErrorCount
+
+	if s.StatusCodes == nil {
+		s.StatusCodes = make(map[int]int, len(other.StatusCodes))
+	}
+	for code, count := range other.StatusCodes {
+		s.StatusCodes[code] += count
+	}
+
+	s.Latency.Merge(other.Latency)
+	s.Phases.merge(other.Phases)
+	s.BytesReceived += other.BytesReceived
+
+	s.Non2xxCount += other.Non2xxCount
+	if s.Errors == nil {
+		s.Errors = make(map[string]ErrorStat, len(other.Errors))
+	}
+	for kind, stat := range other.Errors {
+		merged := s.Errors[kind]
+		merged.Count += stat.Count
+		for _, sample := range stat.Samples {
+			merged.Samples = addSample(merged.Samples, sample)
+		}
+		s.Errors[kind] = merged
+	}
+
+	s.AssertionFailedCount += other.AssertionFailedCount
+	if s.AssertionFailures == nil {
+		s.AssertionFailures = make(map[string]int, len(other.AssertionFailures))
+	}
+	for name, count := range other.AssertionFailures {
+		s.AssertionFailures[name] += count
+	}
+
+	if other.Stream != nil {
+		if s.Stream == nil {
+			s.Stream = newStreamStats()
+		}
+		s.Stream.merge(*other.Stream)
+	}
+
+	if other.Throttle != nil {
+		if s.Throttle == nil {
+			s.Throttle = newThrottleStats()
+		}
+		s.Throttle.merge(*other.Throttle)
+	}
+}